@@ -0,0 +1,663 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// ACTIONS
+// =============================================================================
+
+// Action is a keyboard-triggered operation on the selected tree node
+type Action struct {
+	Key       string
+	Label     string
+	Available func(m model, node *TreeNode) bool
+	Run       func(m model, node *TreeNode) (tea.Model, tea.Cmd)
+	// Effect decides which guard rails apply before Run
+	Effect Effect
+}
+
+// isInstance reports whether the node is a VM instance
+func isInstance(_ model, node *TreeNode) bool {
+	return node.Type == InstanceNode && node.VM != nil
+}
+
+// isLastSessionHost reports whether the node is the instance of the last session
+func isLastSessionHost(m model, node *TreeNode) bool {
+	return isInstance(m, node) && m.isLastSession(node)
+}
+
+// isResourceOf returns a predicate matching resource nodes of the given kind
+func isResourceOf(kind ResourceKind) func(m model, node *TreeNode) bool {
+	return func(_ model, node *TreeNode) bool {
+		return node.Type == ResourceNode && node.Resource.Kind == kind
+	}
+}
+
+// instanceActions returns the actions available in VM selection
+func instanceActions() []Action {
+	return []Action{
+		{
+			// Terminals report shift+enter as a plain enter, so alt+enter stands in for it
+			Key:       "alt+enter",
+			Label:     "open an additional session to the last session's host",
+			Effect:    EffectConnect,
+			Available: isLastSessionHost,
+			Run:       model.cloneSession,
+		},
+		{
+			Key:       "D",
+			Label:     "delete instance",
+			Effect:    EffectDestroy,
+			Available: isInstance,
+			Run:       model.startDelete,
+		},
+		{
+			// The confirmation spells out whether protection is being enabled or disabled
+			Key:       "p",
+			Label:     "toggle deletion protection",
+			Effect:    EffectMutate,
+			Available: isInstance,
+			Run:       model.startToggleDeletionProtection,
+		},
+		{
+			Key:    "c",
+			Label:  "connect to a container",
+			Effect: EffectConnect,
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && node.VM.IsContainerOptimized()
+			},
+			Run: model.discoverContainers,
+		},
+		{
+			Key:       "C",
+			Label:     "connect to serial console",
+			Effect:    EffectConnect,
+			Available: isInstance,
+			Run:       model.connectToSerialConsole,
+		},
+		{
+			Key:   "P",
+			Label: "capture screenshot",
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && VMStatus(node.VM.Status) == StatusRunning
+			},
+			Run: model.captureScreenshot,
+		},
+		{
+			Key:   "S",
+			Label: "view startup script",
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && node.VM.hasStartupScript()
+			},
+			Run: model.showStartupScript,
+		},
+		{
+			Key:       "y",
+			Label:     "copy name, IP or ssh command",
+			Available: isInstance,
+			Run:       model.startYank,
+		},
+		{
+			Key:       "m",
+			Label:     "mark for bulk actions",
+			Available: isInstance,
+			Run:       model.toggleMark,
+		},
+		{
+			Key:   "M",
+			Label: "clear marks",
+			Available: func(m model, node *TreeNode) bool {
+				return len(m.marked) > 0
+			},
+			Run: func(m model, _ *TreeNode) (tea.Model, tea.Cmd) {
+				return m.clearMarks()
+			},
+		},
+		{
+			Key:       "L",
+			Label:     "edit labels",
+			Effect:    EffectMutate,
+			Available: isInstance,
+			Run:       model.startEditLabels,
+		},
+		{
+			Key:       "z",
+			Label:     "suspend",
+			Effect:    EffectDestroy,
+			Available: canSuspend,
+			Run:       model.startSuspend,
+		},
+		{
+			Key:       "z",
+			Label:     "resume",
+			Effect:    EffectMutate,
+			Available: canResume,
+			Run:       model.startResume,
+		},
+		{
+			Key:       "g",
+			Label:     "start instance",
+			Effect:    EffectMutate,
+			Available: canStart,
+			Run:       model.startInstance,
+		},
+		{
+			Key:       "N",
+			Label:     "notify when RUNNING",
+			Available: canWatch,
+			Run:       model.toggleRunningWatch,
+		},
+		{
+			Key:       "R",
+			Label:     "resize managed instance group",
+			Effect:    EffectDestroy,
+			Available: isManagedGroup,
+			Run:       model.startResizeGroup,
+		},
+		{
+			// Same key as resizing a group; this one only applies to stopped instances
+			Key:       "R",
+			Label:     "change machine type",
+			Effect:    EffectMutate,
+			Available: canChangeMachineType,
+			Run:       model.startChangeMachineType,
+		},
+		{
+			Key:       "U",
+			Label:     "rolling restart or replace",
+			Effect:    EffectDestroy,
+			Available: isManagedGroup,
+			Run:       model.startRollout,
+		},
+		{
+			Key:       "f",
+			Label:     "mount filesystem with sshfs",
+			Effect:    EffectConnect,
+			Available: isInstance,
+			Run:       model.startMount,
+		},
+		{
+			Key:    "w",
+			Label:  "forward a port over IAP",
+			Effect: EffectConnect,
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && !m.gcpService.native
+			},
+			Run: model.startIAPTunnel,
+		},
+		{
+			Key:       "V",
+			Label:     "route networks through VM with sshuttle",
+			Effect:    EffectConnect,
+			Available: isInstance,
+			Run:       model.startSSHuttle,
+		},
+		{
+			Key:   "K",
+			Label: "check OS Login SSH key",
+			// Offers to upload a key to the OS Login profile
+			Effect: EffectMutate,
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && !m.gcpService.native
+			},
+			Run: model.checkOSLoginKey,
+		},
+		{
+			Key:       "b",
+			Label:     "browse files over SFTP",
+			Effect:    EffectConnect,
+			Available: isInstance,
+			Run:       model.startSFTP,
+		},
+		{
+			Key:       "n",
+			Label:     "install the Ops Agent",
+			Effect:    EffectMutate,
+			Available: canInstallOpsAgent,
+			Run:       model.startInstallOpsAgent,
+		},
+		{
+			Key:       "B",
+			Label:     "snapshot disks",
+			Effect:    EffectMutate,
+			Available: canSnapshot,
+			Run:       model.startSnapshot,
+		},
+		{
+			Key:       "d",
+			Label:     "attach or detach a disk",
+			Effect:    EffectMutate,
+			Available: canManageDisks,
+			Run:       model.startManageDisks,
+		},
+		{
+			Key:       "X",
+			Label:     "create machine image or custom image",
+			Effect:    EffectMutate,
+			Available: canCreateImage,
+			Run:       model.startCreateImage,
+		},
+		{
+			Key:       "F",
+			Label:     "check SSH firewall reachability",
+			Available: isInstance,
+			Run:       model.checkReachability,
+		},
+		{
+			Key:   "I",
+			Label: "copy internal IP",
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && node.VM.InternalIP() != ""
+			},
+			Run: func(m model, node *TreeNode) (tea.Model, tea.Cmd) {
+				return m.copyToClipboard("internal IP", node.VM.InternalIP())
+			},
+		},
+		{
+			Key:   "E",
+			Label: "copy external IP",
+			Available: func(m model, node *TreeNode) bool {
+				return isInstance(m, node) && node.VM.ExternalIP() != ""
+			},
+			Run: func(m model, node *TreeNode) (tea.Model, tea.Cmd) {
+				return m.copyToClipboard("external IP", node.VM.ExternalIP())
+			},
+		},
+		{
+			Key:       "o",
+			Label:     "open in Cloud Console",
+			Available: func(m model, _ *TreeNode) bool { return !m.onSource() },
+			Run: func(m model, node *TreeNode) (tea.Model, tea.Cmd) {
+				return m.openInConsole(m.nodeConsoleURL(node))
+			},
+		},
+		{
+			Key:       "s",
+			Label:     "SSH into the Workbench VM",
+			Effect:    EffectConnect,
+			Available: isResourceOf(KindWorkbench),
+			Run:       model.connectToWorkbenchVM,
+		},
+		{
+			Key:       "l",
+			Label:     "launch an instance or group from the template",
+			Effect:    EffectMutate,
+			Available: canCreateFromTemplate,
+			Run:       model.startCreateFromTemplate,
+		},
+		{
+			Key:       "s",
+			Label:     "SSH to the item instead of printing it",
+			Effect:    EffectConnect,
+			Available: isResourceOf(KindStdin),
+			Run:       model.connectToStdinItem,
+		},
+		{
+			Key:       "A",
+			Label:     "attach to the container's main process",
+			Effect:    EffectConnect,
+			Available: isRunningContainer,
+			Run:       model.attachToContainer,
+		},
+	}
+}
+
+// offers reports whether the action can be used on the node, honoring read-only mode
+func (m model) offers(action Action, node *TreeNode) bool {
+	if m.config.ReadOnly && action.Effect >= EffectMutate {
+		return false
+	}
+	return action.Available(m, node)
+}
+
+// openActionMenu lists the actions available for the node in a picker
+func (m model) openActionMenu(node *TreeNode) (tea.Model, tea.Cmd) {
+	if node == nil {
+		return m, nil
+	}
+
+	var options []string
+	byOption := make(map[string]Action)
+	for _, action := range instanceActions() {
+		if m.offers(action, node) {
+			option := fmt.Sprintf("%-9s %s", action.Key, action.Label)
+			options = append(options, option)
+			byOption[option] = action
+		}
+	}
+	if len(options) == 0 {
+		m.statusMsg = "No actions available here"
+		return m, nil
+	}
+
+	return m.askPick(fmt.Sprintf("Actions for %s", node.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		return m.runAction(byOption[option], node)
+	})
+}
+
+// findAction returns the available action bound to keypress for the node
+func (m model) findAction(keypress string, node *TreeNode) *Action {
+	if node == nil {
+		return nil
+	}
+	for _, action := range instanceActions() {
+		if action.Key == keypress && m.offers(action, node) {
+			return &action
+		}
+	}
+	return nil
+}
+
+// actionHints lists the actions available for the node as a help line
+func (m model) actionHints(node *TreeNode) string {
+	if node == nil {
+		return ""
+	}
+
+	var hints []string
+	for _, action := range instanceActions() {
+		if m.offers(action, node) {
+			hints = append(hints, fmt.Sprintf("'%s' %s", action.Key, action.Label))
+		}
+	}
+	return strings.Join(hints, ", ")
+}
+
+// =============================================================================
+// CONFIRMATION
+// =============================================================================
+
+// confirmation is a pending yes/no prompt guarding an operation
+type confirmation struct {
+	Prompt    string
+	OnConfirm func(m model) (tea.Model, tea.Cmd)
+}
+
+// askConfirm opens a confirmation prompt
+func (m model) askConfirm(prompt string, onConfirm func(m model) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	m.confirm = &confirmation{Prompt: prompt, OnConfirm: onConfirm}
+	return m, nil
+}
+
+// handleConfirmInput resolves the pending confirmation
+func (m model) handleConfirmInput(keypress string) (tea.Model, tea.Cmd) {
+	pending := m.confirm
+	m.confirm = nil
+
+	switch keypress {
+	case "y", "Y":
+		return pending.OnConfirm(m)
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	m.statusMsg = "Cancelled"
+	return m, nil
+}
+
+// =============================================================================
+// INPUT PROMPT
+// =============================================================================
+
+// inputPrompt is a pending single-line text prompt
+type inputPrompt struct {
+	Prompt   string
+	Input    textinput.Model
+	OnSubmit func(m model, value string) (tea.Model, tea.Cmd)
+}
+
+// askInput opens a text prompt prefilled with value
+func (m model) askInput(prompt, value string, onSubmit func(m model, value string) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Focus()
+
+	m.input = &inputPrompt{Prompt: prompt, Input: input, OnSubmit: onSubmit}
+	return m, textinput.Blink
+}
+
+// handleInputKey edits or resolves the pending text prompt
+func (m model) handleInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pending := *m.input
+
+	switch msg.String() {
+	case "enter":
+		m.input = nil
+		return pending.OnSubmit(m, pending.Input.Value())
+	case "esc":
+		m.input = nil
+		m.statusMsg = "Cancelled"
+		return m, nil
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	pending.Input, cmd = pending.Input.Update(msg)
+	m.input = &pending
+	return m, cmd
+}
+
+// renderInput renders the pending text prompt
+func (m model) renderInput() string {
+	return "  " + m.styles.Prompt.Render(m.input.Prompt) + "\n  " + m.input.Input.View()
+}
+
+// =============================================================================
+// PICKER
+// =============================================================================
+
+// picker is a pending choice among a fixed set of options
+type picker struct {
+	Prompt  string
+	Options []string
+	Cursor  int
+	OnPick  func(m model, option string) (tea.Model, tea.Cmd)
+}
+
+// askPick opens a picker over the options
+func (m model) askPick(prompt string, options []string, onPick func(m model, option string) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	m.picker = &picker{Prompt: prompt, Options: options, OnPick: onPick}
+	return m, nil
+}
+
+// handlePickerInput moves through or resolves the pending picker
+func (m model) handlePickerInput(keypress string) (tea.Model, tea.Cmd) {
+	pending := *m.picker
+
+	switch keypress {
+	case "up", "k":
+		if pending.Cursor > 0 {
+			pending.Cursor--
+		}
+	case "down", "j":
+		if pending.Cursor < len(pending.Options)-1 {
+			pending.Cursor++
+		}
+	case "enter":
+		m.picker = nil
+		return pending.OnPick(m, pending.Options[pending.Cursor])
+	case "esc", "q":
+		m.picker = nil
+		m.statusMsg = "Cancelled"
+		return m, nil
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	m.picker = &pending
+	return m, nil
+}
+
+// Options shown at once; longer pickers scroll with the cursor
+const pickerMaxVisible = 12
+
+// renderPicker renders the pending picker
+func (m model) renderPicker() string {
+	lines := []string{"  " + m.styles.Prompt.Render(m.picker.Prompt)}
+	first, last := 0, len(m.picker.Options)
+	if last > pickerMaxVisible {
+		first = min(max(m.picker.Cursor-pickerMaxVisible/2, 0), last-pickerMaxVisible)
+		last = first + pickerMaxVisible
+	}
+	if first > 0 {
+		lines = append(lines, m.styles.Label.Render(fmt.Sprintf("    ↑ %d more", first)))
+	}
+	for i := first; i < last; i++ {
+		option := m.picker.Options[i]
+		if i == m.picker.Cursor {
+			lines = append(lines, m.styles.SelectedItem.Render("> "+option))
+		} else {
+			lines = append(lines, m.styles.Item.Render(option))
+		}
+	}
+	if last < len(m.picker.Options) {
+		lines = append(lines, m.styles.Label.Render(fmt.Sprintf("    ↓ %d more", len(m.picker.Options)-last)))
+	}
+	lines = append(lines, m.styles.Label.Render("  Enter to choose, Esc to cancel"))
+	return strings.Join(lines, "\n")
+}
+
+// =============================================================================
+// DELETION AND DELETION PROTECTION
+// =============================================================================
+
+// DeletionProtectionUpdatedMsg indicates deletion protection was changed on a VM
+type DeletionProtectionUpdatedMsg struct {
+	VMName     string
+	Enabled    bool
+	ThenDelete bool
+	Err        error
+}
+
+// OperationDoneMsg indicates a mutating operation finished and VMs should be reloaded
+type OperationDoneMsg struct {
+	Description string
+	Err         error
+}
+
+// SetDeletionProtection enables or disables deletion protection on a VM
+func (gcp *GCPService) SetDeletionProtection(project string, vm VM, enabled, thenDelete bool) tea.Cmd {
+	return func() tea.Msg {
+		flag := "--no-deletion-protection"
+		if enabled {
+			flag = "--deletion-protection"
+		}
+
+		_, err := gcp.runGcloud("compute", "instances", "update", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			flag)
+		if err != nil {
+			err = fmt.Errorf("failed to update deletion protection: %w", err)
+		}
+
+		return DeletionProtectionUpdatedMsg{VMName: vm.Name, Enabled: enabled, ThenDelete: thenDelete, Err: err}
+	}
+}
+
+// DeleteVM deletes a VM instance
+func (gcp *GCPService) DeleteVM(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		_, err := gcp.runGcloud("compute", "instances", "delete", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			"--quiet")
+		if err != nil {
+			err = fmt.Errorf("failed to delete %s: %w", vm.Name, err)
+		}
+
+		return OperationDoneMsg{Description: fmt.Sprintf("Deleted %s", vm.Name), Err: err}
+	}
+}
+
+// startDelete begins deletion, requiring protection to be removed explicitly first
+func (m model) startDelete(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+
+	if vm.DeletionProtection {
+		prompt := fmt.Sprintf("%s has deletion protection enabled. Remove protection?", vm.Name)
+		return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+			m.statusMsg = fmt.Sprintf("Removing deletion protection from %s...", vm.Name)
+			return m, m.gcpService.SetDeletionProtection(m.selectedProject, vm, false, true)
+		})
+	}
+
+	return m.confirmDelete(vm)
+}
+
+// confirmDelete asks for final confirmation before deleting an unprotected VM
+func (m model) confirmDelete(vm VM) (tea.Model, tea.Cmd) {
+	prompt := fmt.Sprintf("Delete %s? This cannot be undone.", vm.Name)
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		m.statusMsg = fmt.Sprintf("Deleting %s...", vm.Name)
+		return m, m.gcpService.DeleteVM(m.selectedProject, vm)
+	})
+}
+
+// startToggleDeletionProtection flips deletion protection after confirmation
+func (m model) startToggleDeletionProtection(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	verb := "Enable"
+	if vm.DeletionProtection {
+		verb = "Disable"
+	}
+
+	prompt := fmt.Sprintf("%s deletion protection on %s?", verb, vm.Name)
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		m.statusMsg = fmt.Sprintf("Updating deletion protection on %s...", vm.Name)
+		return m, m.gcpService.SetDeletionProtection(m.selectedProject, vm, !vm.DeletionProtection, false)
+	})
+}
+
+// handleDeletionProtectionUpdated applies a protection change and continues a pending delete
+func (m model) handleDeletionProtectionUpdated(msg DeletionProtectionUpdatedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	vm := m.treeManager.FindVM(msg.VMName)
+	if vm == nil {
+		m.statusMsg = fmt.Sprintf("%s is no longer listed", msg.VMName)
+		return m, nil
+	}
+	vm.DeletionProtection = msg.Enabled
+	m.treeManager.invalidateRows()
+	m.updateVMList()
+
+	state := "disabled"
+	if msg.Enabled {
+		state = "enabled"
+	}
+	m.statusMsg = fmt.Sprintf("Deletion protection %s on %s", state, vm.Name)
+
+	if msg.ThenDelete {
+		return m.confirmDelete(*vm)
+	}
+	return m, nil
+}
+
+// handleOperationDone reports a finished operation and reloads the VM list
+func (m model) handleOperationDone(msg OperationDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	m.statusMsg = msg.Description
+	// Reload whatever the tree shows; an operation started from the templates
+	// browser shouldn't swap in the VM list
+	return m, m.loadCmd()
+}
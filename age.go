@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// AGE, UPTIME AND SORTING
+// =============================================================================
+
+// SortOrder controls how instances are ordered within the tree
+type SortOrder int
+
+const (
+	SortByName SortOrder = iota
+	SortNewest
+	SortOldest
+	SortLongestUptime
+)
+
+// String returns the display name of the sort order
+func (s SortOrder) String() string {
+	switch s {
+	case SortNewest:
+		return "newest first"
+	case SortOldest:
+		return "oldest first"
+	case SortLongestUptime:
+		return "longest uptime first"
+	default:
+		return "name"
+	}
+}
+
+// Every sort order, in the order O cycles through them
+var sortOrders = []SortOrder{SortByName, SortNewest, SortOldest, SortLongestUptime}
+
+// Next returns the sort order that follows in the toggle cycle
+func (s SortOrder) Next() SortOrder {
+	return sortOrders[(slices.Index(sortOrders, s)+1)%len(sortOrders)]
+}
+
+// CreatedAt returns when the VM was created, or the zero time if unknown
+func (vm VM) CreatedAt() time.Time {
+	t, _ := time.Parse(time.RFC3339, vm.CreationTimestamp)
+	return t
+}
+
+// Uptime returns how long a running VM has been up since its last start
+func (vm VM) Uptime(now time.Time) (time.Duration, bool) {
+	if VMStatus(vm.Status) != StatusRunning {
+		return 0, false
+	}
+	started, err := time.Parse(time.RFC3339, vm.LastStartTimestamp)
+	if err != nil {
+		// VMs never stopped since creation have no last start
+		started = vm.CreatedAt()
+	}
+	if started.IsZero() {
+		return 0, false
+	}
+	return now.Sub(started), true
+}
+
+// formatAge renders a duration in its largest whole unit, e.g. "3d"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo", int(d.Hours()/24/30))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+	}
+}
+
+// sortVMs orders VMs in place
+func sortVMs(vms []*VM, order SortOrder) {
+	now := time.Now()
+	switch order {
+	case SortByName:
+		sort.SliceStable(vms, func(i, j int) bool { return vms[i].Name < vms[j].Name })
+	case SortNewest:
+		sort.SliceStable(vms, func(i, j int) bool { return vms[i].CreatedAt().After(vms[j].CreatedAt()) })
+	case SortOldest:
+		sort.SliceStable(vms, func(i, j int) bool { return vms[i].CreatedAt().Before(vms[j].CreatedAt()) })
+	case SortLongestUptime:
+		sort.SliceStable(vms, func(i, j int) bool {
+			a, _ := vms[i].Uptime(now)
+			b, _ := vms[j].Uptime(now)
+			return a > b
+		})
+	}
+}
+
+// ageBadge returns the age or uptime shown in rows while sorting by it
+func (tm *TreeManager) ageBadge(vm *VM) string {
+	switch tm.sortOrder {
+	case SortNewest, SortOldest:
+		if created := vm.CreatedAt(); !created.IsZero() {
+			return formatAge(time.Since(created)) + " old"
+		}
+	case SortLongestUptime:
+		if uptime, ok := vm.Uptime(time.Now()); ok {
+			return "up " + formatAge(uptime)
+		}
+	}
+	return ""
+}
+
+// cycleSortOrder switches to the next instance sort order
+func (m model) cycleSortOrder() (tea.Model, tea.Cmd) {
+	if !m.listsInstances() {
+		m.statusMsg = "O only applies to instances"
+		return m, nil
+	}
+	m.treeManager.sortOrder = m.treeManager.sortOrder.Next()
+	if m.treeManager.vms != nil {
+		m.treeManager.BuildFromVMs(m.treeManager.vms)
+	}
+	m.updateVMList()
+	m.statusMsg = fmt.Sprintf("Sorted by %s", m.treeManager.sortOrder)
+	return m, nil
+}
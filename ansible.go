@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// ANSIBLE INVENTORY
+// =============================================================================
+
+// AnsibleConfig locates the inventory; unset falls back to ANSIBLE_INVENTORY
+type AnsibleConfig struct {
+	// INI or YAML file, JSON from a dynamic inventory, or an executable
+	// dynamic inventory script, which is run with --list
+	Inventory string `yaml:"inventory"`
+}
+
+// ansibleGroup is a group with its direct hosts, child groups and variables
+type ansibleGroup struct {
+	hosts    []string
+	children []string
+	vars     map[string]string
+}
+
+// ansibleInventory is an inventory in any of the supported formats, normalised
+type ansibleInventory struct {
+	groups   map[string]*ansibleGroup
+	hostVars map[string]map[string]string
+}
+
+// newAnsibleInventory returns an empty inventory
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		groups:   make(map[string]*ansibleGroup),
+		hostVars: make(map[string]map[string]string),
+	}
+}
+
+// group returns a group, creating it on first use
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &ansibleGroup{vars: make(map[string]string)}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+// addHost adds a host to a group and merges its variables
+func (inv *ansibleInventory) addHost(group, host string, vars map[string]string) {
+	g := inv.group(group)
+	g.hosts = append(g.hosts, host)
+	if inv.hostVars[host] == nil {
+		inv.hostVars[host] = make(map[string]string)
+	}
+	for key, value := range vars {
+		inv.hostVars[host][key] = value
+	}
+}
+
+// LoadAnsibleHosts lists the hosts of an Ansible inventory, grouped by the groups that list them
+func LoadAnsibleHosts(config Config) tea.Cmd {
+	return func() tea.Msg {
+		path := config.Ansible.Inventory
+		if path == "" {
+			path = os.Getenv("ANSIBLE_INVENTORY")
+		}
+		if path == "" {
+			return ErrorMsg{Err: errors.New("no inventory configured; set ansible.inventory in the config or ANSIBLE_INVENTORY")}
+		}
+		inv, err := readAnsibleInventory(config, expandHome(path))
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		var resources []Resource
+		for host, groups := range inv.hostGroups() {
+			vars := inv.effectiveVars(host, groups)
+			address := vars["ansible_host"]
+			if address == "" {
+				address = host
+			}
+			fields := []ResourceField{{Label: "Address", Value: address}}
+			if user := vars["ansible_user"]; user != "" {
+				fields = append(fields, ResourceField{Label: "User", Value: user})
+			}
+			if port := vars["ansible_port"]; port != "" {
+				fields = append(fields, ResourceField{Label: "Port", Value: port})
+			}
+			fields = append(fields, ResourceField{Label: "Groups", Value: strings.Join(groups, ", ")})
+
+			// A host in several groups is listed under each of them
+			for _, group := range groups {
+				resource := Resource{Kind: KindAnsible, Name: host, Fields: fields}
+				if group != "all" && group != "ungrouped" {
+					resource.Group = group
+				}
+				resources = append(resources, resource)
+			}
+		}
+		debugf("listed %d hosts from %s", len(inv.hostVars), path)
+		return ResourcesLoadedMsg{Kind: KindAnsible, Resources: resources}
+	}
+}
+
+// readAnsibleInventory parses an inventory, running it first if it is a dynamic inventory script
+func readAnsibleInventory(config Config, path string) (*ansibleInventory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var data []byte
+	if info.Mode()&0o111 != 0 {
+		data, err = runSourceCommand(config, path, "--list")
+		if err != nil {
+			return nil, fmt.Errorf("failed to run inventory script %s: %w", path, err)
+		}
+	} else if data, err = os.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var inv *ansibleInventory
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		inv, err = parseAnsibleJSON(trimmed)
+	case strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml"):
+		inv, err = parseAnsibleYAML(data)
+	default:
+		inv, err = parseAnsibleINI(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return inv, nil
+}
+
+// parseAnsibleJSON reads the --list output of a dynamic inventory
+func parseAnsibleJSON(data []byte) (*ansibleInventory, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var meta struct {
+		HostVars map[string]map[string]any `json:"hostvars"`
+	}
+	if m, ok := raw["_meta"]; ok {
+		if err := json.Unmarshal(m, &meta); err != nil {
+			return nil, fmt.Errorf("_meta: %w", err)
+		}
+	}
+
+	inv := newAnsibleInventory()
+	for name, body := range raw {
+		if name == "_meta" {
+			continue
+		}
+		var group struct {
+			Hosts    []string       `json:"hosts"`
+			Children []string       `json:"children"`
+			Vars     map[string]any `json:"vars"`
+		}
+		// A group is either a plain host list or an object
+		if err := json.Unmarshal(body, &group.Hosts); err != nil {
+			if err := json.Unmarshal(body, &group); err != nil {
+				return nil, fmt.Errorf("group %s: %w", name, err)
+			}
+		}
+		g := inv.group(name)
+		g.children = append(g.children, group.Children...)
+		for key, value := range group.Vars {
+			g.vars[key] = fmt.Sprint(value)
+		}
+		for _, host := range group.Hosts {
+			inv.addHost(name, host, stringVars(meta.HostVars[host]))
+		}
+	}
+	return inv, nil
+}
+
+// ansibleYAMLGroup is a group in a YAML inventory
+type ansibleYAMLGroup struct {
+	Hosts    map[string]map[string]any    `yaml:"hosts"`
+	Vars     map[string]any               `yaml:"vars"`
+	Children map[string]*ansibleYAMLGroup `yaml:"children"`
+}
+
+// parseAnsibleYAML reads a YAML inventory rooted at its top-level groups
+func parseAnsibleYAML(data []byte) (*ansibleInventory, error) {
+	var top map[string]*ansibleYAMLGroup
+	if err := yaml.Unmarshal(data, &top); err != nil {
+		return nil, err
+	}
+	inv := newAnsibleInventory()
+	for name, group := range top {
+		inv.addYAMLGroup(name, group)
+	}
+	return inv, nil
+}
+
+// addYAMLGroup adds a YAML group and, recursively, its children
+func (inv *ansibleInventory) addYAMLGroup(name string, group *ansibleYAMLGroup) {
+	g := inv.group(name)
+	if group == nil {
+		return
+	}
+	for key, value := range group.Vars {
+		g.vars[key] = fmt.Sprint(value)
+	}
+	for pattern, vars := range group.Hosts {
+		for _, host := range expandHostRange(pattern) {
+			inv.addHost(name, host, stringVars(vars))
+		}
+	}
+	for child, childGroup := range group.Children {
+		g.children = append(g.children, child)
+		inv.addYAMLGroup(child, childGroup)
+	}
+}
+
+// parseAnsibleINI reads an INI inventory with [group], [group:vars] and [group:children] sections
+func parseAnsibleINI(data []byte) (*ansibleInventory, error) {
+	inv := newAnsibleInventory()
+	section, kind := "ungrouped", "hosts"
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind, _ = strings.Cut(line[1:len(line)-1], ":")
+			if kind == "" {
+				kind = "hosts"
+			}
+			inv.group(section)
+			continue
+		}
+
+		switch kind {
+		case "hosts":
+			fields := strings.Fields(line)
+			vars := make(map[string]string)
+			for _, assignment := range fields[1:] {
+				key, value, ok := strings.Cut(assignment, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNumber, assignment)
+				}
+				vars[key] = strings.Trim(value, `"'`)
+			}
+			for _, host := range expandHostRange(fields[0]) {
+				inv.addHost(section, host, vars)
+			}
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNumber, line)
+			}
+			inv.group(section).vars[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+		case "children":
+			inv.group(section).children = append(inv.group(section).children, line)
+			inv.group(line)
+		default:
+			return nil, fmt.Errorf("line %d: unknown section type %q", lineNumber, kind)
+		}
+	}
+	return inv, scanner.Err()
+}
+
+// expandHostRange expands Ansible's numeric host ranges, e.g. web[01:03].example.com
+func expandHostRange(pattern string) []string {
+	start := strings.Index(pattern, "[")
+	end := strings.Index(pattern, "]")
+	if start < 0 || end < start {
+		return []string{pattern}
+	}
+	from, to, ok := strings.Cut(pattern[start+1:end], ":")
+	low, err1 := strconv.Atoi(from)
+	high, err2 := strconv.Atoi(to)
+	if !ok || err1 != nil || err2 != nil || high < low {
+		return []string{pattern}
+	}
+
+	var hosts []string
+	for i := low; i <= high; i++ {
+		// Leading zeros in the range pad every number to the same width
+		number := fmt.Sprintf("%0*d", len(from), i)
+		for _, rest := range expandHostRange(pattern[end+1:]) {
+			hosts = append(hosts, pattern[:start]+number+rest)
+		}
+	}
+	return hosts
+}
+
+// stringVars renders variable values as strings
+func stringVars(vars map[string]any) map[string]string {
+	out := make(map[string]string, len(vars))
+	for key, value := range vars {
+		out[key] = fmt.Sprint(value)
+	}
+	return out
+}
+
+// hostGroups returns the sorted groups that list each host directly
+func (inv *ansibleInventory) hostGroups() map[string][]string {
+	groups := make(map[string][]string)
+	for name, g := range inv.groups {
+		for _, host := range g.hosts {
+			groups[host] = append(groups[host], name)
+		}
+	}
+	for host := range groups {
+		sort.Strings(groups[host])
+		groups[host] = dedupeSorted(groups[host])
+	}
+	return groups
+}
+
+// dedupeSorted drops repeated entries from a sorted slice
+func dedupeSorted(items []string) []string {
+	out := items[:0]
+	for i, item := range items {
+		if i == 0 || item != items[i-1] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// effectiveVars resolves a host's variables: "all" first, then parent groups
+// before their children, then the host's own variables
+func (inv *ansibleInventory) effectiveVars(host string, groups []string) map[string]string {
+	parents := make(map[string][]string)
+	for name, g := range inv.groups {
+		for _, child := range g.children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+
+	vars := make(map[string]string)
+	applied := make(map[string]bool)
+	var apply func(name string)
+	apply = func(name string) {
+		if applied[name] {
+			return
+		}
+		applied[name] = true
+		for _, parent := range parents[name] {
+			apply(parent)
+		}
+		if g, ok := inv.groups[name]; ok {
+			for key, value := range g.vars {
+				vars[key] = value
+			}
+		}
+	}
+	apply("all")
+	for _, group := range groups {
+		apply(group)
+	}
+	for key, value := range inv.hostVars[host] {
+		vars[key] = value
+	}
+	return vars
+}
+
+// connectToAnsibleHost opens SSH with the host's ansible_host, ansible_user and ansible_port
+func (m model) connectToAnsibleHost(node *TreeNode) (tea.Model, tea.Cmd) {
+	resource := node.Resource
+	var options []string
+	if port := resource.Field("Port"); port != "" {
+		options = append(options, "-p", port)
+	}
+	args, err := sshArgs(resource.Field("User"), resource.Field("Address"), options...)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// REST API ACCESS
+// =============================================================================
+
+// Timeout for direct REST calls made outside gcloud
+const apiTimeout = 30 * time.Second
+
+// How long an access token from gcloud is reused; they are valid for an hour
+const tokenTTL = 10 * time.Minute
+
+// accessToken returns a cached gcloud access token, refreshing it when stale
+func (gcp *GCPService) accessToken() (string, error) {
+	gcp.tokenMu.Lock()
+	defer gcp.tokenMu.Unlock()
+
+	if gcp.native {
+		// The token source refreshes on its own
+		return gcp.adcToken()
+	}
+	if gcp.token != "" && time.Since(gcp.tokenFetched) < tokenTTL {
+		return gcp.token, nil
+	}
+	output, err := gcp.runGcloud("auth", "print-access-token", "--quiet")
+	if err != nil {
+		return "", err
+	}
+	gcp.token = strings.TrimSpace(string(output))
+	gcp.tokenFetched = time.Now()
+	return gcp.token, nil
+}
+
+// callAPI performs an authenticated Google API request using gcloud's credentials.
+// It covers the few operations gcloud has no command for.
+func (gcp *GCPService) callAPI(method, url string, body any) (data []byte, err error) {
+	if method != http.MethodGet {
+		started := time.Now()
+		defer func() {
+			record := AuditRecord{Time: started, Kind: "api", Method: method, URL: url, Duration: time.Since(started).Seconds()}
+			if err != nil {
+				record.Error = err.Error()
+			}
+			recordAudit(record)
+		}()
+	}
+
+	token, err := gcp.accessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	gcp.setActivity(method + " " + url)
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(gcp.baseContext(), method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if gcp.billingProject != "" {
+		req.Header.Set("X-Goog-User-Project", gcp.billingProject)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: apiTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	debugf("%s %s: %s", method, url, resp.Status)
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Revoked or switched credentials; fetch a fresh token next time
+		gcp.tokenMu.Lock()
+		gcp.token = ""
+		gcp.tokenMu.Unlock()
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, apiErrorMessage(data))
+	}
+	return data, nil
+}
+
+// apiErrorMessage extracts the message from a Google API error body
+func apiErrorMessage(data []byte) string {
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+		return apiErr.Error.Message
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// computeURL returns the Compute Engine API URL of a VM sub-resource
+func computeURL(project string, vm VM, suffix string) string {
+	return fmt.Sprintf("https://compute.googleapis.com/compute/v1/projects/%s/zones/%s/instances/%s%s",
+		project, vm.ZoneName(), vm.Name, suffix)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// ATTACH AND DETACH DISKS
+// =============================================================================
+
+// Picker entries of the disk action
+const (
+	attachDiskOption = "Attach an existing disk…"
+	detachDiskPrefix = "Detach "
+	readWriteOption  = "Read-write"
+	readOnlyOption   = "Read-only (can be shared with other instances)"
+)
+
+// FreeDisk is an unattached disk in the VM's zone
+type FreeDisk struct {
+	Name   string `json:"name"`
+	SizeGB string `json:"sizeGb"`
+	Type   string `json:"type"`
+}
+
+// Option renders the disk as a picker option, e.g. "data-1  100 GB pd-ssd"
+func (d FreeDisk) Option() string {
+	parts := strings.Split(d.Type, "/")
+	return fmt.Sprintf("%s  %s GB %s", d.Name, d.SizeGB, parts[len(parts)-1])
+}
+
+// FreeDisksLoadedMsg carries the disks that can be attached to a VM
+type FreeDisksLoadedMsg struct {
+	VM    VM
+	Disks []FreeDisk
+	Err   error
+}
+
+// canManageDisks reports whether the node is an instance disks can be attached to
+func canManageDisks(m model, node *TreeNode) bool {
+	return isInstance(m, node) && !m.gcpService.native
+}
+
+// detachableDisks returns the VM's secondary persistent disks
+func (vm VM) detachableDisks() []Disk {
+	var disks []Disk
+	for _, disk := range vm.Disks {
+		if !disk.Boot && disk.Source != "" && disk.Type != "SCRATCH" {
+			disks = append(disks, disk)
+		}
+	}
+	return disks
+}
+
+// startManageDisks offers attaching a disk or detaching one of the secondary disks
+func (m model) startManageDisks(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	disks := vm.detachableDisks()
+	if len(disks) == 0 {
+		return m.loadFreeDisks(vm)
+	}
+	options := []string{attachDiskOption}
+	for _, disk := range disks {
+		options = append(options, detachDiskPrefix+disk.Name())
+	}
+	return m.askPick(fmt.Sprintf("Disks of %s", vm.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		if option == attachDiskOption {
+			return m.loadFreeDisks(vm)
+		}
+		disk := strings.TrimPrefix(option, detachDiskPrefix)
+		prompt := fmt.Sprintf("Detach %s from %s? Unmount it first, or writes in flight may be lost", disk, vm.Name)
+		return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+			m.statusMsg = fmt.Sprintf("Detaching %s from %s...", disk, vm.Name)
+			return m, m.gcpService.DetachDisk(m.selectedProject, vm, disk)
+		})
+	})
+}
+
+// loadFreeDisks looks up the disks that can be attached
+func (m model) loadFreeDisks(vm VM) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Loading unattached disks in %s...", vm.ZoneName())
+	return m, m.gcpService.ListFreeDisks(m.selectedProject, vm)
+}
+
+// ListFreeDisks lists the disks in the VM's zone that no instance uses
+func (gcp *GCPService) ListFreeDisks(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "disks", "list",
+			"--project", project,
+			"--filter", fmt.Sprintf("zone:%s AND -users:*", vm.ZoneName()),
+			"--format", "json(name,sizeGb,type)")
+		if err != nil {
+			return FreeDisksLoadedMsg{VM: vm, Err: fmt.Errorf("failed to list disks: %w", err)}
+		}
+		var disks []FreeDisk
+		if err := json.Unmarshal(output, &disks); err != nil {
+			return FreeDisksLoadedMsg{VM: vm, Err: fmt.Errorf("failed to parse disks: %w", err)}
+		}
+		return FreeDisksLoadedMsg{VM: vm, Disks: disks}
+	}
+}
+
+// handleFreeDisksLoaded asks which disk to attach and how, then confirms
+func (m model) handleFreeDisksLoaded(msg FreeDisksLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	vm := msg.VM
+	if len(msg.Disks) == 0 {
+		m.statusMsg = fmt.Sprintf("No unattached disks in %s", vm.ZoneName())
+		return m, nil
+	}
+	m.statusMsg = ""
+	options := make([]string, len(msg.Disks))
+	for i, disk := range msg.Disks {
+		options[i] = disk.Option()
+	}
+	return m.askPick(fmt.Sprintf("Attach which disk to %s?", vm.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		disk, _, _ := strings.Cut(option, " ")
+		return m.askPick(fmt.Sprintf("Attach %s as", disk), []string{readWriteOption, readOnlyOption}, func(m model, mode string) (tea.Model, tea.Cmd) {
+			readOnly := mode == readOnlyOption
+			access := "read-write"
+			if readOnly {
+				access = "read-only"
+			}
+			return m.askConfirm(fmt.Sprintf("Attach %s to %s %s?", disk, vm.Name, access), func(m model) (tea.Model, tea.Cmd) {
+				m.statusMsg = fmt.Sprintf("Attaching %s to %s...", disk, vm.Name)
+				return m, m.gcpService.AttachDisk(m.selectedProject, vm, disk, readOnly)
+			})
+		})
+	})
+}
+
+// AttachDisk attaches an existing disk to the VM
+func (gcp *GCPService) AttachDisk(project string, vm VM, disk string, readOnly bool) tea.Cmd {
+	return func() tea.Msg {
+		mode := "rw"
+		if readOnly {
+			mode = "ro"
+		}
+		_, err := gcp.runGcloud("compute", "instances", "attach-disk", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			"--disk", disk,
+			"--mode", mode)
+		if err != nil {
+			return OperationDoneMsg{Err: fmt.Errorf("failed to attach %s to %s: %w", disk, vm.Name, err)}
+		}
+		return OperationDoneMsg{Description: fmt.Sprintf("Attached %s to %s", disk, vm.Name)}
+	}
+}
+
+// DetachDisk detaches a disk from the VM
+func (gcp *GCPService) DetachDisk(project string, vm VM, disk string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := gcp.runGcloud("compute", "instances", "detach-disk", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			"--disk", disk)
+		if err != nil {
+			return OperationDoneMsg{Err: fmt.Errorf("failed to detach %s from %s: %w", disk, vm.Name, err)}
+		}
+		return OperationDoneMsg{Description: fmt.Sprintf("Detached %s from %s", disk, vm.Name)}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// AUDIT LOG
+// =============================================================================
+
+// AuditRecord is one line of audit.jsonl
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"` // gcloud, api, launch, session or tunnel
+	Project  string    `json:"project,omitempty"`
+	Target   string    `json:"target,omitempty"`
+	Command  []string  `json:"command,omitempty"`
+	Method   string    `json:"method,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	Duration float64   `json:"durationSeconds,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// gcloud verbs that only read, and so are left out of the audit log
+var readOnlyVerbs = map[string]bool{
+	"list":                    true,
+	"list-instances":          true,
+	"describe":                true,
+	"cat":                     true,
+	"print-access-token":      true,
+	"get-serial-port-output":  true,
+	"get-credentials":         true,
+	"get-iam-policy":          true,
+	"get-effective-firewalls": true,
+}
+
+// auditMu serializes appends from concurrent commands
+var auditMu sync.Mutex
+
+// auditLogPath returns the audit log file
+func auditLogPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// recordAudit appends a record to the audit log. Failures are ignored: the
+// log must never stand in the way of the operation it describes.
+func recordAudit(record AuditRecord) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	path, err := auditLogPath()
+	if err != nil {
+		return
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
+
+// isReadOnlyGcloud reports whether a gcloud invocation only reads state
+func isReadOnlyGcloud(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		if readOnlyVerbs[arg] {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue returns the value of --name in args, in either --name value or --name=value form
+func flagValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// auditCommand records an external command unless it only reads
+func auditCommand(kind, target string, args []string, started time.Time, err error) {
+	if kind == "gcloud" && isReadOnlyGcloud(args) {
+		return
+	}
+	record := AuditRecord{
+		Time:     started,
+		Kind:     kind,
+		Project:  flagValue(args, "--project"),
+		Target:   target,
+		Command:  args,
+		Duration: time.Since(started).Seconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	recordAudit(record)
+}
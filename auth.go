@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// AUTHENTICATION PREFLIGHT
+// =============================================================================
+
+// AuthCheckedMsg reports the outcome of the credentials preflight
+type AuthCheckedMsg struct {
+	Account string
+	Err     error
+}
+
+// AuthLoginFinishedMsg indicates the interactive `gcloud auth login` exited
+type AuthLoginFinishedMsg struct {
+	Err error
+}
+
+// CheckAuth verifies that gcloud has an active account with usable credentials
+func (gcp *GCPService) CheckAuth() tea.Cmd {
+	return func() tea.Msg {
+		if gcp.native {
+			return gcp.checkADC()
+		}
+
+		output, err := gcp.runGcloud("auth", "list",
+			"--filter", "status:ACTIVE",
+			"--format", "value(account)")
+		if err != nil {
+			return AuthCheckedMsg{Err: fmt.Errorf("failed to read gcloud accounts: %w", err)}
+		}
+
+		account := strings.TrimSpace(string(output))
+		if account == "" {
+			return AuthCheckedMsg{Err: errors.New("no active gcloud account is configured")}
+		}
+
+		// Listing accounts doesn't touch the network; minting a token proves the credentials still work
+		if _, err := gcp.runGcloud("auth", "print-access-token", "--quiet"); err != nil {
+			return AuthCheckedMsg{Account: account, Err: fmt.Errorf("credentials for %s are expired or invalid: %w", account, err)}
+		}
+
+		return AuthCheckedMsg{Account: account}
+	}
+}
+
+// Login runs `gcloud auth login` interactively, suspending the TUI meanwhile
+func (gcp *GCPService) Login() tea.Cmd {
+	if gcp.native {
+		return func() tea.Msg {
+			return AuthLoginFinishedMsg{Err: errors.New("without gcloud, set GOOGLE_APPLICATION_CREDENTIALS to a key file or run on a VM with a service account")}
+		}
+	}
+	return tea.ExecProcess(exec.Command("gcloud", "auth", "login"), func(err error) tea.Msg {
+		return AuthLoginFinishedMsg{Err: err}
+	})
+}
+
+// handleAuthChecked proceeds to loading or shows the login screen
+func (m model) handleAuthChecked(msg AuthCheckedMsg) (tea.Model, tea.Cmd) {
+	if m.onSource() {
+		// Switched to another source while credentials were checked
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.state = StateAuthRequired
+		m.authErr = msg.Err
+		return m, nil
+	}
+
+	m.authErr = nil
+	return m.startLoading()
+}
+
+// handleAuthLoginFinished re-runs the preflight after a login attempt
+func (m model) handleAuthLoginFinished(msg AuthLoginFinishedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.authErr = fmt.Errorf("gcloud auth login failed: %w", msg.Err)
+		return m, nil
+	}
+
+	m.state = StateCheckingAuth
+	spin := m.startSpinner()
+	return m, tea.Batch(m.gcpService.CheckAuth(), spin)
+}
+
+// handleAuthRequiredKeys handles input on the login screen
+func (m model) handleAuthRequiredKeys(keypress string) (tea.Model, tea.Cmd) {
+	switch keypress {
+	case "l", "enter":
+		return m, m.gcpService.Login()
+	case "r":
+		m.state = StateCheckingAuth
+		spin := m.startSpinner()
+		return m, tea.Batch(m.gcpService.CheckAuth(), spin)
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderAuthRequired renders the login screen
+func (m model) renderAuthRequired() string {
+	if m.gcpService.native {
+		return fmt.Sprintf("\n  %s\n\n  %v\n\n  Press 'r' to check again, 'q' to quit.\n",
+			m.styles.Prompt.Render("Application Default Credentials required"), m.authErr)
+	}
+	return fmt.Sprintf("\n  %s\n\n  %v\n\n  Press 'l' to run gcloud auth login, 'r' to check again, 'q' to quit.\n",
+		m.styles.Prompt.Render("gcloud credentials required"), m.authErr)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// BASTIONS
+// =============================================================================
+
+// BastionRule routes SSH to matching instances through a jump host; the first match wins
+type BastionRule struct {
+	// Project ID glob or /regexp/; empty matches any
+	Project string `yaml:"project"`
+	// VPC network name glob or /regexp/ of the instance's first interface; empty matches any
+	Network string `yaml:"network"`
+	// Jump host as ssh's ProxyJump takes it: [user@]host[:port]
+	Jump string `yaml:"jump"`
+	// Name of a host in the -inventory catalog to jump through instead
+	Inventory string `yaml:"inventory"`
+}
+
+// validate checks the rule when the config is loaded
+func (r BastionRule) validate() error {
+	if (r.Jump == "") == (r.Inventory == "") {
+		return fmt.Errorf("needs exactly one of jump or inventory")
+	}
+	if strings.ContainsAny(r.Jump, " \t") {
+		return fmt.Errorf("jump host %q contains whitespace", r.Jump)
+	}
+	for _, pattern := range []string{r.Project, r.Network} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compilePatterns([]string{pattern}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveBastions turns inventory bastions into jump hosts, reading the catalog once
+func resolveBastions(rules []BastionRule, inventory string) ([]BastionRule, error) {
+	var hosts []InventoryHost
+	resolved := make([]BastionRule, len(rules))
+	for i, rule := range rules {
+		resolved[i] = rule
+		if rule.Inventory == "" {
+			continue
+		}
+		if inventory == "" {
+			return nil, fmt.Errorf("bastion %q is an inventory host, but no inventory is configured", rule.Inventory)
+		}
+		if hosts == nil {
+			var err error
+			if hosts, err = readInventory(expandHome(inventory)); err != nil {
+				return nil, err
+			}
+		}
+		host, ok := findInventoryHost(hosts, rule.Inventory)
+		if !ok {
+			return nil, fmt.Errorf("bastion %q is not in %s", rule.Inventory, inventory)
+		}
+		resolved[i].Jump = host.jumpSpec()
+	}
+	return resolved, nil
+}
+
+// findInventoryHost looks a catalog entry up by name
+func findInventoryHost(hosts []InventoryHost, name string) (InventoryHost, bool) {
+	for _, host := range hosts {
+		if host.Name == name {
+			return host, true
+		}
+	}
+	return InventoryHost{}, false
+}
+
+// jumpSpec formats the host for ProxyJump; its ssh_options can't be expressed there
+func (h InventoryHost) jumpSpec() string {
+	spec := h.Address
+	if h.User != "" {
+		spec = h.User + "@" + spec
+	}
+	if h.Port != 0 {
+		spec += ":" + strconv.Itoa(h.Port)
+	}
+	return spec
+}
+
+// bastionFor returns the jump host for an instance, if a rule routes it through one
+func (gcp *GCPService) bastionFor(project string, vm VM) (string, bool) {
+	network := ""
+	if len(vm.NetworkInterfaces) > 0 {
+		network = path.Base(vm.NetworkInterfaces[0].Network)
+	}
+	for _, rule := range gcp.bastions {
+		if patternMatches(rule.Project, project) && patternMatches(rule.Network, network) {
+			return rule.Jump, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CLIPBOARD
+// =============================================================================
+
+// writeOSC52 asks the terminal to set its clipboard, which also works over SSH
+func writeOSC52(value string) error {
+	seq := osc52.New(value)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if os.Getenv("STY") != "" {
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
+
+// copyToClipboard copies value to the system clipboard and reports it.
+// The local clipboard tool is tried first; OSC52 covers remote and headless sessions.
+func (m model) copyToClipboard(what, value string) (tea.Model, tea.Cmd) {
+	nativeErr := clipboard.WriteAll(value)
+	if err := writeOSC52(value); err != nil && nativeErr != nil {
+		m.statusMsg = fmt.Sprintf("Failed to copy %s: %v", what, nativeErr)
+		return m, nil
+	}
+	m.statusMsg = fmt.Sprintf("Copied %s: %s", what, value)
+	return m, nil
+}
+
+// yankChoices returns what can be copied for a VM, keyed by description
+func (m model) yankChoices(vm VM) ([]string, map[string]string) {
+	values := map[string]string{
+		"name":        vm.Name,
+		"ssh command": strings.Join(m.gcpService.SSHArgs(m.selectedProject, vm), " "),
+	}
+	if ip := vm.InternalIP(); ip != "" {
+		values["internal IP"] = ip
+	}
+	if ip := vm.ExternalIP(); ip != "" {
+		values["external IP"] = ip
+	}
+
+	var options []string
+	for _, what := range []string{"name", "internal IP", "external IP", "ssh command"} {
+		if _, ok := values[what]; ok {
+			options = append(options, what)
+		}
+	}
+	return options, values
+}
+
+// startYank asks what to copy for the selected VM
+func (m model) startYank(node *TreeNode) (tea.Model, tea.Cmd) {
+	options, values := m.yankChoices(*node.VM)
+	return m.askPick(fmt.Sprintf("Copy from %s", node.VM.Name), options, func(m model, what string) (tea.Model, tea.Cmd) {
+		return m.copyToClipboard(what, values[what])
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CLOUD SQL
+// =============================================================================
+
+// CloudSQLInstance represents a Cloud SQL instance as returned by gcloud
+type CloudSQLInstance struct {
+	Name            string `json:"name"`
+	ConnectionName  string `json:"connectionName"`
+	DatabaseVersion string `json:"databaseVersion"`
+	Region          string `json:"region"`
+	State           string `json:"state"`
+}
+
+// LoadCloudSQLInstances loads Cloud SQL instances from GCP project, grouped by region
+func (gcp *GCPService) LoadCloudSQLInstances(project string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("sql", "instances", "list",
+			"--project", project,
+			"--format", "json(name,connectionName,databaseVersion,region,state)")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list Cloud SQL instances: %w", err)}
+		}
+
+		var instances []CloudSQLInstance
+		if err := json.Unmarshal(output, &instances); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse Cloud SQL data: %w", err)}
+		}
+
+		resources := make([]Resource, len(instances))
+		for i, instance := range instances {
+			// RUNNABLE is Cloud SQL's equivalent of a running VM
+			status := instance.State
+			if status == "RUNNABLE" {
+				status = string(StatusRunning)
+			}
+
+			resources[i] = Resource{
+				Kind:     KindCloudSQL,
+				Name:     instance.Name,
+				Location: instance.Region,
+				Status:   status,
+				Group:    instance.Region,
+				Fields: []ResourceField{
+					{Label: "Version", Value: instance.DatabaseVersion},
+					{Label: "Connection", Value: instance.ConnectionName},
+				},
+			}
+		}
+
+		return ResourcesLoadedMsg{Kind: KindCloudSQL, Resources: resources}
+	}
+}
+
+// cloudSQLDefaultPort returns the conventional port for a database version
+func cloudSQLDefaultPort(databaseVersion string) int {
+	switch {
+	case strings.HasPrefix(databaseVersion, "POSTGRES"):
+		return 5432
+	case strings.HasPrefix(databaseVersion, "SQLSERVER"):
+		return 1433
+	default:
+		return 3306
+	}
+}
+
+// startCloudSQLProxy starts a cloud-sql-proxy tunnel to the selected instance
+func (m model) startCloudSQLProxy(node *TreeNode) (tea.Model, tea.Cmd) {
+	instance := node.Resource
+
+	proxyPath, err := exec.LookPath("cloud-sql-proxy")
+	if err != nil {
+		m.statusMsg = "cloud-sql-proxy not found in PATH"
+		return m, nil
+	}
+
+	port, err := freeLocalPort(cloudSQLDefaultPort(instance.Field("Version")))
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+
+	args := []string{proxyPath, "--port", fmt.Sprintf("%d", port), instance.Field("Connection")}
+	return m.startTunnel("cloud-sql-proxy", instance.Name, fmt.Sprintf("127.0.0.1:%d", port), args)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// CONFIGURATION
+// =============================================================================
+
+// Config is the user configuration read from config.yaml
+type Config struct {
+	// Hide every action that changes resources; browsing and connecting still work
+	ReadOnly bool `yaml:"read_only"`
+	// Use Application Default Credentials and the built-in SSH client instead of gcloud
+	NoGcloud bool `yaml:"no_gcloud"`
+	// Project charged for API quota and billing instead of the resource's project
+	BillingProject string `yaml:"billing_project"`
+	// How Enter connects to instances: "ssh" (default) or "et" for resumable
+	// Eternal Terminal sessions
+	ConnectMethod string   `yaml:"connect_method"`
+	ET            ETConfig `yaml:"et"`
+	// Check that sshd answers before handing the terminal to ssh, retrying
+	// for up to ssh_probe_timeout (default 30s)
+	SSHProbe        bool          `yaml:"ssh_probe"`
+	SSHProbeTimeout time.Duration `yaml:"ssh_probe_timeout"`
+	// Commands replacing the default connection for matching hosts
+	Connect []ConnectRule `yaml:"connect"`
+	// SSH users and extra gcloud compute ssh flags for matching instances
+	SSHUsers []SSHUserRule `yaml:"ssh_users"`
+	// Host key checking of the built-in SSH client used without gcloud
+	HostKeys HostKeyConfig `yaml:"host_keys"`
+	// Jump hosts SSH to matching instances is routed through
+	Bastions []BastionRule `yaml:"bastions"`
+	// Print the selection instead of connecting; only set by -pick
+	Pick bool `yaml:"-"`
+	// Go template for what -pick prints, e.g. "{{.Project}} {{.Name}}"
+	OutputTemplate string `yaml:"output_template"`
+	// Inventory to browse instead of GCP projects, e.g. vsphere
+	Source string `yaml:"source"`
+	// Sources to switch between with Tab; the first is shown unless -source is given
+	Sources    []string         `yaml:"sources"`
+	VSphere    VSphereConfig    `yaml:"vsphere"`
+	Tailscale  TailscaleConfig  `yaml:"tailscale"`
+	SSHConfig  SSHConfigSource  `yaml:"ssh_config"`
+	Ansible    AnsibleConfig    `yaml:"ansible"`
+	Kubernetes KubernetesConfig `yaml:"kubernetes"`
+	Docker     DockerConfig     `yaml:"docker"`
+	SSHFS      SSHFSConfig      `yaml:"sshfs"`
+	SSHuttle   SSHuttleConfig   `yaml:"sshuttle"`
+	Recording  RecordingConfig  `yaml:"recording"`
+	Preview    PreviewConfig    `yaml:"preview"`
+	Snapshots  SnapshotConfig   `yaml:"snapshots"`
+	Images     ImageConfig      `yaml:"images"`
+	// Bell and desktop notifications, e.g. when a watched instance is RUNNING
+	Notifications NotificationConfig `yaml:"notifications"`
+	// Host catalog browsed with -source=inventory
+	Inventory string `yaml:"inventory"`
+	// Limit for each gcloud call, e.g. 90s
+	Timeout time.Duration `yaml:"timeout"`
+	// Compute Engine filter expression applied server-side when listing VMs
+	Filter string `yaml:"filter"`
+	// Load VM Manager patch compliance for the details pane and list badges;
+	// needs the OS Config API and agent
+	PatchCompliance bool `yaml:"patch_compliance"`
+	// Start with stopped (TERMINATED) instances hidden; H toggles at runtime
+	HideTerminated bool `yaml:"hide_terminated"`
+	// Always reopen where the last run left off, like -resume
+	Resume bool `yaml:"resume"`
+	// How VMs are grouped: by instance group (default) or "zone"
+	GroupBy string `yaml:"group_by"`
+	// SSH through an IAP tunnel even to instances with an external IP
+	IAP bool `yaml:"iap"`
+	// Extra gcloud compute ssh flags for every instance, before any from ssh_users
+	SSHFlags []string `yaml:"ssh_flags"`
+	// Colors: "default", or "mono" for none
+	Theme string `yaml:"theme"`
+	// Project ID or alias opened right away, like -project
+	Project string `yaml:"project"`
+	// Named sets of settings selected with -profile
+	Profiles map[string]ProfileConfig `yaml:"profiles"`
+	// Names of instances and resources never shown, as globs or /regexps/
+	Exclude []string `yaml:"exclude"`
+	// Zones or regions to list VMs in; empty means all
+	Zones    []string       `yaml:"zones"`
+	Projects ProjectsConfig `yaml:"projects"`
+	// Short names for project IDs, usable with -project
+	Aliases    map[string]string `yaml:"aliases"`
+	Production ProductionConfig  `yaml:"production"`
+}
+
+// ProjectsConfig narrows the project selection screen, by project ID pattern
+type ProjectsConfig struct {
+	// Only these projects are listed; empty means all
+	Include []string `yaml:"include"`
+	// These projects are never listed, even if included
+	Exclude []string `yaml:"exclude"`
+}
+
+// resolveProject returns the project ID an alias stands for, or name itself
+func (c Config) resolveProject(name string) string {
+	if id, ok := c.Aliases[name]; ok {
+		return id
+	}
+	return name
+}
+
+// aliasesOf returns the sorted aliases of a project ID
+func (c Config) aliasesOf(projectID string) []string {
+	var aliases []string
+	for alias, id := range c.Aliases {
+		if id == projectID {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// filter returns the projects allowed by the include and exclude patterns
+func (c ProjectsConfig) filter(projects []Project) []Project {
+	if len(c.Include) == 0 && len(c.Exclude) == 0 {
+		return projects
+	}
+	// Already validated by loadConfig
+	include, _ := compilePatterns(c.Include)
+	exclude, _ := compilePatterns(c.Exclude)
+
+	var allowed []Project
+	for _, project := range projects {
+		if len(c.Include) > 0 && !include.Match(project.ProjectID) {
+			continue
+		}
+		if exclude.Match(project.ProjectID) {
+			continue
+		}
+		allowed = append(allowed, project)
+	}
+	return allowed
+}
+
+// ProductionConfig marks projects and labelled instances as production
+type ProductionConfig struct {
+	// Project ID patterns, e.g. "*-prod"
+	Projects []string `yaml:"projects"`
+	// Label values per key, e.g. env: [prod, production]
+	Labels map[string][]string `yaml:"labels"`
+	// Refuse destructive actions on production resources instead of confirming them
+	DisableDestructive bool `yaml:"disable_destructive"`
+}
+
+// Value of group_by grouping VMs by zone
+const GroupByZone = "zone"
+
+// validateGroupBy checks a group_by value
+func validateGroupBy(groupBy string) error {
+	if groupBy != "" && groupBy != GroupByZone {
+		return fmt.Errorf("unknown grouping %q, expected %s or nothing for instance groups", groupBy, GroupByZone)
+	}
+	return nil
+}
+
+// Themes besides the default colors
+const ThemeMono = "mono"
+
+// validateTheme checks a theme name
+func validateTheme(theme string) error {
+	if theme != "" && theme != "default" && theme != ThemeMono {
+		return fmt.Errorf("unknown theme %q, expected default or %s", theme, ThemeMono)
+	}
+	return nil
+}
+
+// ProfileConfig is a named set of settings applied over the rest of the config
+// with -profile; command-line flags still take precedence
+type ProfileConfig struct {
+	// Project ID or alias opened right away
+	Project        string   `yaml:"project"`
+	Filter         string   `yaml:"filter"`
+	Zones          []string `yaml:"zones"`
+	GroupBy        string   `yaml:"group_by"`
+	IAP            bool     `yaml:"iap"`
+	ReadOnly       bool     `yaml:"read_only"`
+	HideTerminated bool     `yaml:"hide_terminated"`
+	BillingProject string   `yaml:"billing_project"`
+}
+
+// applyProfile overrides the settings the named profile sets and returns it
+func (c *Config) applyProfile(name string) (ProfileConfig, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for known := range c.Profiles {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return profile, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return profile, fmt.Errorf("unknown profile %q, expected one of: %s", name, strings.Join(names, ", "))
+	}
+	if profile.Filter != "" {
+		c.Filter = profile.Filter
+	}
+	if len(profile.Zones) > 0 {
+		c.Zones = profile.Zones
+	}
+	if profile.GroupBy != "" {
+		c.GroupBy = profile.GroupBy
+	}
+	if profile.BillingProject != "" {
+		c.BillingProject = profile.BillingProject
+	}
+	c.IAP = c.IAP || profile.IAP
+	c.ReadOnly = c.ReadOnly || profile.ReadOnly
+	c.HideTerminated = c.HideTerminated || profile.HideTerminated
+	return profile, nil
+}
+
+// defaultConfigPath returns the location of config.yaml
+func defaultConfigPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// loadConfig reads the configuration; a missing file yields the defaults
+func loadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return parseConfig(path, data)
+}
+
+// parseConfig decodes and validates the contents of the config file at path
+func parseConfig(path string, data []byte) (Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if problems := config.problems(); len(problems) > 0 {
+		return config, fmt.Errorf("%s: %w", path, problems[0])
+	}
+	return config, nil
+}
+
+// configProblem is an invalid setting, keyed by its dotted path in config.yaml
+type configProblem struct {
+	Key string
+	Err error
+	// Set by config check, which knows where the key is
+	Line int
+}
+
+func (p configProblem) Error() string {
+	return p.Key + ": " + p.Err.Error()
+}
+
+// problems validates the settings that loadConfig can't check by type alone
+func (c Config) problems() []configProblem {
+	var problems []configProblem
+	check := func(key string, err error) {
+		if err != nil {
+			problems = append(problems, configProblem{Key: key, Err: err})
+		}
+	}
+	// List items are keyed by their 1-based position, e.g. connect.2
+	item := func(key string, i int) string {
+		return fmt.Sprintf("%s.%d", key, i+1)
+	}
+	checkPatterns := func(key string, patterns []string) {
+		for i, pattern := range patterns {
+			_, err := compilePatterns([]string{pattern})
+			check(item(key, i), err)
+		}
+	}
+	checkPatterns("exclude", c.Exclude)
+	checkPatterns("projects.include", c.Projects.Include)
+	checkPatterns("projects.exclude", c.Projects.Exclude)
+	for i, rule := range c.Connect {
+		check(item("connect", i), rule.validate())
+	}
+	for i, rule := range c.SSHUsers {
+		check(item("ssh_users", i), rule.validate())
+	}
+	check("host_keys.policy", validHostKeyPolicy(c.HostKeys.Policy))
+	for i, override := range c.HostKeys.Hosts {
+		check(item("host_keys.hosts", i), override.validate())
+	}
+	for i, rule := range c.Bastions {
+		check(item("bastions", i), rule.validate())
+	}
+	check("recording.tool", c.Recording.validate())
+	check("group_by", validateGroupBy(c.GroupBy))
+	check("theme", validateTheme(c.Theme))
+	_, err := computeFilter(c.Filter)
+	check("filter", err)
+	if method := c.ConnectMethod; method != "" && method != ConnectSSH && method != ConnectET {
+		check("connect_method", fmt.Errorf("unknown connect method %q, expected %s or %s", method, ConnectSSH, ConnectET))
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check("profiles."+name+".group_by", validateGroupBy(c.Profiles[name].GroupBy))
+		_, err = computeFilter(c.Profiles[name].Filter)
+		check("profiles."+name+".filter", err)
+	}
+	return problems
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// CONFIG CHECK
+// =============================================================================
+
+// configCheck reports every problem in the config file with its line, then
+// prints the configuration werkroom would run with
+func configCheck(args []string, out, errOut io.Writer) error {
+	flags := flag.NewFlagSet("werkroom config check", flag.ContinueOnError)
+	configFlag := flags.String("config", "", "Path to check (default: user config dir/werkroom/config.yaml)")
+	profileFlag := flags.String("profile", "", "Apply this profile to the effective configuration")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	path, err := commandConfigPath(*configFlag)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(out, "# %s does not exist; werkroom runs with the defaults (see werkroom config init)\n", path)
+		data = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var document *yaml.Node
+	if len(root.Content) > 0 {
+		document = root.Content[0]
+	}
+	var config Config
+	found := unknownKeys(document, reflect.TypeOf(config), "")
+	if err := root.Decode(&config); document != nil && err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		// Fields of the wrong type are skipped; the rest still decode
+		for _, message := range typeErr.Errors {
+			found = append(found, typeProblem(message))
+		}
+	}
+	for _, problem := range config.problems() {
+		problem.Line = keyLine(document, problem.Key)
+		found = append(found, problem)
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Line < found[j].Line })
+	var problems []string
+	for _, problem := range found {
+		if problem.Key == "" {
+			problems = append(problems, fmt.Sprintf("%s:%d: %v", path, problem.Line, problem.Err))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s:%d: %s", path, problem.Line, problem))
+		}
+	}
+
+	envProject, err := config.applyEnv(os.Getenv)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	profileName := *profileFlag
+	if profileName == "" {
+		profileName = os.Getenv(envProfile)
+	}
+	var profile ProfileConfig
+	if profileName != "" {
+		if profile, err = config.applyProfile(profileName); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if _, err := resolveBastions(config.Bastions, config.Inventory); err != nil {
+		problems = append(problems, fmt.Sprintf("%s: bastions: %v", path, err))
+	}
+	// Shown as werkroom would open it without -project
+	for _, project := range []string{profile.Project, envProject} {
+		if project != "" {
+			config.Project = project
+			break
+		}
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintln(errOut, problem)
+	}
+	var effective bytes.Buffer
+	encoder := yaml.NewEncoder(&effective)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return fmt.Errorf("failed to print the effective configuration: %w", err)
+	}
+	fmt.Fprintf(out, "# Effective configuration: %s and WERKROOM_* variables", path)
+	if profileName != "" {
+		fmt.Fprintf(out, ", then profile %s", profileName)
+	}
+	fmt.Fprintf(out, "\n%s", effective.String())
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) in %s", len(problems), path)
+	}
+	return nil
+}
+
+// typeProblem turns a decoding message like "line 3: cannot unmarshal ..." into a problem on that line
+func typeProblem(message string) configProblem {
+	if rest, ok := strings.CutPrefix(message, "line "); ok {
+		if number, text, ok := strings.Cut(rest, ": "); ok {
+			if line, err := strconv.Atoi(number); err == nil {
+				return configProblem{Err: errors.New(text), Line: line}
+			}
+		}
+	}
+	return configProblem{Err: errors.New(message)}
+}
+
+// unknownKeys returns the mapping keys under node that t has no field for
+func unknownKeys(node *yaml.Node, t reflect.Type, prefix string) []configProblem {
+	if node == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var problems []configProblem
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				problems = append(problems, configProblem{Key: prefix + key.Value, Err: errors.New("unknown setting"), Line: key.Line})
+				continue
+			}
+			problems = append(problems, unknownKeys(value, field, prefix+key.Value+".")...)
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, unknownKeys(node.Content[i+1], t.Elem(), prefix+node.Content[i].Value+".")...)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			problems = append(problems, unknownKeys(item, t.Elem(), prefix+strconv.Itoa(i+1)+".")...)
+		}
+	}
+	return problems
+}
+
+// yamlFields maps the YAML keys of a struct to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// keyLine returns the line of a dotted key, or of its closest present parent.
+// Numeric parts pick list items, counting from 1.
+func keyLine(node *yaml.Node, key string) int {
+	line := 0
+	for _, part := range strings.Split(key, ".") {
+		if node == nil {
+			break
+		}
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					line, next = node.Content[i].Line, node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(part); err == nil && i >= 1 && i <= len(node.Content) {
+				next = node.Content[i-1]
+				line = next.Line
+			}
+		}
+		node = next
+	}
+	return line
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// CONFIG COMMAND
+// =============================================================================
+
+// runConfigCommand runs `werkroom config <subcommand>` and returns the exit code
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: werkroom config init [-config PATH] [-force]")
+		fmt.Fprintln(os.Stderr, "       werkroom config check [-config PATH] [-profile NAME]")
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "init":
+		err = configInit(args[1:], os.Stdin, os.Stdout)
+	case "check":
+		err = configCheck(args[1:], os.Stdout, os.Stderr)
+	default:
+		fmt.Fprintf(os.Stderr, "werkroom: unknown config command %q, expected init or check\n", args[0])
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "werkroom: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// commandConfigPath returns the config file a subcommand works on: -config,
+// then WERKROOM_CONFIG, then the default location
+func commandConfigPath(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if path := os.Getenv(envConfig); path != "" {
+		return path, nil
+	}
+	path, err := defaultConfigPath()
+	if err != nil {
+		return "", fmt.Errorf("could not locate the config directory: %w", err)
+	}
+	return path, nil
+}
+
+// =============================================================================
+// CONFIG INIT
+// =============================================================================
+
+// Input ran out before the wizard finished
+var errInitAborted = errors.New("config init aborted, nothing was written")
+
+// configEntry is a setting written by config init, commented out when left at its default
+type configEntry struct {
+	Comment string
+	Key     string
+	Value   any
+	Set     bool
+}
+
+// configInit asks for the provider, default project, SSH options and theme,
+// then writes a commented config file
+func configInit(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("werkroom config init", flag.ContinueOnError)
+	configFlag := flags.String("config", "", "Path to write (default: user config dir/werkroom/config.yaml)")
+	forceFlag := flags.Bool("force", false, "Overwrite an existing config without asking")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	path, err := commandConfigPath(*configFlag)
+	if err != nil {
+		return err
+	}
+
+	p := &prompter{in: bufio.NewScanner(in), out: out}
+	fmt.Fprintf(out, "This writes %s. Press Enter to accept the [default].\n\n", path)
+	if _, err := os.Stat(path); err == nil && !*forceFlag {
+		overwrite, err := p.confirm(fmt.Sprintf("%s already exists. Overwrite it?", path), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			fmt.Fprintln(out, "Left the existing config untouched.")
+			return nil
+		}
+	}
+
+	entries, err := p.askConfig()
+	if err != nil {
+		return err
+	}
+	data := renderConfig(entries)
+	// Whatever was typed, the file must load; check before replacing anything
+	if _, err := parseConfig(path, data); err != nil {
+		return fmt.Errorf("the answers make a config that doesn't load, nothing was written (please report this): %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nWrote %s. Settings left at their defaults are commented out; the README lists the rest.\n", path)
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so an existing file is never left half-written
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// askConfig walks through the settings and returns them in file order
+func (p *prompter) askConfig() ([]configEntry, error) {
+	var entries []configEntry
+
+	// stdin only makes sense for a single piped run
+	sources := slices.DeleteFunc(sourceNames(), func(name string) bool { return name == "stdin" })
+	fmt.Fprintln(p.out, "Provider")
+	source, err := p.choose("What do you browse?", sources, "gcp")
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, configEntry{
+		Comment: "Inventory to browse: " + strings.Join(sources, ", "),
+		Key:     "source", Value: source, Set: source != "gcp",
+	})
+	if source != "gcp" {
+		fmt.Fprintf(p.out, "See the Other Sources section of the README for the %s settings.\n", source)
+	}
+
+	if source == "gcp" {
+		_, lookErr := exec.LookPath("gcloud")
+		noGcloud := lookErr != nil
+		if noGcloud {
+			fmt.Fprintln(p.out, "gcloud is not installed, so werkroom will use Application Default Credentials.")
+		} else if noGcloud, err = p.confirm("Use Application Default Credentials and the built-in SSH client instead of gcloud?", false); err != nil {
+			return nil, err
+		}
+		entries = append(entries, configEntry{
+			Comment: "Use Application Default Credentials and the built-in SSH client instead of gcloud",
+			Key:     "no_gcloud", Value: true, Set: noGcloud && lookErr == nil,
+		})
+
+		fmt.Fprintln(p.out, "\nDefault project")
+		suggested := ""
+		if !noGcloud {
+			suggested = gcloudDefaultProject()
+		}
+		project, err := p.ask("Project ID or alias to open on start (empty to pick from the list)", suggested)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, configEntry{
+			Comment: "Same as -project: open this project ID or alias right away",
+			Key:     "project", Value: orExample(project, "my-project"), Set: project != "",
+		})
+
+		fmt.Fprintln(p.out, "\nSSH")
+		method, err := p.choose("Connect with plain SSH or resumable Eternal Terminal sessions?", []string{ConnectSSH, ConnectET}, ConnectSSH)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, configEntry{
+			Comment: "Same as -connect-method: ssh, or et for Eternal Terminal",
+			Key:     "connect_method", Value: method, Set: method != ConnectSSH,
+		})
+		iap, err := p.confirm("Always tunnel SSH through IAP, even to instances with an external IP?", false)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, configEntry{
+			Comment: "SSH through an IAP tunnel even to instances with an external IP",
+			Key:     "iap", Value: true, Set: iap,
+		})
+		sshFlags, err := p.ask("Extra gcloud compute ssh flags, e.g. --ssh-flag=-A (empty for none)", "")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, configEntry{
+			Comment: "Extra gcloud compute ssh flags for every instance, before any from ssh_users",
+			Key:     "ssh_flags", Value: strings.Fields(orExample(sshFlags, "--ssh-flag=-A")), Set: sshFlags != "",
+		})
+	}
+
+	fmt.Fprintln(p.out, "\nTheme")
+	theme, err := p.choose("Colors?", []string{"default", ThemeMono}, "default")
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, configEntry{
+		Comment: `"mono" turns colors off`,
+		Key:     "theme", Value: ThemeMono, Set: theme == ThemeMono,
+	})
+	return entries, nil
+}
+
+// gcloudDefaultProject returns the project set with gcloud config, if any
+func gcloudDefaultProject() string {
+	output, err := exec.Command("gcloud", "config", "get-value", "project").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// orExample returns value, or an example to show in a commented-out setting
+func orExample(value, example string) string {
+	if value == "" {
+		return example
+	}
+	return value
+}
+
+// renderConfig writes the entries as YAML, each under its comment
+func renderConfig(entries []configEntry) []byte {
+	var b strings.Builder
+	b.WriteString("# werkroom configuration, written by `werkroom config init`\n")
+	b.WriteString("# Every setting is optional; the README describes all of them.\n")
+	for _, entry := range entries {
+		b.WriteString("\n# " + entry.Comment + "\n")
+		var data bytes.Buffer
+		encoder := yaml.NewEncoder(&data)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string]any{entry.Key: entry.Value}); err != nil {
+			continue
+		}
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(data.String(), "\n"), "\n") {
+			if !entry.Set {
+				b.WriteString("# ")
+			}
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// =============================================================================
+// PROMPTS
+// =============================================================================
+
+// prompter asks questions on the terminal, one answer per line
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask returns the answer to a question, or def for an empty answer
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	if !p.in.Scan() {
+		fmt.Fprintln(p.out)
+		return "", errInitAborted
+	}
+	if answer := strings.TrimSpace(p.in.Text()); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose asks for one of the options, by name or number, until it gets one
+func (p *prompter) choose(question string, options []string, def string) (string, error) {
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		if slices.Contains(options, answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(p.out, "Please enter a number from 1 to %d or one of the names above.\n", len(options))
+	}
+}
+
+// confirm asks a yes/no question until it gets an answer
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, hint)
+		if !p.in.Scan() {
+			fmt.Fprintln(p.out)
+			return false, errInitAborted
+		}
+		switch strings.ToLower(strings.TrimSpace(p.in.Text())) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n.")
+	}
+}
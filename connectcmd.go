@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CONNECT COMMAND TEMPLATES
+// =============================================================================
+
+// ConnectRule replaces the connect command for matching hosts; the first match wins
+type ConnectRule struct {
+	// Source the host is listed from, e.g. gcp or tailscale; empty matches any
+	Source string `yaml:"source"`
+	// Project ID glob or /regexp/; empty matches any
+	Project string `yaml:"project"`
+	// Host name glob or /regexp/; empty matches any
+	Host string `yaml:"host"`
+	// Go template for the command, e.g. "ssh -J bastion {{.User}}@{{.InternalIP}}"
+	Command string `yaml:"command"`
+}
+
+// ConnectTarget is what connect command templates are executed with
+type ConnectTarget struct {
+	PickedItem
+	// SSH user from ssh_users, or the local user name
+	User string
+}
+
+// validate checks the rule's patterns and template when the config is loaded
+func (r ConnectRule) validate() error {
+	if strings.TrimSpace(r.Command) == "" {
+		return fmt.Errorf("command is empty")
+	}
+	for _, pattern := range []string{r.Project, r.Host} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compilePatterns([]string{pattern}); err != nil {
+			return err
+		}
+	}
+	if _, err := commandTemplates(r.Command); err != nil {
+		return fmt.Errorf("invalid command template: %w", err)
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a host
+func (r ConnectRule) matches(source, project, host string) bool {
+	if r.Source != "" && r.Source != source {
+		return false
+	}
+	return patternMatches(r.Project, project) && patternMatches(r.Host, host)
+}
+
+// connectRule finds the rule for a node. Only hosts are covered: GCP instances
+// and items from other sources, not clusters, databases and the like.
+func (m model) connectRule(node *TreeNode) (ConnectRule, bool) {
+	if node.Type != InstanceNode && !(node.Type == ResourceNode && m.onSource()) {
+		return ConnectRule{}, false
+	}
+	project := m.selectedProject
+	if m.onSource() {
+		project = ""
+	}
+	for _, rule := range m.config.Connect {
+		if rule.matches(m.currentSource(), project, node.Name) {
+			return rule, true
+		}
+	}
+	return ConnectRule{}, false
+}
+
+// connectWithTemplate runs the rule's command in place of the default connection
+func (m model) connectWithTemplate(rule ConnectRule, node *TreeNode) (tea.Model, tea.Cmd) {
+	target := ConnectTarget{User: localUser()}
+	if node.Type == InstanceNode {
+		target.PickedItem = m.pickedVM(*node.VM)
+		m.selectedVM = node.VM
+		if rule, ok := m.gcpService.sshUserRule(m.selectedProject, *node.VM); ok && rule.User != "" {
+			target.User = rule.User
+		}
+	} else {
+		target.PickedItem = m.pickedResource(node)
+	}
+
+	templates, err := commandTemplates(rule.Command)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Invalid connect command: %v", err)
+		return m, nil
+	}
+	// Each word expands to one argument and nothing goes through a shell, so
+	// host names and labels can't inject commands or extra arguments
+	var args []string
+	for _, tmpl := range templates {
+		var word bytes.Buffer
+		if err := tmpl.Execute(&word, target); err != nil {
+			m.statusMsg = fmt.Sprintf("Connect command failed for %s: %v", node.Name, err)
+			return m, nil
+		}
+		if word.Len() > 0 {
+			args = append(args, word.String())
+		}
+	}
+	if len(args) == 0 {
+		m.statusMsg = fmt.Sprintf("Connect command for %s is empty", node.Name)
+		return m, nil
+	}
+	args[0] = expandHome(args[0])
+	debugf("connect rule for %s: %q", node.Name, args)
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
+
+// Template actions, kept whole while a command is split into words
+var (
+	templateAction      = regexp.MustCompile(`{{.*?}}`)
+	templatePlaceholder = regexp.MustCompile("\x00([0-9]+)\x00")
+)
+
+// commandTemplates splits a connect command into words like a shell would,
+// then parses each word as a template of its own
+func commandTemplates(command string) ([]*template.Template, error) {
+	var actions []string
+	protected := templateAction.ReplaceAllStringFunc(command, func(action string) string {
+		actions = append(actions, action)
+		return fmt.Sprintf("\x00%d\x00", len(actions)-1)
+	})
+	var templates []*template.Template
+	for _, word := range splitCommandLine(protected) {
+		word = templatePlaceholder.ReplaceAllStringFunc(word, func(placeholder string) string {
+			i, _ := strconv.Atoi(strings.Trim(placeholder, "\x00"))
+			return actions[i]
+		})
+		tmpl, err := template.New("connect").Option("missingkey=zero").Parse(word)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// localUser returns the name of the user running werkroom, without a Windows domain
+func localUser() string {
+	current, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	name := current.Username
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
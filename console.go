@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CLOUD CONSOLE LINKS
+// =============================================================================
+
+// Base URL of the Cloud Console
+const consoleBaseURL = "https://console.cloud.google.com"
+
+// consoleURL builds a Cloud Console URL for a path within a project
+func consoleURL(path, project string) string {
+	return fmt.Sprintf("%s/%s?project=%s", consoleBaseURL, path, url.QueryEscape(project))
+}
+
+// projectConsoleURL returns the dashboard of a project
+func projectConsoleURL(project string) string {
+	return consoleURL("home/dashboard", project)
+}
+
+// nodeConsoleURL returns the Cloud Console page of a tree node
+func (m model) nodeConsoleURL(node *TreeNode) string {
+	switch node.Type {
+	case InstanceNode:
+		return consoleURL(fmt.Sprintf("compute/instancesDetail/zones/%s/instances/%s",
+			node.VM.ZoneName(), node.VM.Name), m.selectedProject)
+	case ResourceNode:
+		if link := resourceType(node.Resource.Kind).ConsoleURL; link != nil {
+			return link(m.selectedProject, *node.Resource)
+		}
+	}
+	return resourceType(m.resourceKind).ListURL(m.selectedProject)
+}
+
+// openInConsole opens a Cloud Console page in the browser
+func (m model) openInConsole(link string) (tea.Model, tea.Cmd) {
+	if err := openBrowser(link); err != nil {
+		m.statusMsg = fmt.Sprintf("Failed to open browser: %v", err)
+		return m, nil
+	}
+	m.statusMsg = fmt.Sprintf("Opened %s", link)
+	return m, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CONTAINERS ON CONTAINER-OPTIMIZED OS
+// =============================================================================
+
+// ContainersDiscoveredMsg lists the running containers found on a VM
+type ContainersDiscoveredMsg struct {
+	VM         VM
+	Containers []string
+	Err        error
+}
+
+// IsContainerOptimized reports whether the VM runs Container-Optimized OS
+func (vm VM) IsContainerOptimized() bool {
+	if vm.GetMetadata("gce-container-declaration") != "" {
+		return true
+	}
+	for _, disk := range vm.Disks {
+		for _, license := range disk.Licenses {
+			if strings.Contains(license, "/cos-cloud/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListContainers runs `docker ps` on the VM over SSH
+func (gcp *GCPService) ListContainers(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "ssh", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			"--command", "docker ps --format '{{.Names}}'",
+			"--quiet")
+		if err != nil {
+			return ContainersDiscoveredMsg{VM: vm, Err: fmt.Errorf("failed to list containers on %s: %w", vm.Name, err)}
+		}
+		return ContainersDiscoveredMsg{VM: vm, Containers: strings.Fields(string(output))}
+	}
+}
+
+// discoverContainers looks up running containers on the selected COS VM
+func (m model) discoverContainers(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Looking for containers on %s...", node.VM.Name)
+	return m, m.gcpService.ListContainers(m.selectedProject, *node.VM)
+}
+
+// handleContainersDiscovered offers the discovered containers for connection
+func (m model) handleContainersDiscovered(msg ContainersDiscoveredMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	if len(msg.Containers) == 0 {
+		m.statusMsg = fmt.Sprintf("No running containers on %s", msg.VM.Name)
+		return m, nil
+	}
+
+	m.statusMsg = ""
+	vm := msg.VM
+	return m.askPick(fmt.Sprintf("Container on %s", vm.Name), msg.Containers, func(m model, container string) (tea.Model, tea.Cmd) {
+		m.selectedVM = &vm
+		return m.launchAndQuit(Launch{
+			Title: fmt.Sprintf("%s on %s", container, vm.Name),
+			Args:  append(m.gcpService.SSHArgs(m.selectedProject, vm), "--container", container),
+		})
+	})
+}
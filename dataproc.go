@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// DATAPROC CLUSTERS
+// =============================================================================
+
+// DataprocCluster represents a Dataproc cluster as returned by gcloud
+type DataprocCluster struct {
+	ClusterName string `json:"clusterName"`
+	Status      struct {
+		State string `json:"state"`
+	} `json:"status"`
+	Config struct {
+		MasterConfig struct {
+			InstanceNames []string `json:"instanceNames"`
+		} `json:"masterConfig"`
+		GceClusterConfig struct {
+			ZoneURI string `json:"zoneUri"`
+		} `json:"gceClusterConfig"`
+	} `json:"config"`
+}
+
+// LoadDataprocClusters loads Dataproc clusters from every region of the project
+func (gcp *GCPService) LoadDataprocClusters(project string) tea.Cmd {
+	return func() tea.Msg {
+		// Dataproc only lists one region at a time
+		output, err := gcp.runGcloud("compute", "regions", "list",
+			"--project", project,
+			"--format", "value(name)")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list regions: %w", err)}
+		}
+		regions := strings.Fields(string(output))
+
+		resources, err := fanOut(regions, func(region string) ([]Resource, error) {
+			clusters, err := gcp.listDataprocClusters(project, region)
+			if err != nil {
+				return nil, err
+			}
+			resources := make([]Resource, len(clusters))
+			for i, cluster := range clusters {
+				resources[i] = dataprocResource(region, cluster)
+			}
+			return resources, nil
+		})
+		if err != nil && len(resources) == 0 {
+			return ErrorMsg{Err: err}
+		}
+		return ResourcesLoadedMsg{Kind: KindDataproc, Resources: resources}
+	}
+}
+
+// listDataprocClusters lists the Dataproc clusters of a single region
+func (gcp *GCPService) listDataprocClusters(project, region string) ([]DataprocCluster, error) {
+	output, err := gcp.runGcloud("dataproc", "clusters", "list",
+		"--project", project,
+		"--region", region,
+		"--format", "json(clusterName,status.state,config.masterConfig.instanceNames,config.gceClusterConfig.zoneUri)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Dataproc clusters in %s: %w", region, err)
+	}
+
+	var clusters []DataprocCluster
+	if err := json.Unmarshal(output, &clusters); err != nil {
+		return nil, fmt.Errorf("failed to parse Dataproc cluster data: %w", err)
+	}
+	return clusters, nil
+}
+
+// dataprocResource converts a cluster into a tree resource
+func dataprocResource(region string, cluster DataprocCluster) Resource {
+	master := ""
+	if names := cluster.Config.MasterConfig.InstanceNames; len(names) > 0 {
+		master = names[0]
+	}
+	zone := VM{Zone: cluster.Config.GceClusterConfig.ZoneURI}.ZoneName()
+
+	return Resource{
+		Kind:     KindDataproc,
+		Name:     cluster.ClusterName,
+		Location: region,
+		Status:   cluster.Status.State,
+		Group:    region,
+		Fields: []ResourceField{
+			{Label: "Master", Value: master},
+			{Label: "Zone", Value: zone},
+		},
+	}
+}
+
+// connectToDataprocMaster opens an SSH session to the cluster's master node
+func (m model) connectToDataprocMaster(node *TreeNode) (tea.Model, tea.Cmd) {
+	cluster := node.Resource
+	master := cluster.Field("Master")
+	if master == "" {
+		m.statusMsg = fmt.Sprintf("%s has no master node", cluster.Name)
+		return m, nil
+	}
+
+	return m.connectToVM(&VM{
+		Name:   master,
+		Zone:   cluster.Field("Zone"),
+		Status: cluster.Status,
+	})
+}
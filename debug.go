@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// DEBUG LOGGING
+// =============================================================================
+
+// debugEnabled is set once at startup by -debug
+var debugEnabled bool
+
+// debugLogPath returns the default debug log file
+func debugLogPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "debug.log"), nil
+}
+
+// enableDebugLog sends the standard logger to path for the life of the program
+func enableDebugLog(path string) (io.Closer, error) {
+	f, err := tea.LogToFile(path, "werkroom")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open debug log %s: %w", path, err)
+	}
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	debugEnabled = true
+	return f, nil
+}
+
+// debugf logs when -debug is set
+func debugf(format string, args ...any) {
+	if debugEnabled {
+		log.Printf(format, args...)
+	}
+}
+
+// debugMsg logs a bubbletea message by type, with the key for key presses
+func debugMsg(msg tea.Msg) {
+	if !debugEnabled {
+		return
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		debugf("msg tea.KeyMsg %q", msg.String())
+	case ErrorMsg:
+		debugf("msg ErrorMsg: %v", msg.Err)
+	default:
+		debugf("msg %T", msg)
+	}
+}
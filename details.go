@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// DETAILS PANE
+// =============================================================================
+
+// renderDetails returns the details pane for the given node
+func (m model) renderDetails(node *TreeNode) string {
+	if node == nil {
+		return m.styles.Details.Render("No selection")
+	}
+
+	var lines []string
+	field := func(label, value string) {
+		lines = append(lines, fmt.Sprintf("%s %s", m.styles.Label.Render(label+":"), value))
+	}
+
+	if node.Type == GroupNode {
+		field("Group", node.Name)
+		field("Instances", fmt.Sprintf("%d", len(node.Children)))
+		if total, ok := groupCost(node); ok {
+			field("Estimated cost", formatCost(total))
+		}
+		if name, cpu, ok := m.leastLoaded(node); ok {
+			field("Least loaded", fmt.Sprintf("%s (CPU %.0f%%)", name, cpu*100))
+		}
+		if agents, ok := m.groupOpsAgentSummary(node); ok {
+			field("Ops Agent", agents)
+		}
+		if health, ok := m.treeManager.groupHealth[node.Name]; ok && !node.IsGKE {
+			field("Health", health.Summary())
+			field("Target size", fmt.Sprintf("%d", health.TargetSize))
+			if health.Autoscaled {
+				field("Autoscaler", fmt.Sprintf("%d-%d instances, recommends %d (mode %s)",
+					health.MinReplicas, health.MaxReplicas, health.Recommended, health.Mode))
+			}
+		}
+		return m.styles.Details.Render(strings.Join(lines, "\n"))
+	}
+
+	if node.Type == ResourceNode {
+		resource := node.Resource
+		field("Name", resource.Name)
+		field("Type", resourceType(resource.Kind).Singular)
+		field("Location", resource.Location)
+		if resource.Status != "" {
+			field("Status", VMStatus(resource.Status).GetStyle(m.styles).Render(resource.Status))
+		}
+		for _, extra := range resource.Fields {
+			if extra.Value != "" {
+				field(extra.Label, extra.Value)
+			}
+		}
+		return m.styles.Details.Render(strings.Join(lines, "\n"))
+	}
+
+	vm := node.VM
+	status := VMStatus(vm.Status)
+	field("Name", vm.Name)
+	field("Zone", vm.ZoneName())
+	field("Status", status.GetStyle(m.styles).Render(vm.Status))
+	if uptime, ok := vm.Uptime(time.Now()); ok {
+		field("Uptime", formatAge(uptime))
+	}
+	if created := vm.CreatedAt(); !created.IsZero() {
+		field("Created", fmt.Sprintf("%s (%s ago)", created.Local().Format("2006-01-02 15:04"), formatAge(time.Since(created))))
+	}
+	if machineType := vm.MachineTypeName(); machineType != "" {
+		if cost, ok := vm.MonthlyCost(); ok {
+			machineType += m.styles.Label.Render("  " + formatCost(cost))
+		}
+		machineType += m.keyHint(node, "R", "  (R to change)")
+		field("Machine type", machineType)
+	}
+	if metrics, ok := m.metrics[markKey(*vm)]; ok {
+		if len(metrics.CPU) > 0 {
+			field("CPU", formatUtilization(metrics.CPU, 1))
+		}
+		if len(metrics.Memory) > 0 {
+			field("Memory", formatUtilization(metrics.Memory, 100))
+		}
+	}
+	if vm.IsSpot() {
+		field("Provisioning", "Spot (can be preempted at any time)")
+	}
+	if vm.HasGPU() {
+		field("GPUs", vm.AcceleratorSummary())
+	}
+	if vm.IsConfidential() {
+		kind := vm.Confidential.ConfidentialInstanceType
+		if kind == "" {
+			kind = "SEV"
+		}
+		field("Confidential VM", kind)
+	}
+	if rec, ok := m.recommendationFor(node); ok {
+		summary := rec.Description
+		if savings := rec.formatSavings(); savings != "" {
+			summary += m.styles.Label.Render("  (" + savings + ")")
+		}
+		field("Recommendation", m.styles.Recommendation.Render("["+rec.Badge()+"]")+" "+summary)
+	}
+	if len(vm.Labels) > 0 {
+		field("Labels", strings.ReplaceAll(formatLabels(vm.Labels), ",", ", ")+m.keyHint(node, "L", "  (L to edit)"))
+	}
+	if agent, ok := m.opsAgentSummary(*vm); ok {
+		field("Ops Agent", agent)
+	}
+	if patches, ok := m.patchSummary(*vm); ok {
+		field("Patches", patches)
+	}
+	if findings := m.findingLines(*vm); len(findings) > 0 {
+		field("Findings", m.styles.Finding.Render(m.findingsBadge(*vm)))
+		lines = append(lines, findings...)
+	}
+	if ip := vm.InternalIP(); ip != "" {
+		field("Internal IP", ip+m.styles.Label.Render("  (I to copy)"))
+	}
+	if ip := vm.ExternalIP(); ip != "" {
+		field("External IP", ip+m.styles.Label.Render("  (E to copy)"))
+	}
+	if disks := m.diskLines(*vm); len(disks) > 0 {
+		field("Disks", m.keyHint(node, "B", "(B to snapshot)"))
+		lines = append(lines, disks...)
+	}
+	if network := vm.NetworkName(); network != "" {
+		field("Network", network)
+	}
+	if len(vm.Tags.Items) > 0 {
+		field("Network tags", strings.Join(vm.Tags.Items, ", "))
+	}
+	if result, ok := m.reachability[markKey(*vm)]; ok {
+		style := m.styles.Running
+		if !result.OK {
+			style = m.styles.Stopping
+		}
+		field("SSH reachability", style.Render(result.Summary))
+	} else {
+		field("SSH reachability", m.styles.Label.Render("unchecked (F to check)"))
+	}
+	m.renderServiceAccount(*vm, field)
+	if cluster, pool, ok := vm.GKENodePool(); ok {
+		field("GKE cluster", cluster)
+		field("Node pool", pool)
+	} else if group := vm.GetInstanceGroup(); group != "" {
+		field("Group", group)
+	}
+
+	protection := "off"
+	if vm.DeletionProtection {
+		protection = m.styles.Protected.Render("on")
+	}
+	field("Deletion protection", protection+m.keyHint(node, "p", "  (p to toggle)"))
+
+	if samples := m.latency[latencyKey(m.selectedProject, *vm)]; len(samples) > 0 {
+		avg := averageLatency(samples)
+		field("Connect time", fmt.Sprintf("%s avg %s (%d)", sparkline(samples), avg.Round(100*time.Millisecond), len(samples)))
+		if hint := m.latencyHint(vm, avg); hint != "" {
+			lines = append(lines, m.styles.Prompt.Render(hint))
+		}
+	}
+
+	return m.styles.Details.Render(strings.Join(lines, "\n"))
+}
+
+// keyHint renders hint only when the key's action is offered for the node,
+// so read-only mode doesn't advertise keys that do nothing
+func (m model) keyHint(node *TreeNode, key, hint string) string {
+	if m.findAction(key, node) == nil {
+		return ""
+	}
+	return m.styles.Label.Render(hint)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// DOCKER
+// =============================================================================
+
+// DockerConfig selects the Docker contexts whose containers are listed
+type DockerConfig struct {
+	// Default is every context from `docker context ls`
+	Contexts []string `yaml:"contexts"`
+	// Command run by docker exec, default sh
+	Shell string `yaml:"shell"`
+}
+
+// Label Compose sets on the containers of a project
+const composeProjectLabel = "com.docker.compose.project"
+
+// dockerContainer is one line of `docker ps --format '{{json .}}'`
+type dockerContainer struct {
+	ID     string `json:"ID"`
+	Names  string `json:"Names"`
+	Image  string `json:"Image"`
+	State  string `json:"State"`
+	Status string `json:"Status"`
+	Labels string `json:"Labels"`
+}
+
+// Container states mapped onto the VM statuses the tree knows how to style
+var dockerStates = map[string]VMStatus{
+	"running":    StatusRunning,
+	"created":    StatusProvisioning,
+	"restarting": StatusProvisioning,
+	"paused":     StatusSuspended,
+	"exited":     StatusTerminated,
+	"dead":       StatusTerminated,
+	"removing":   StatusStopping,
+}
+
+// dockerContexts returns the configured contexts, or all known ones
+func dockerContexts(config Config) ([]string, error) {
+	if len(config.Docker.Contexts) > 0 {
+		return config.Docker.Contexts, nil
+	}
+	output, err := runSourceCommand(config, "docker", "context", "ls", "--format", "{{.Name}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Docker contexts: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// LoadDockerContainers lists containers across contexts, grouped by context and Compose project
+func LoadDockerContainers(config Config) tea.Cmd {
+	return func() tea.Msg {
+		contexts, err := dockerContexts(config)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		resources, err := fanOut(contexts, func(context string) ([]Resource, error) {
+			output, err := runSourceCommand(config, "docker", "--context", context, "ps", "--all", "--no-trunc", "--format", "{{json .}}")
+			if err != nil {
+				return nil, fmt.Errorf("context %s: %w", context, err)
+			}
+			return parseDockerContainers(context, output)
+		})
+		if err != nil {
+			if len(resources) == 0 {
+				return ErrorMsg{Err: fmt.Errorf("failed to list containers: %w", err)}
+			}
+			// One unreachable remote shouldn't hide the other contexts
+			debugf("listing containers: %v", err)
+		}
+		return ResourcesLoadedMsg{Kind: KindDocker, Resources: resources}
+	}
+}
+
+// parseDockerContainers turns `docker ps` JSON lines into resources
+func parseDockerContainers(context string, output []byte) ([]Resource, error) {
+	var resources []Resource
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var container dockerContainer
+		if err := json.Unmarshal(scanner.Bytes(), &container); err != nil {
+			return nil, fmt.Errorf("context %s: failed to parse docker ps: %w", context, err)
+		}
+
+		group := context
+		project := dockerLabel(container.Labels, composeProjectLabel)
+		if project != "" {
+			group += "/" + project
+		}
+		fields := []ResourceField{
+			{Label: "Context", Value: context},
+			{Label: "ID", Value: container.ID},
+			{Label: "Image", Value: container.Image},
+			{Label: "Status", Value: container.Status},
+		}
+		if project != "" {
+			fields = append(fields, ResourceField{Label: "Compose project", Value: project})
+		}
+		resources = append(resources, Resource{
+			Kind:     KindDocker,
+			Name:     container.Names,
+			Location: context,
+			Status:   string(dockerStates[container.State]),
+			Group:    group,
+			Fields:   fields,
+		})
+	}
+	return resources, scanner.Err()
+}
+
+// dockerLabel picks one label out of docker ps's comma-separated key=value list
+func dockerLabel(labels, key string) string {
+	for _, label := range strings.Split(labels, ",") {
+		if k, v, ok := strings.Cut(label, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// isRunningContainer reports whether the node is a container that can be attached to
+func isRunningContainer(_ model, node *TreeNode) bool {
+	return node.Type == ResourceNode && node.Resource.Kind == KindDocker && VMStatus(node.Resource.Status) == StatusRunning
+}
+
+// execIntoContainer hands the terminal to a shell in the container
+func (m model) execIntoContainer(node *TreeNode) (tea.Model, tea.Cmd) {
+	if VMStatus(node.Resource.Status) != StatusRunning {
+		m.statusMsg = fmt.Sprintf("%s is not running (%s)", node.Name, node.Resource.Field("Status"))
+		return m, nil
+	}
+	shell := m.config.Docker.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: []string{
+		"docker", "--context", node.Resource.Location, "exec", "-it", node.Resource.Field("ID"), shell,
+	}})
+}
+
+// attachToContainer hands the terminal to the container's main process
+func (m model) attachToContainer(node *TreeNode) (tea.Model, tea.Cmd) {
+	return m.launchAndQuit(Launch{Title: node.Name, Args: []string{
+		"docker", "--context", node.Resource.Location, "attach", node.Resource.Field("ID"),
+	}})
+}
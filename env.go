@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// ENVIRONMENT OVERRIDES
+// =============================================================================
+
+// Environment variables read before the config file is located
+const (
+	envConfig  = "WERKROOM_CONFIG"
+	envProfile = "WERKROOM_PROFILE"
+)
+
+// envSetting is a WERKROOM_* variable overriding a config setting
+type envSetting struct {
+	Name  string
+	Apply func(c *Config, value string) error
+}
+
+// envSettings lists the variables applyEnv honors, in the order they're applied
+func envSettings() []envSetting {
+	return []envSetting{
+		{"WERKROOM_PROVIDER", func(c *Config, value string) error {
+			c.Source = value
+			return nil
+		}},
+		{"WERKROOM_FILTER", func(c *Config, value string) error {
+			c.Filter = value
+			return nil
+		}},
+		{"WERKROOM_ZONES", func(c *Config, value string) error {
+			c.Zones = splitList(value)
+			return nil
+		}},
+		{"WERKROOM_BILLING_PROJECT", func(c *Config, value string) error {
+			c.BillingProject = value
+			return nil
+		}},
+		{"WERKROOM_SSH_FLAGS", func(c *Config, value string) error {
+			c.SSHFlags = strings.Fields(value)
+			return nil
+		}},
+		{"WERKROOM_CONNECT_METHOD", func(c *Config, value string) error {
+			c.ConnectMethod = value
+			return nil
+		}},
+		{"WERKROOM_GROUP_BY", func(c *Config, value string) error {
+			c.GroupBy = value
+			return validateGroupBy(value)
+		}},
+		{"WERKROOM_THEME", func(c *Config, value string) error {
+			c.Theme = value
+			return validateTheme(value)
+		}},
+		{"WERKROOM_TIMEOUT", func(c *Config, value string) (err error) {
+			c.Timeout, err = time.ParseDuration(value)
+			return err
+		}},
+		{"WERKROOM_READ_ONLY", func(c *Config, value string) error {
+			// Like -read-only, it can only turn read-only mode on
+			readOnly, err := strconv.ParseBool(value)
+			c.ReadOnly = c.ReadOnly || readOnly
+			return err
+		}},
+		{"WERKROOM_IAP", func(c *Config, value string) (err error) {
+			c.IAP, err = strconv.ParseBool(value)
+			return err
+		}},
+		{"WERKROOM_HIDE_TERMINATED", func(c *Config, value string) (err error) {
+			c.HideTerminated, err = strconv.ParseBool(value)
+			return err
+		}},
+	}
+}
+
+// applyEnv overrides settings with the WERKROOM_* variables that are set, so
+// they sit between the config file and a profile. It returns
+// WERKROOM_PROJECT, which outranks the config's project but not a profile's.
+func (c *Config) applyEnv(getenv func(string) string) (string, error) {
+	for _, setting := range envSettings() {
+		value := strings.TrimSpace(getenv(setting.Name))
+		if value == "" {
+			continue
+		}
+		if err := setting.Apply(c, value); err != nil {
+			return "", fmt.Errorf("%s: %w", setting.Name, err)
+		}
+	}
+	return strings.TrimSpace(getenv("WERKROOM_PROJECT")), nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// ETERNAL TERMINAL
+// =============================================================================
+
+// ETConfig tunes Eternal Terminal sessions
+type ETConfig struct {
+	// Port etserver listens on, default 2022
+	Port int `yaml:"port"`
+}
+
+// Connection methods for connect_method
+const (
+	ConnectSSH = "ssh"
+	ConnectET  = "et"
+)
+
+// connectWithET hands the terminal to a resumable Eternal Terminal session
+func (m model) connectWithET(vm *VM) (tea.Model, tea.Cmd) {
+	gcp, project, target, port := m.gcpService, m.selectedProject, *vm, m.config.ET.Port
+	return m.launchAndQuit(Launch{Title: vm.Name, Run: func() error {
+		return gcp.etSession(project, target, port)
+	}})
+}
+
+// etSession connects with et, which bootstraps over the same ssh settings
+// gcloud uses. Without et on either end it falls back to a plain SSH session.
+func (gcp *GCPService) etSession(project string, vm VM, port int) error {
+	fallback := Launch{Title: vm.Name, Args: gcp.SSHArgs(project, vm)}
+	if _, err := exec.LookPath("et"); err != nil {
+		fmt.Fprintln(os.Stderr, "werkroom: et not found in PATH, connecting with SSH")
+		return gcp.ExecLaunch(fallback)
+	}
+
+	destination, options, err := gcp.resolveSSH(project, vm)
+	if err != nil {
+		return err
+	}
+	installed, err := hasRemoteET(destination, options)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		fmt.Fprintf(os.Stderr, "werkroom: Eternal Terminal is not installed on %s, connecting with SSH\n", vm.Name)
+		return gcp.ExecLaunch(fallback)
+	}
+
+	if port == 0 {
+		port = 2022
+	}
+	args := []string{"et"}
+	for _, option := range options {
+		args = append(args, "--ssh-option", option)
+	}
+	args = append(args, destination+":"+strconv.Itoa(port))
+	return gcp.ExecLaunch(Launch{Title: vm.Name, Args: args})
+}
+
+// hasRemoteET reports whether the VM has etterminal, which et starts over ssh
+func hasRemoteET(destination string, options []string) (bool, error) {
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=15"}
+	for _, option := range options {
+		args = append(args, "-o", option)
+	}
+	args = append(args, destination, "command -v etterminal")
+
+	output, err := exec.Command("ssh", args...).CombinedOutput()
+	debugf("probing for etterminal on %s: %v: %s", destination, err, output)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() != 255:
+		// The remote command ran; etterminal just isn't there
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to reach %s over SSH: %w: %s", destination, err, output)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SECURITY COMMAND CENTER FINDINGS
+// =============================================================================
+
+// Active, unmuted findings on Compute Engine instances
+const findingsFilter = `state="ACTIVE" AND mute!="MUTED" AND resource.type="google.compute.Instance"`
+
+// Findings listed in the details pane; the rest are counted
+const findingsShown = 5
+
+// Finding is an open Security Command Center finding on an instance
+type Finding struct {
+	Category string
+	Severity string
+}
+
+// Summary renders the finding as "HIGH open ssh port"
+func (f Finding) Summary() string {
+	category := strings.ToLower(strings.ReplaceAll(f.Category, "_", " "))
+	if f.Severity == "" || f.Severity == "SEVERITY_UNSPECIFIED" {
+		return category
+	}
+	return f.Severity + " " + category
+}
+
+// Ranks findings most severe first
+var severityRank = map[string]int{"CRITICAL": 0, "HIGH": 1, "MEDIUM": 2, "LOW": 3}
+
+// rank orders unknown severities last
+func (f Finding) rank() int {
+	if rank, ok := severityRank[f.Severity]; ok {
+		return rank
+	}
+	return len(severityRank)
+}
+
+// FindingsLoadedMsg carries findings keyed like marks (zone/name)
+type FindingsLoadedMsg struct {
+	Project  string
+	Findings map[string][]Finding
+	Err      error
+}
+
+// LoadFindings lists the project's open findings on instances
+func (gcp *GCPService) LoadFindings(project string) tea.Cmd {
+	return func() tea.Msg {
+		findings := make(map[string][]Finding)
+		query := url.Values{"filter": {findingsFilter}, "pageSize": {"1000"}}
+		for {
+			endpoint := fmt.Sprintf("https://securitycenter.googleapis.com/v1/projects/%s/sources/-/findings?%s", project, query.Encode())
+			data, err := gcp.callAPI(http.MethodGet, endpoint, nil)
+			if err != nil {
+				return FindingsLoadedMsg{Project: project, Err: fmt.Errorf("failed to list findings: %w", err)}
+			}
+			var page struct {
+				ListFindingsResults []struct {
+					Finding struct {
+						Category     string `json:"category"`
+						Severity     string `json:"severity"`
+						ResourceName string `json:"resourceName"`
+					} `json:"finding"`
+				} `json:"listFindingsResults"`
+				NextPageToken string `json:"nextPageToken"`
+			}
+			if err := json.Unmarshal(data, &page); err != nil {
+				return FindingsLoadedMsg{Project: project, Err: fmt.Errorf("failed to parse findings: %w", err)}
+			}
+			for _, result := range page.ListFindingsResults {
+				// //compute.googleapis.com/projects/P/zones/Z/instances/NAME
+				parts := strings.Split(result.Finding.ResourceName, "/")
+				if len(parts) < 4 || parts[len(parts)-2] != "instances" {
+					continue
+				}
+				key := parts[len(parts)-3] + "/" + parts[len(parts)-1]
+				findings[key] = append(findings[key], Finding{Category: result.Finding.Category, Severity: result.Finding.Severity})
+			}
+			if page.NextPageToken == "" {
+				break
+			}
+			query.Set("pageToken", page.NextPageToken)
+		}
+
+		for _, list := range findings {
+			sort.SliceStable(list, func(i, j int) bool { return list[i].rank() < list[j].rank() })
+		}
+		return FindingsLoadedMsg{Project: project, Findings: findings}
+	}
+}
+
+// handleFindingsLoaded stores findings for the current project
+func (m model) handleFindingsLoaded(msg FindingsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Project != m.selectedProject {
+		return m, nil
+	}
+	if msg.Err != nil {
+		// Security Command Center is often not activated; the badges are advisory
+		debugf("findings: %v", msg.Err)
+	}
+	m.findings = msg.Findings
+	m.updateVMList()
+	return m, nil
+}
+
+// findingsBadge returns the list badge of an instance with open findings, or ""
+func (m model) findingsBadge(vm VM) string {
+	count := len(m.findings[markKey(vm)])
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return "1 finding"
+	}
+	return fmt.Sprintf("%d findings", count)
+}
+
+// findingLines returns the details pane lines of an instance's findings
+func (m model) findingLines(vm VM) []string {
+	findings := m.findings[markKey(vm)]
+	var lines []string
+	for i, finding := range findings {
+		if i == findingsShown {
+			lines = append(lines, m.styles.Label.Render(fmt.Sprintf("  … and %d more", len(findings)-findingsShown)))
+			break
+		}
+		lines = append(lines, "  "+m.styles.Finding.Render(finding.Summary()))
+	}
+	return lines
+}
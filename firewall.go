@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// FIREWALL REACHABILITY
+// =============================================================================
+
+// Source range used by Identity-Aware Proxy TCP forwarding
+const iapSourceRange = "35.235.240.0/20"
+
+// FirewallRule is the subset of a VPC firewall rule needed to evaluate SSH access
+type FirewallRule struct {
+	Name                  string         `json:"name"`
+	Network               string         `json:"network"`
+	Direction             string         `json:"direction"`
+	Priority              int            `json:"priority"`
+	Disabled              bool           `json:"disabled"`
+	SourceRanges          []string       `json:"sourceRanges,omitempty"`
+	TargetTags            []string       `json:"targetTags,omitempty"`
+	TargetServiceAccounts []string       `json:"targetServiceAccounts,omitempty"`
+	Allowed               []FirewallPort `json:"allowed,omitempty"`
+	Denied                []FirewallPort `json:"denied,omitempty"`
+}
+
+// FirewallPort is a protocol with optional ports or port ranges
+type FirewallPort struct {
+	IPProtocol string   `json:"IPProtocol"`
+	Ports      []string `json:"ports,omitempty"`
+}
+
+// Reachability is the outcome of the SSH preflight check
+type Reachability struct {
+	OK      bool
+	Summary string
+}
+
+// ReachabilityCheckedMsg carries the preflight result for a VM
+type ReachabilityCheckedMsg struct {
+	VM     VM
+	Result Reachability
+	Err    error
+}
+
+// NetworkName returns the short name of the VM's primary network
+func (vm VM) NetworkName() string {
+	if len(vm.NetworkInterfaces) == 0 {
+		return ""
+	}
+	parts := strings.Split(vm.NetworkInterfaces[0].Network, "/")
+	return parts[len(parts)-1]
+}
+
+// matchesPort reports whether the entry covers the given TCP port
+func (p FirewallPort) matchesPort(port int) bool {
+	if p.IPProtocol != "tcp" && p.IPProtocol != "all" {
+		return false
+	}
+	if len(p.Ports) == 0 {
+		return true
+	}
+	for _, spec := range p.Ports {
+		low, high, isRange := strings.Cut(spec, "-")
+		if !isRange {
+			high = low
+		}
+		from, errLow := strconv.Atoi(low)
+		to, errHigh := strconv.Atoi(high)
+		if errLow == nil && errHigh == nil && port >= from && port <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether the rule targets the VM
+func (r FirewallRule) appliesTo(vm VM) bool {
+	if r.Disabled || (r.Direction != "" && r.Direction != "INGRESS") {
+		return false
+	}
+	// Service account targets aren't loaded, so such rules are not assumed to apply
+	if len(r.TargetServiceAccounts) > 0 {
+		return false
+	}
+	if len(r.TargetTags) == 0 {
+		return true
+	}
+	for _, target := range r.TargetTags {
+		for _, tag := range vm.Tags.Items {
+			if target == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// coversRange reports whether any of the source ranges contains the given CIDR
+func coversRange(sources []string, cidr string) bool {
+	_, want, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	wantOnes, _ := want.Mask.Size()
+	for _, source := range sources {
+		_, have, err := net.ParseCIDR(source)
+		if err != nil {
+			continue
+		}
+		haveOnes, _ := have.Mask.Size()
+		if have.Contains(want.IP) && haveOnes <= wantOnes {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateSSHReachability decides whether port 22 is open to the path gcloud will use:
+// the internet for VMs with an external IP, the IAP range otherwise
+func evaluateSSHReachability(vm VM, rules []FirewallRule) Reachability {
+	source, via := iapSourceRange, "IAP"
+	if vm.ExternalIP() != "" {
+		source, via = "0.0.0.0/0", "the internet"
+	}
+
+	// Lower priority numbers win; on ties deny beats allow
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return len(rules[i].Denied) > len(rules[j].Denied)
+	})
+
+	var partial []string
+	for _, rule := range rules {
+		if !rule.appliesTo(vm) {
+			continue
+		}
+		for _, denied := range rule.Denied {
+			if denied.matchesPort(22) && coversRange(rule.SourceRanges, source) {
+				return Reachability{Summary: fmt.Sprintf("blocked from %s by %s", via, rule.Name)}
+			}
+		}
+		for _, allowed := range rule.Allowed {
+			if !allowed.matchesPort(22) {
+				continue
+			}
+			if coversRange(rule.SourceRanges, source) {
+				return Reachability{OK: true, Summary: fmt.Sprintf("open from %s via %s", via, rule.Name)}
+			}
+			partial = append(partial, fmt.Sprintf("%s (%s)", rule.Name, strings.Join(rule.SourceRanges, ", ")))
+		}
+	}
+
+	if len(partial) > 0 {
+		return Reachability{Summary: fmt.Sprintf("only open to %s", strings.Join(partial, "; "))}
+	}
+	if via == "IAP" {
+		return Reachability{Summary: fmt.Sprintf("no rule allows tcp:22 from %s (IAP)", iapSourceRange)}
+	}
+	return Reachability{Summary: "no rule allows tcp:22"}
+}
+
+// CheckSSHReachability inspects the VM's network firewall rules for port 22
+func (gcp *GCPService) CheckSSHReachability(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "firewall-rules", "list",
+			"--project", project,
+			"--format", "json(name,network,direction,priority,disabled,sourceRanges,targetTags,targetServiceAccounts,allowed,denied)")
+		if err != nil {
+			return ReachabilityCheckedMsg{VM: vm, Err: fmt.Errorf("failed to list firewall rules: %w", err)}
+		}
+
+		var rules []FirewallRule
+		if err := json.Unmarshal(output, &rules); err != nil {
+			return ReachabilityCheckedMsg{VM: vm, Err: fmt.Errorf("failed to parse firewall rules: %w", err)}
+		}
+		var onNetwork []FirewallRule
+		for _, rule := range rules {
+			if strings.HasSuffix(rule.Network, "/"+vm.NetworkName()) {
+				onNetwork = append(onNetwork, rule)
+			}
+		}
+		return ReachabilityCheckedMsg{VM: vm, Result: evaluateSSHReachability(vm, onNetwork)}
+	}
+}
+
+// checkReachability starts the SSH preflight for the selected VM
+func (m model) checkReachability(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Checking firewall rules for %s...", node.VM.Name)
+	return m, m.gcpService.CheckSSHReachability(m.selectedProject, *node.VM)
+}
+
+// handleReachabilityChecked reports the preflight result and keeps it for the details pane
+func (m model) handleReachabilityChecked(msg ReachabilityCheckedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	// Copy on write: the map is shared with earlier model values
+	reachability := make(map[string]Reachability, len(m.reachability)+1)
+	for k, v := range m.reachability {
+		reachability[k] = v
+	}
+	reachability[markKey(msg.VM)] = msg.Result
+	m.reachability = reachability
+
+	m.statusMsg = fmt.Sprintf("SSH to %s: %s", msg.VM.Name, msg.Result.Summary)
+	return m, nil
+}
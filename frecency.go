@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// PROJECT FRECENCY
+// =============================================================================
+
+// ProjectVisits is how often and how recently a project was opened
+type ProjectVisits struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// ProjectUsage maps project IDs to their visits
+type ProjectUsage map[string]ProjectVisits
+
+// Score weighs the visit count by recency, so a project used daily last month
+// gradually gives way to the one used this week
+func (v ProjectVisits) Score(now time.Time) float64 {
+	age := now.Sub(v.LastUsed)
+	weight := 0.1
+	switch {
+	case age < 4*24*time.Hour:
+		weight = 1
+	case age < 14*24*time.Hour:
+		weight = 0.7
+	case age < 31*24*time.Hour:
+		weight = 0.5
+	case age < 90*24*time.Hour:
+		weight = 0.3
+	}
+	return float64(v.Count) * weight
+}
+
+// projectUsagePath returns the file holding project visits
+func projectUsagePath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "projects.json"), nil
+}
+
+// loadProjectUsage reads project visits, returning empty usage on any failure
+func loadProjectUsage() ProjectUsage {
+	usage := ProjectUsage{}
+	path, err := projectUsagePath()
+	if err != nil {
+		return usage
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &usage)
+	}
+	return usage
+}
+
+// recordProjectVisit counts a visit to the project and saves the usage
+func recordProjectVisit(project string) (ProjectUsage, error) {
+	usage := loadProjectUsage()
+	visits := usage[project]
+	visits.Count++
+	visits.LastUsed = time.Now()
+	usage[project] = visits
+
+	path, err := projectUsagePath()
+	if err != nil {
+		return usage, err
+	}
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return usage, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return usage, err
+	}
+	return usage, os.WriteFile(path, data, 0o644)
+}
+
+// sortProjects orders projects by frecency, or alphabetically when toggled;
+// projects never opened follow in alphabetical order
+func (m *model) sortProjects() {
+	now := time.Now()
+	sort.SliceStable(m.projects, func(i, j int) bool {
+		a, b := m.projects[i], m.projects[j]
+		if !m.projectsAlphabetical {
+			scoreA, scoreB := m.projectUsage[a.ProjectID].Score(now), m.projectUsage[b.ProjectID].Score(now)
+			if scoreA != scoreB {
+				return scoreA > scoreB
+			}
+		}
+		return a.ProjectID < b.ProjectID
+	})
+}
+
+// toggleProjectOrder switches the project list between frecency and alphabetical order
+func (m model) toggleProjectOrder() (tea.Model, tea.Cmd) {
+	m.projectsAlphabetical = !m.projectsAlphabetical
+	m.sortProjects()
+	m.refreshProjectList()
+	m.list.Select(0)
+	if m.projectsAlphabetical {
+		m.statusMsg = "Projects sorted alphabetically"
+	} else {
+		m.statusMsg = "Projects sorted by most used"
+	}
+	return m, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// GKE CLUSTERS
+// =============================================================================
+
+// GKECluster represents a GKE cluster as returned by gcloud
+type GKECluster struct {
+	Name             string `json:"name"`
+	Location         string `json:"location"`
+	Status           string `json:"status"`
+	MasterVersion    string `json:"currentMasterVersion"`
+	CurrentNodeCount int    `json:"currentNodeCount"`
+	Endpoint         string `json:"endpoint"`
+}
+
+// ClusterCredentialsMsg indicates get-credentials finished for a cluster
+type ClusterCredentialsMsg struct {
+	Cluster    string
+	Kubeconfig string
+	Err        error
+}
+
+// LoadGKEClusters loads GKE clusters from GCP project, grouped by location
+func (gcp *GCPService) LoadGKEClusters(project string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("container", "clusters", "list",
+			"--project", project,
+			"--format", "json(name,location,status,currentMasterVersion,currentNodeCount,endpoint)")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list GKE clusters: %w", err)}
+		}
+
+		var clusters []GKECluster
+		if err := json.Unmarshal(output, &clusters); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse GKE cluster data: %w", err)}
+		}
+
+		resources := make([]Resource, len(clusters))
+		for i, cluster := range clusters {
+			resources[i] = Resource{
+				Kind:     KindGKEClusters,
+				Name:     cluster.Name,
+				Location: cluster.Location,
+				Status:   cluster.Status,
+				Group:    cluster.Location,
+				Fields: []ResourceField{
+					{Label: "Version", Value: cluster.MasterVersion},
+					{Label: "Nodes", Value: fmt.Sprintf("%d", cluster.CurrentNodeCount)},
+					{Label: "Endpoint", Value: cluster.Endpoint},
+				},
+			}
+		}
+
+		return ResourcesLoadedMsg{Kind: KindGKEClusters, Resources: resources}
+	}
+}
+
+// kubeconfigPath returns the dedicated kubeconfig file for a cluster
+func kubeconfigPath(project string, cluster Resource) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s_%s_%s.yaml", project, cluster.Location, cluster.Name)
+	return filepath.Join(dir, "kubeconfig", name), nil
+}
+
+// GetClusterCredentials writes cluster credentials into a dedicated kubeconfig
+func (gcp *GCPService) GetClusterCredentials(project string, cluster Resource) tea.Cmd {
+	return func() tea.Msg {
+		path, err := kubeconfigPath(project, cluster)
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(path), 0o700)
+		}
+		if err != nil {
+			return ClusterCredentialsMsg{Cluster: cluster.Name, Err: fmt.Errorf("failed to prepare kubeconfig: %w", err)}
+		}
+
+		// gcloud writes to whatever KUBECONFIG points at
+		_, err = gcp.runGcloudWithEnv([]string{"KUBECONFIG=" + path}, "container", "clusters", "get-credentials", cluster.Name,
+			"--project", project,
+			"--location", cluster.Location)
+		if err != nil {
+			return ClusterCredentialsMsg{Cluster: cluster.Name, Err: fmt.Errorf("failed to get credentials for %s: %w", cluster.Name, err)}
+		}
+
+		return ClusterCredentialsMsg{Cluster: cluster.Name, Kubeconfig: path}
+	}
+}
+
+// fetchClusterCredentials starts get-credentials for the selected cluster
+func (m model) fetchClusterCredentials(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Fetching credentials for %s...", node.Resource.Name)
+	return m, m.gcpService.GetClusterCredentials(m.selectedProject, *node.Resource)
+}
+
+// handleClusterCredentials reports the kubeconfig and offers a shell using it
+func (m model) handleClusterCredentials(msg ClusterCredentialsMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	m.statusMsg = fmt.Sprintf("Credentials for %s written to %s", msg.Cluster, msg.Kubeconfig)
+	prompt := fmt.Sprintf("Open a shell with KUBECONFIG set for %s?", msg.Cluster)
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		return m.launchAndQuit(Launch{
+			Title: fmt.Sprintf("shell for cluster %s", msg.Cluster),
+			Args:  []string{defaultShell()},
+			Env:   append(os.Environ(), "KUBECONFIG="+msg.Kubeconfig),
+		})
+	})
+}
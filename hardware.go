@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// SCHEDULING, ACCELERATORS AND CONFIDENTIAL COMPUTING
+// =============================================================================
+
+// Scheduling holds the provisioning model of a VM
+type Scheduling struct {
+	ProvisioningModel string `json:"provisioningModel,omitempty"`
+	Preemptible       bool   `json:"preemptible,omitempty"`
+}
+
+// Accelerator is a GPU type attached to a VM
+type Accelerator struct {
+	AcceleratorType  string `json:"acceleratorType"`
+	AcceleratorCount int    `json:"acceleratorCount"`
+}
+
+// ConfidentialSpec describes Confidential VM settings
+type ConfidentialSpec struct {
+	EnableConfidentialCompute bool   `json:"enableConfidentialCompute,omitempty"`
+	ConfidentialInstanceType  string `json:"confidentialInstanceType,omitempty"`
+}
+
+// Machine families that come with GPUs attached
+var acceleratorFamilies = map[string]bool{
+	"a2": true,
+	"a3": true,
+	"g2": true,
+}
+
+// IsSpot reports whether the VM is a Spot or legacy preemptible VM
+func (vm VM) IsSpot() bool {
+	return vm.Scheduling.ProvisioningModel == "SPOT" || vm.Scheduling.Preemptible
+}
+
+// HasGPU reports whether the VM has attached or built-in accelerators
+func (vm VM) HasGPU() bool {
+	if len(vm.GuestAccelerators) > 0 {
+		return true
+	}
+	family, _, _ := strings.Cut(vm.MachineTypeName(), "-")
+	return acceleratorFamilies[family]
+}
+
+// IsConfidential reports whether the VM runs with Confidential Computing
+func (vm VM) IsConfidential() bool {
+	return vm.Confidential.EnableConfidentialCompute || vm.Confidential.ConfidentialInstanceType != ""
+}
+
+// CapabilityBadges returns the short badges shown next to the instance name
+func (vm VM) CapabilityBadges() []string {
+	var badges []string
+	if vm.IsSpot() {
+		if vm.Scheduling.ProvisioningModel == "SPOT" {
+			badges = append(badges, "spot")
+		} else {
+			badges = append(badges, "preemptible")
+		}
+	}
+	if vm.HasGPU() {
+		badges = append(badges, "gpu")
+	}
+	if vm.IsConfidential() {
+		badges = append(badges, "cvm")
+	}
+	return badges
+}
+
+// AcceleratorSummary describes the attached accelerators, e.g. "2x nvidia-tesla-t4"
+func (vm VM) AcceleratorSummary() string {
+	var parts []string
+	for _, accelerator := range vm.GuestAccelerators {
+		segments := strings.Split(accelerator.AcceleratorType, "/")
+		parts = append(parts, fmt.Sprintf("%dx %s", accelerator.AcceleratorCount, segments[len(segments)-1]))
+	}
+	if len(parts) == 0 && vm.HasGPU() {
+		return "built into " + vm.MachineTypeName()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// ORGANIZATION AND FOLDER HIERARCHY
+// =============================================================================
+
+// ProjectParent is the folder or organization a project lives in
+type ProjectParent struct {
+	Type string `json:"type"` // "folder" or "organization"
+	ID   string `json:"id"`
+}
+
+// ResourceName returns the parent as a Resource Manager name, e.g. folders/123
+func (p ProjectParent) ResourceName() string {
+	if p.Type == "" || p.ID == "" {
+		return ""
+	}
+	return p.Type + "s/" + p.ID
+}
+
+// Container is an organization or folder
+type Container struct {
+	Name        string `json:"name"` // organizations/123 or folders/456
+	DisplayName string `json:"displayName"`
+	Parent      string `json:"parent"`
+	State       string `json:"state"`
+}
+
+// projectHierarchy holds the containers visible to the user and which are expanded
+type projectHierarchy struct {
+	Containers []Container
+	Expanded   map[string]bool
+}
+
+// hierarchyRow is one line of the hierarchy view
+type hierarchyRow struct {
+	Container string // Resource name, empty for project rows
+	ProjectID string
+}
+
+// HierarchyLoadedMsg carries the organizations and folders
+type HierarchyLoadedMsg struct {
+	Containers []Container
+	Err        error
+}
+
+// LoadHierarchy lists the organizations and folders the user can see
+func (gcp *GCPService) LoadHierarchy() tea.Cmd {
+	return func() tea.Msg {
+		orgs, err := gcp.searchContainers("organizations")
+		if err != nil {
+			return HierarchyLoadedMsg{Err: fmt.Errorf("failed to list organizations: %w", err)}
+		}
+		folders, err := gcp.searchContainers("folders")
+		if err != nil {
+			return HierarchyLoadedMsg{Err: fmt.Errorf("failed to list folders: %w", err)}
+		}
+		return HierarchyLoadedMsg{Containers: append(orgs, folders...)}
+	}
+}
+
+// searchContainers pages through organizations:search or folders:search
+func (gcp *GCPService) searchContainers(kind string) ([]Container, error) {
+	var containers []Container
+	pageToken := ""
+	for {
+		query := url.Values{}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		data, err := gcp.callAPI("GET", fmt.Sprintf("https://cloudresourcemanager.googleapis.com/v3/%s:search?%s",
+			kind, query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		var items []Container
+		if raw, ok := page[kind]; ok {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, err
+			}
+		}
+		for _, item := range items {
+			if item.State == "" || item.State == "ACTIVE" {
+				containers = append(containers, item)
+			}
+		}
+
+		var next string
+		if raw, ok := page["nextPageToken"]; ok {
+			json.Unmarshal(raw, &next)
+		}
+		if next == "" {
+			return containers, nil
+		}
+		pageToken = next
+	}
+}
+
+// toggleHierarchy switches the project list between flat and folder views
+func (m model) toggleHierarchy() (tea.Model, tea.Cmd) {
+	if m.hierarchy == nil {
+		m.statusMsg = "Loading organizations and folders..."
+		return m, m.gcpService.LoadHierarchy()
+	}
+	m.showHierarchy = !m.showHierarchy
+	m.refreshProjectList()
+	m.list.Select(0)
+	m.statusMsg = ""
+	return m, nil
+}
+
+// handleHierarchyLoaded shows the folder view once the containers are known
+func (m model) handleHierarchyLoaded(msg HierarchyLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	// Organizations start expanded so the first level of folders is visible
+	expanded := make(map[string]bool)
+	for _, container := range msg.Containers {
+		if strings.HasPrefix(container.Name, "organizations/") {
+			expanded[container.Name] = true
+		}
+	}
+	m.hierarchy = &projectHierarchy{Containers: msg.Containers, Expanded: expanded}
+	m.showHierarchy = true
+	m.statusMsg = ""
+	if m.state == StateSelectingProject {
+		m.refreshProjectList()
+		m.list.Select(0)
+	}
+	return m, nil
+}
+
+// toggleContainer expands or collapses the selected organization or folder
+func (m model) toggleContainer(name string) (tea.Model, tea.Cmd) {
+	expanded := make(map[string]bool, len(m.hierarchy.Expanded)+1)
+	for key, value := range m.hierarchy.Expanded {
+		expanded[key] = value
+	}
+	expanded[name] = !expanded[name]
+
+	hierarchy := *m.hierarchy
+	hierarchy.Expanded = expanded
+	m.hierarchy = &hierarchy
+	index := m.list.Index()
+	m.refreshProjectList()
+	m.list.Select(index)
+	return m, nil
+}
+
+// selectedHierarchyRow returns the row under the cursor in the folder view
+func (m model) selectedHierarchyRow() (hierarchyRow, bool) {
+	index := m.list.Index()
+	if !m.showHierarchy || index < 0 || index >= len(m.hierarchyRows) {
+		return hierarchyRow{}, false
+	}
+	return m.hierarchyRows[index], true
+}
+
+// hierarchyItems renders the containers holding listed projects as a tree,
+// followed by projects whose parent is not visible
+func (m model) hierarchyItems() ([]list.Item, []hierarchyRow) {
+	containers := make(map[string]Container)
+	for _, container := range m.hierarchy.Containers {
+		containers[container.Name] = container
+	}
+
+	children := make(map[string][]string)
+	var roots []string
+	for _, container := range m.hierarchy.Containers {
+		if _, ok := containers[container.Parent]; ok {
+			children[container.Parent] = append(children[container.Parent], container.Name)
+		} else {
+			roots = append(roots, container.Name)
+		}
+	}
+	byDisplayName := func(names []string) {
+		sort.Slice(names, func(i, j int) bool {
+			return containers[names[i]].DisplayName < containers[names[j]].DisplayName
+		})
+	}
+	byDisplayName(roots)
+	for _, names := range children {
+		byDisplayName(names)
+	}
+
+	// Projects keep the list order (frecency or alphabetical) within their parent
+	projects := make(map[string][]Project)
+	var orphans []Project
+	for _, project := range m.projects {
+		parent := project.Parent.ResourceName()
+		if _, ok := containers[parent]; ok {
+			projects[parent] = append(projects[parent], project)
+		} else {
+			orphans = append(orphans, project)
+		}
+	}
+
+	var count func(name string) int
+	count = func(name string) int {
+		total := len(projects[name])
+		for _, child := range children[name] {
+			total += count(child)
+		}
+		return total
+	}
+
+	var items []list.Item
+	var rows []hierarchyRow
+	var walk func(name string, depth int)
+	walk = func(name string, depth int) {
+		total := count(name)
+		if total == 0 {
+			return
+		}
+		indent := strings.Repeat("  ", depth)
+		icon := m.styles.Collapsed.Render("▶")
+		if m.hierarchy.Expanded[name] {
+			icon = m.styles.Expanded.Render("▼")
+		}
+		label := fmt.Sprintf("%s%s %s (%d projects)", indent, icon, m.styles.Group.Render(containers[name].DisplayName), total)
+		items = append(items, item(label))
+		rows = append(rows, hierarchyRow{Container: name})
+
+		if !m.hierarchy.Expanded[name] {
+			return
+		}
+		for _, child := range children[name] {
+			walk(child, depth+1)
+		}
+		for _, project := range projects[name] {
+			items = append(items, item(indent+"  "+m.projectLabel(project)))
+			rows = append(rows, hierarchyRow{ProjectID: project.ProjectID})
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	for _, project := range orphans {
+		items = append(items, item(m.projectLabel(project)))
+		rows = append(rows, hierarchyRow{ProjectID: project.ProjectID})
+	}
+	return items, rows
+}
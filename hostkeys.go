@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// =============================================================================
+// HOST KEY POLICY
+// =============================================================================
+
+// Host key policies for the built-in SSH client
+const (
+	// Trust a host's key the first time, reject it if it changes later
+	HostKeyAcceptNew = "accept-new"
+	// Only connect to hosts whose key is already known
+	HostKeyStrict = "strict"
+)
+
+// HostKeyConfig controls host key checking of the built-in SSH client
+type HostKeyConfig struct {
+	// accept-new (default) or strict
+	Policy string `yaml:"policy"`
+	// known_hosts files to check; new keys are added to the first. Default is
+	// known_hosts in the werkroom state directory
+	KnownHosts []string `yaml:"known_hosts"`
+	// Policies for particular instances; the first match wins
+	Hosts []HostKeyOverride `yaml:"hosts"`
+}
+
+// HostKeyOverride sets the policy for instances matching a name pattern
+type HostKeyOverride struct {
+	// Instance name glob or /regexp/
+	Host   string `yaml:"host"`
+	Policy string `yaml:"policy"`
+}
+
+// validate checks the override's pattern and policy when the config is loaded
+func (o HostKeyOverride) validate() error {
+	if _, err := compilePatterns([]string{o.Host}); err != nil || o.Host == "" {
+		return fmt.Errorf("invalid host pattern %q", o.Host)
+	}
+	return validHostKeyPolicy(o.Policy)
+}
+
+// validHostKeyPolicy rejects unknown policy names; empty means the default
+func validHostKeyPolicy(policy string) error {
+	switch policy {
+	case "", HostKeyAcceptNew, HostKeyStrict:
+		return nil
+	}
+	return fmt.Errorf("unknown host key policy %q, expected %s or %s", policy, HostKeyAcceptNew, HostKeyStrict)
+}
+
+// policyFor returns the policy for an instance
+func (c HostKeyConfig) policyFor(name string) string {
+	policy := c.Policy
+	for _, override := range c.Hosts {
+		if patternMatches(override.Host, name) {
+			policy = override.Policy
+			break
+		}
+	}
+	if policy == "" {
+		return HostKeyAcceptNew
+	}
+	return policy
+}
+
+// knownHostsFiles returns the files to check, creating the first if needed
+// and skipping others that don't exist, like ssh does
+func (c HostKeyConfig) knownHostsFiles() ([]string, error) {
+	var files []string
+	for _, file := range c.KnownHosts {
+		files = append(files, expandHome(file))
+	}
+	if len(files) == 0 {
+		dir, err := stateDir()
+		if err != nil {
+			return nil, err
+		}
+		files = []string{filepath.Join(dir, "known_hosts")}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(files[0]), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(files[0], os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	existing := files[:1]
+	for _, file := range files[1:] {
+		_, err := os.Stat(file)
+		switch {
+		case err == nil:
+			existing = append(existing, file)
+		case errors.Is(err, os.ErrNotExist):
+			debugf("skipping missing known hosts file %s", file)
+		default:
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// callback checks the instance's host key according to its policy. Changed
+// keys are always rejected; unknown keys are recorded under accept-new.
+func (c HostKeyConfig) callback(name string) (ssh.HostKeyCallback, error) {
+	files, err := c.knownHostsFiles()
+	if err != nil {
+		return nil, err
+	}
+	check, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+	policy := c.policyFor(name)
+	debugf("host key policy for %s: %s, known hosts %v", name, policy, files)
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			return err
+		}
+		if policy == HostKeyStrict {
+			return fmt.Errorf("host key for %s (%s) is unknown and the host key policy is strict", name, hostname)
+		}
+		f, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+		return err
+	}, nil
+}
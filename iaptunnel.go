@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// IAP TCP TUNNELS
+// =============================================================================
+
+// startIAPTunnel asks for a VM port to forward through Identity-Aware Proxy
+func (m model) startIAPTunnel(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	prompt := fmt.Sprintf("Port on %s to forward, e.g. 5432 or 3389:13389 for remote:local", vm.Name)
+	return m.askInput(prompt, "", func(m model, value string) (tea.Model, tea.Cmd) {
+		remote, local, explicit, err := parsePortSpec(strings.TrimSpace(value))
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		// A local port that was asked for must be the one used
+		if explicit {
+			_, err = listenLocalPort(local)
+		} else {
+			local, err = freeLocalPort(local)
+		}
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		localAddr := fmt.Sprintf("127.0.0.1:%d", local)
+		args := []string{
+			"gcloud", "compute", "start-iap-tunnel", vm.Name, strconv.Itoa(remote),
+			"--local-host-port", localAddr,
+			"--project", m.selectedProject,
+			"--zone", vm.ZoneName(),
+		}
+		return m.startTunnel("iap", fmt.Sprintf("%s:%d", vm.Name, remote), localAddr, args)
+	})
+}
+
+// parsePortSpec parses "remote" or "remote:local" and reports whether the
+// local port was given; it defaults to the remote one
+func parsePortSpec(spec string) (int, int, bool, error) {
+	remoteText, localText, hasLocal := strings.Cut(spec, ":")
+	remote, err := parsePort(remoteText)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if !hasLocal {
+		return remote, remote, false, nil
+	}
+	local, err := parsePort(localText)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return remote, local, true, nil
+}
+
+// parsePort parses a TCP port number
+func parsePort(text string) (int, error) {
+	port, err := strconv.Atoi(text)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", text)
+	}
+	return port, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// MACHINE IMAGES AND CUSTOM IMAGES
+// =============================================================================
+
+// ImageConfig tunes image creation
+type ImageConfig struct {
+	// Default Cloud Storage location for new images, e.g. "eu" or
+	// "europe-west1"; empty lets Google pick the nearest multi-region
+	StorageLocation string `yaml:"storage_location"`
+}
+
+// Picker entries for the kind of image to create
+const (
+	machineImageOption = "Machine image (all disks and instance settings)"
+	customImageOption  = "Custom image (boot disk only)"
+)
+
+// bootDisk returns the VM's boot disk
+func (vm VM) bootDisk() (Disk, bool) {
+	for _, disk := range vm.Disks {
+		if disk.Boot && disk.Source != "" {
+			return disk, true
+		}
+	}
+	return Disk{}, false
+}
+
+// canCreateImage reports whether the node is an instance images can be created from
+func canCreateImage(m model, node *TreeNode) bool {
+	return isInstance(m, node) && !m.gcpService.native
+}
+
+// startCreateImage asks which kind of image to create from the instance
+func (m model) startCreateImage(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	options := []string{machineImageOption, customImageOption}
+	return m.askPick(fmt.Sprintf("Create which image from %s?", vm.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		if option == customImageOption {
+			if VMStatus(vm.Status) != StatusTerminated {
+				// Imaging a disk in use risks an inconsistent filesystem
+				m.statusMsg = fmt.Sprintf("Stop %s before creating a custom image of its boot disk", vm.Name)
+				return m, nil
+			}
+			if _, ok := vm.bootDisk(); !ok {
+				m.statusMsg = fmt.Sprintf("%s has no boot disk", vm.Name)
+				return m, nil
+			}
+		}
+		return m.askImageName(vm, option == machineImageOption)
+	})
+}
+
+// askImageName asks for the image name, then its storage location
+func (m model) askImageName(vm VM, machineImage bool) (tea.Model, tea.Cmd) {
+	suggested, _ := resourceName(vm.Name + "-" + time.Now().UTC().Format("20060102-150405"))
+	return m.askInput("Image name", suggested, func(m model, text string) (tea.Model, tea.Cmd) {
+		name, err := resourceName(text)
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		prompt := "Storage location, e.g. eu or europe-west1 (empty for the nearest multi-region)"
+		return m.askInput(prompt, m.config.Images.StorageLocation, func(m model, storage string) (tea.Model, tea.Cmd) {
+			storage = strings.TrimSpace(storage)
+			if machineImage {
+				return m, m.gcpService.CreateMachineImage(m.selectedProject, vm, name, storage)
+			}
+			return m, m.gcpService.CreateImage(m.selectedProject, vm, name, storage)
+		})
+	})
+}
+
+// CreateMachineImage starts a machine image of the VM, tracked as a task
+func (gcp *GCPService) CreateMachineImage(project string, vm VM, name, storage string) tea.Cmd {
+	args := []string{"compute", "machine-images", "create", name,
+		"--source-instance", vm.Name,
+		"--source-instance-zone", vm.ZoneName()}
+	if storage != "" {
+		args = append(args, "--storage-location", storage)
+	}
+	return gcp.StartOperation("Machine image "+name, project, "global", args...)
+}
+
+// CreateImage starts a custom image of the VM's boot disk, tracked as a task
+func (gcp *GCPService) CreateImage(project string, vm VM, name, storage string) tea.Cmd {
+	disk, _ := vm.bootDisk()
+	args := []string{"compute", "images", "create", name, "--source-disk", disk.Name()}
+	// .../zones/ZONE/disks/NAME or .../regions/REGION/disks/NAME
+	location := path.Dir(path.Dir(disk.Source))
+	if path.Base(path.Dir(location)) == "regions" {
+		args = append(args, "--source-disk-region", path.Base(location))
+	} else {
+		args = append(args, "--source-disk-zone", vm.ZoneName())
+	}
+	if storage != "" {
+		args = append(args, "--storage-location", storage)
+	}
+	return gcp.StartOperation("Image "+name, project, "global", args...)
+}
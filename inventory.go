@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// STATIC INVENTORY
+// =============================================================================
+
+// InventoryHost is one entry of a user-maintained host catalog
+type InventoryHost struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	User    string `yaml:"user"`
+	Port    int    `yaml:"port"`
+	// Hosts are listed under each of their tags
+	Tags []string `yaml:"tags"`
+	// Passed to ssh as -o Key=Value
+	SSHOptions map[string]string `yaml:"ssh_options"`
+}
+
+// inventoryFile is the catalog format; a bare list of hosts is accepted too
+type inventoryFile struct {
+	Hosts []InventoryHost `yaml:"hosts"`
+}
+
+// readInventory parses a YAML or JSON host catalog
+func readInventory(path string) ([]InventoryHost, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	// JSON is valid YAML, so one parser covers both
+	var file inventoryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		if err := yaml.Unmarshal(data, &file.Hosts); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: expected a list of hosts or a hosts: key", path)
+		}
+	}
+	for i, host := range file.Hosts {
+		if host.Name == "" {
+			return nil, fmt.Errorf("%s: host %d has no name", path, i+1)
+		}
+		if host.Address == "" {
+			file.Hosts[i].Address = host.Name
+		}
+	}
+	return file.Hosts, nil
+}
+
+// LoadInventoryHosts lists the hosts of the -inventory catalog, grouped by tag
+func LoadInventoryHosts(config Config) tea.Cmd {
+	return func() tea.Msg {
+		if config.Inventory == "" {
+			return ErrorMsg{Err: errors.New("no inventory file; pass -inventory or set inventory in the config")}
+		}
+		hosts, err := readInventory(expandHome(config.Inventory))
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		var resources []Resource
+		for _, host := range hosts {
+			fields := []ResourceField{{Label: "Address", Value: host.Address}}
+			if host.User != "" {
+				fields = append(fields, ResourceField{Label: "User", Value: host.User})
+			}
+			if host.Port != 0 {
+				fields = append(fields, ResourceField{Label: "Port", Value: strconv.Itoa(host.Port)})
+			}
+			if len(host.Tags) > 0 {
+				fields = append(fields, ResourceField{Label: "Tags", Value: strings.Join(host.Tags, ", ")})
+			}
+			// One field per option, since values like ProxyCommand contain spaces
+			options := make([]string, 0, len(host.SSHOptions))
+			for key, value := range host.SSHOptions {
+				options = append(options, key+"="+value)
+			}
+			sort.Strings(options)
+			for _, option := range options {
+				fields = append(fields, ResourceField{Label: "SSH option", Value: option})
+			}
+
+			resource := Resource{Kind: KindInventory, Name: host.Name, Fields: fields}
+			if len(host.Tags) == 0 {
+				resources = append(resources, resource)
+			}
+			for _, tag := range host.Tags {
+				resource.Group = tag
+				resources = append(resources, resource)
+			}
+		}
+		return ResourcesLoadedMsg{Kind: KindInventory, Resources: resources}
+	}
+}
+
+// connectToInventoryHost opens SSH with the catalog's address, user, port and options
+func (m model) connectToInventoryHost(node *TreeNode) (tea.Model, tea.Cmd) {
+	resource := node.Resource
+	var options []string
+	if port := resource.Field("Port"); port != "" {
+		options = append(options, "-p", port)
+	}
+	for _, field := range resource.Fields {
+		if field.Label == "SSH option" {
+			options = append(options, "-o", field.Value)
+		}
+	}
+	args, err := sshArgs(resource.Field("User"), resource.Field("Address"), options...)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
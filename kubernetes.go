@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// KUBERNETES PODS
+// =============================================================================
+
+// KubernetesConfig selects the pods listed from the kubeconfig
+type KubernetesConfig struct {
+	// Default is the current kubeconfig context
+	Context string `yaml:"context"`
+	// Default is all namespaces
+	Namespace string `yaml:"namespace"`
+	// "namespace" (default) or "deployment"
+	GroupBy string `yaml:"group_by"`
+	// Command run by kubectl exec, default sh
+	Shell string `yaml:"shell"`
+}
+
+// Pod phases mapped onto the VM statuses the tree knows how to style
+var podPhases = map[string]VMStatus{
+	"Running":   StatusRunning,
+	"Pending":   StatusProvisioning,
+	"Succeeded": StatusTerminated,
+	"Failed":    StatusTerminated,
+}
+
+// kubePod is the part of a pod from `kubectl get pods -o json` werkroom uses
+type kubePod struct {
+	Metadata struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace"`
+		Labels          map[string]string `json:"labels"`
+		OwnerReferences []struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name string `json:"name"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string `json:"phase"`
+		PodIP             string `json:"podIP"`
+		ContainerStatuses []struct {
+			RestartCount int `json:"restartCount"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// kubectlArgs prefixes a kubectl command with the configured context
+func (c KubernetesConfig) kubectlArgs(args ...string) []string {
+	if c.Context != "" {
+		return append([]string{"--context", c.Context}, args...)
+	}
+	return args
+}
+
+// LoadKubernetesPods lists pods of the kubeconfig context, grouped by namespace or deployment
+func LoadKubernetesPods(config Config) tea.Cmd {
+	return func() tea.Msg {
+		kube := config.Kubernetes
+		args := []string{"get", "pods", "-o", "json"}
+		if kube.Namespace != "" {
+			args = append(args, "-n", kube.Namespace)
+		} else {
+			args = append(args, "--all-namespaces")
+		}
+		output, err := runSourceCommand(config, "kubectl", kube.kubectlArgs(args...)...)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list pods: %w", err)}
+		}
+		var pods struct {
+			Items []kubePod `json:"items"`
+		}
+		if err := json.Unmarshal(output, &pods); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse pods: %w", err)}
+		}
+
+		resources := make([]Resource, 0, len(pods.Items))
+		for _, pod := range pods.Items {
+			restarts := 0
+			for _, status := range pod.Status.ContainerStatuses {
+				restarts += status.RestartCount
+			}
+			containers := make([]string, len(pod.Spec.Containers))
+			for i, container := range pod.Spec.Containers {
+				containers[i] = container.Name
+			}
+
+			resource := Resource{
+				Kind:     KindKubernetes,
+				Name:     pod.Metadata.Name,
+				Location: pod.Metadata.Namespace,
+				Status:   string(podPhases[pod.Status.Phase]),
+				Group:    pod.Metadata.Namespace,
+				Fields: []ResourceField{
+					{Label: "Namespace", Value: pod.Metadata.Namespace},
+					{Label: "Phase", Value: pod.Status.Phase},
+					{Label: "Node", Value: pod.Spec.NodeName},
+					{Label: "IP", Value: pod.Status.PodIP},
+					{Label: "Containers", Value: strings.Join(containers, ", ")},
+					{Label: "Restarts", Value: strconv.Itoa(restarts)},
+				},
+			}
+			if owner := podOwner(pod); owner != "" {
+				resource.Fields = append(resource.Fields, ResourceField{Label: "Owner", Value: owner})
+				if kube.GroupBy == "deployment" {
+					resource.Group = pod.Metadata.Namespace + "/" + owner
+				}
+			}
+			resources = append(resources, resource)
+		}
+		return ResourcesLoadedMsg{Kind: KindKubernetes, Resources: resources}
+	}
+}
+
+// podOwner names the workload behind a pod, resolving ReplicaSets to their Deployment
+func podOwner(pod kubePod) string {
+	if len(pod.Metadata.OwnerReferences) == 0 {
+		return ""
+	}
+	owner := pod.Metadata.OwnerReferences[0]
+	if hash := pod.Metadata.Labels["pod-template-hash"]; owner.Kind == "ReplicaSet" && hash != "" {
+		if deployment, ok := strings.CutSuffix(owner.Name, "-"+hash); ok {
+			return deployment
+		}
+	}
+	return owner.Name
+}
+
+// execIntoPod hands the terminal to an interactive shell in the pod
+func (m model) execIntoPod(node *TreeNode) (tea.Model, tea.Cmd) {
+	resource := node.Resource
+	if VMStatus(resource.Status) != StatusRunning {
+		m.statusMsg = fmt.Sprintf("%s is %s, not running", node.Name, resource.Field("Phase"))
+		return m, nil
+	}
+	kube := m.config.Kubernetes
+	shell := kube.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+	args := append([]string{"kubectl"}, kube.kubectlArgs("exec", "-it", "-n", resource.Location, resource.Name, "--", shell)...)
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// LABELS
+// =============================================================================
+
+// labelKeyPattern matches valid GCP label keys
+var labelKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+
+// LabelEdit is a parsed set of label changes
+type LabelEdit struct {
+	Set    map[string]string
+	Remove []string
+}
+
+// parseLabelEdit parses "key=value" (set) and "-key" (remove) tokens
+func parseLabelEdit(input string) (LabelEdit, error) {
+	edit := LabelEdit{Set: make(map[string]string)}
+
+	for _, token := range strings.Fields(input) {
+		if key, found := strings.CutPrefix(token, "-"); found {
+			if !labelKeyPattern.MatchString(key) {
+				return edit, fmt.Errorf("invalid label key %q", key)
+			}
+			edit.Remove = append(edit.Remove, key)
+			continue
+		}
+
+		key, value, found := strings.Cut(token, "=")
+		if !found || !labelKeyPattern.MatchString(key) {
+			return edit, fmt.Errorf("invalid label %q, expected key=value or -key", token)
+		}
+		edit.Set[key] = value
+	}
+
+	if len(edit.Set) == 0 && len(edit.Remove) == 0 {
+		return edit, errors.New("no label changes given")
+	}
+	return edit, nil
+}
+
+// formatLabels renders labels as sorted key=value pairs
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ApplyLabels applies a label edit to each of the VMs
+func (gcp *GCPService) ApplyLabels(project string, vms []VM, edit LabelEdit) tea.Cmd {
+	return func() tea.Msg {
+		var errs []error
+		for _, vm := range vms {
+			if len(edit.Set) > 0 {
+				if _, err := gcp.runGcloud("compute", "instances", "add-labels", vm.Name,
+					"--project", project,
+					"--zone", vm.ZoneName(),
+					"--labels", formatLabels(edit.Set)); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", vm.Name, err))
+					continue
+				}
+			}
+			if len(edit.Remove) > 0 {
+				if _, err := gcp.runGcloud("compute", "instances", "remove-labels", vm.Name,
+					"--project", project,
+					"--zone", vm.ZoneName(),
+					"--labels", strings.Join(edit.Remove, ",")); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", vm.Name, err))
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			return OperationDoneMsg{Err: fmt.Errorf("failed to update labels: %w", errors.Join(errs...))}
+		}
+		return OperationDoneMsg{Description: fmt.Sprintf("Updated labels on %d instance(s)", len(vms))}
+	}
+}
+
+// startEditLabels prompts for label changes on the target VMs
+func (m model) startEditLabels(node *TreeNode) (tea.Model, tea.Cmd) {
+	targets := m.targetVMs(node)
+
+	prompt := fmt.Sprintf("Labels for %s (key=value to set, -key to remove)", targets[0].Name)
+	if len(targets) > 1 {
+		prompt = fmt.Sprintf("Labels for %d marked instances (key=value to set, -key to remove)", len(targets))
+	}
+
+	return m.askInput(prompt, "", func(m model, value string) (tea.Model, tea.Cmd) {
+		edit, err := parseLabelEdit(value)
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+
+		m.statusMsg = fmt.Sprintf("Updating labels on %d instance(s)...", len(targets))
+		m.marked = nil
+		return m, m.gcpService.ApplyLabels(m.selectedProject, targets, edit)
+	})
+}
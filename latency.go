@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// CONNECTION LATENCY HISTORY
+// =============================================================================
+
+const (
+	// Environment handed to ssh so its LocalCommand can report back to werkroom
+	latencyHostEnv  = "WERKROOM_LATENCY_HOST"
+	latencyStartEnv = "WERKROOM_LATENCY_START"
+
+	// Samples kept per host
+	latencyHistorySize = 20
+)
+
+// SlowConnectThreshold marks hosts whose average setup time warrants a hint
+var SlowConnectThreshold = 5 * time.Second
+
+// LatencySample is a single recorded connection setup
+type LatencySample struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration"`
+}
+
+// LatencyHistory maps host keys to their most recent samples
+type LatencyHistory map[string][]LatencySample
+
+// latencyKey identifies a host across runs
+func latencyKey(project string, vm VM) string {
+	return project + "/" + vm.ZoneName() + "/" + vm.Name
+}
+
+// latencyHistoryPath returns the file holding connection latency history
+func latencyHistoryPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "latency.json"), nil
+}
+
+// loadLatencyHistory reads recorded latencies, returning an empty history on any failure
+func loadLatencyHistory() LatencyHistory {
+	history := LatencyHistory{}
+	path, err := latencyHistoryPath()
+	if err != nil {
+		return history
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &history)
+	}
+	return history
+}
+
+// withLatencyTracking makes ssh report back once the connection is established.
+// ssh runs LocalCommand locally right after authentication succeeds, so the
+// elapsed time since launch covers IAP, key propagation and the handshake.
+func withLatencyTracking(args []string, project string, vm VM) ([]string, []string) {
+	if runtime.GOOS == "windows" {
+		// gcloud connects through PuTTY there, which has no LocalCommand
+		return args, nil
+	}
+	self, err := os.Executable()
+	if err != nil || strings.ContainsAny(self, " \t%") {
+		// gcloud splits --ssh-flag on whitespace and ssh expands % tokens
+		return args, nil
+	}
+
+	tracked := append(append([]string{}, args...),
+		"--ssh-flag=-oPermitLocalCommand=yes",
+		"--ssh-flag=-oLocalCommand="+self)
+	env := []string{
+		latencyHostEnv + "=" + latencyKey(project, vm),
+		latencyStartEnv + "=" + strconv.FormatInt(time.Now().UnixNano(), 10),
+	}
+	return tracked, env
+}
+
+// isLatencyCallback reports whether werkroom was invoked as ssh's LocalCommand
+func isLatencyCallback() bool {
+	return os.Getenv(latencyHostEnv) != "" && os.Getenv(latencyStartEnv) != ""
+}
+
+// recordLatencyCallback stores the sample described by the environment
+func recordLatencyCallback() error {
+	startNanos, err := strconv.ParseInt(os.Getenv(latencyStartEnv), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", latencyStartEnv, err)
+	}
+
+	path, err := latencyHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	history := loadLatencyHistory()
+	key := os.Getenv(latencyHostEnv)
+	samples := append(history[key], LatencySample{
+		At:       time.Now(),
+		Duration: time.Since(time.Unix(0, startNanos)),
+	})
+	if len(samples) > latencyHistorySize {
+		samples = samples[len(samples)-latencyHistorySize:]
+	}
+	history[key] = samples
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// averageLatency returns the mean setup time of the samples
+func averageLatency(samples []LatencySample) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample.Duration
+	}
+	return total / time.Duration(len(samples))
+}
+
+// sparkline renders samples as a row of block characters scaled to the maximum
+func sparkline(samples []LatencySample) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	var maxDuration time.Duration
+	for _, sample := range samples {
+		if sample.Duration > maxDuration {
+			maxDuration = sample.Duration
+		}
+	}
+	if maxDuration == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, sample := range samples {
+		b.WriteRune(blocks[int(sample.Duration*time.Duration(len(blocks)-1)/maxDuration)])
+	}
+	return b.String()
+}
+
+// latencyHint suggests alternatives for a chronically slow host
+func (m model) latencyHint(vm *VM, avg time.Duration) string {
+	if avg < SlowConnectThreshold {
+		return ""
+	}
+	hint := "Slow to connect: try --internal-ip from inside the VPC"
+	if best, bestAvg := m.fasterSibling(vm, avg); best != "" {
+		hint += fmt.Sprintf(", or %s in the same group, which connects faster (avg %s)", best, bestAvg.Round(100*time.Millisecond))
+	}
+	return hint
+}
+
+// fasterSibling returns the VM of the same instance group that connects
+// fastest, if any beats avg; ungrouped VMs have no siblings
+func (m model) fasterSibling(vm *VM, avg time.Duration) (string, time.Duration) {
+	group := vm.GetInstanceGroup()
+	best, bestAvg := "", avg
+	if group == "" {
+		return best, bestAvg
+	}
+	for _, node := range m.treeManager.GetNodes() {
+		for _, candidate := range append([]*TreeNode{node}, node.Children...) {
+			if candidate.VM == nil || candidate.VM.Name == vm.Name || candidate.VM.GetInstanceGroup() != group {
+				continue
+			}
+			samples := m.latency[latencyKey(m.selectedProject, *candidate.VM)]
+			if candAvg := averageLatency(samples); len(samples) > 0 && candAvg < bestAvg {
+				best, bestAvg = candidate.VM.Name, candAvg
+			}
+		}
+	}
+	return best, bestAvg
+}
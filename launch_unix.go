@@ -0,0 +1,22 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// execProcess replaces the current process with path, so the terminal is
+// handed over without werkroom lingering in between
+func execProcess(path string, args, env []string) error {
+	return syscall.Exec(path, args, env)
+}
+
+// defaultShell returns the user's login shell
+func defaultShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
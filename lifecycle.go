@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// START, SUSPEND AND RESUME
+// =============================================================================
+
+// StartVM starts a stopped VM
+func (gcp *GCPService) StartVM(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		_, err := gcp.runGcloud("compute", "instances", "start", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName())
+		if err != nil {
+			err = fmt.Errorf("failed to start %s: %w", vm.Name, err)
+		}
+
+		return OperationDoneMsg{Description: fmt.Sprintf("Started %s", vm.Name), Err: err}
+	}
+}
+
+// canStart reports whether the node is a stopped VM
+func canStart(m model, node *TreeNode) bool {
+	return isInstance(m, node) && VMStatus(node.VM.Status) == StatusTerminated
+}
+
+// startInstance starts the selected stopped VM and notifies once it is RUNNING
+func (m model) startInstance(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	m = m.watchUntilRunning(vm)
+	m.statusMsg = fmt.Sprintf("Starting %s...", vm.Name)
+	return m, m.gcpService.StartVM(m.selectedProject, vm)
+}
+
+// SetSuspended suspends a running VM or resumes a suspended one
+func (gcp *GCPService) SetSuspended(project string, vm VM, suspend bool) tea.Cmd {
+	return func() tea.Msg {
+		verb, done := "resume", "Resumed"
+		if suspend {
+			verb, done = "suspend", "Suspended"
+		}
+
+		_, err := gcp.runGcloud("compute", "instances", verb, vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName())
+		if err != nil {
+			err = fmt.Errorf("failed to %s %s: %w", verb, vm.Name, err)
+		}
+
+		return OperationDoneMsg{Description: fmt.Sprintf("%s %s", done, vm.Name), Err: err}
+	}
+}
+
+// canSuspend reports whether the node is a running VM
+func canSuspend(m model, node *TreeNode) bool {
+	return isInstance(m, node) && VMStatus(node.VM.Status) == StatusRunning
+}
+
+// canResume reports whether the node is a suspended VM
+func canResume(m model, node *TreeNode) bool {
+	return isInstance(m, node) && VMStatus(node.VM.Status) == StatusSuspended
+}
+
+// startSuspend suspends the selected VM after confirmation
+func (m model) startSuspend(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	prompt := fmt.Sprintf("Suspend %s? Memory is kept on disk and compute billing stops.", vm.Name)
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		m.statusMsg = fmt.Sprintf("Suspending %s...", vm.Name)
+		return m, m.gcpService.SetSuspended(m.selectedProject, vm, true)
+	})
+}
+
+// startResume resumes the selected suspended VM
+func (m model) startResume(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	m = m.watchUntilRunning(vm)
+	m.statusMsg = fmt.Sprintf("Resuming %s...", vm.Name)
+	return m, m.gcpService.SetSuspended(m.selectedProject, vm, false)
+}
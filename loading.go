@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// LOADING SCREENS
+// =============================================================================
+
+// Longest command shown under the spinner before it is cut
+const maxActivityWidth = 100
+
+// isLoading reports whether a full-screen loading view is shown
+func (m model) isLoading() bool {
+	return m.state == StateCheckingAuth || m.state == StateLoadingProjects || m.state == StateLoadingVMs
+}
+
+// startSpinner resets the elapsed time and starts animating; a spinner that is
+// already running drops its stale ticks, so calling this twice is harmless
+func (m *model) startSpinner() tea.Cmd {
+	m.loadingSince = time.Now()
+	m.loadingNote = ""
+	m.gcpService.setActivity("")
+	return m.spinner.Tick
+}
+
+// handleSpinnerTick advances the animation while a loading view is shown
+func (m model) handleSpinnerTick(msg spinner.TickMsg) (tea.Model, tea.Cmd) {
+	if !m.isLoading() {
+		// Stops the tick loop until the next load
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// renderLoading shows what is loading, for how long, and the call in flight
+func (m model) renderLoading(what string) string {
+	elapsed := time.Since(m.loadingSince).Truncate(time.Second)
+	view := fmt.Sprintf("\n  %s %s %s\n", m.spinner.View(), what, m.styles.Label.Render(elapsed.String()))
+
+	if activity := m.gcpService.Activity(); activity != "" {
+		if len(activity) > maxActivityWidth {
+			activity = activity[:maxActivityWidth] + "…"
+		}
+		view += "  " + m.styles.Label.Render(activity) + "\n"
+	}
+	if m.loadingNote != "" {
+		view += "  " + m.loadingNote + "\n"
+	}
+	if m.state != StateCheckingAuth {
+		view += "\n" + m.styles.Label.Render("  Press Esc to cancel") + "\n"
+	}
+	return view + "\n"
+}
+
+// cancelLoad aborts the calls behind a loading screen and returns to where
+// the user came from
+func (m model) cancelLoad() (tea.Model, tea.Cmd) {
+	m.gcpService.CancelLoad()
+	debugf("loading cancelled in state %d", m.state)
+
+	if m.state == StateLoadingProjects {
+		// Nothing to go back to; offer to start over instead
+		m.err = errors.New("loading GCP projects was cancelled")
+		m.retry = withRetry("GCP Projects", m.gcpService.forLoad().LoadProjects())
+		return m, nil
+	}
+	if m.onSource() {
+		m.err = fmt.Errorf("loading %s was cancelled", resourceType(m.resourceKind).Plural)
+		m.retry = m.loadCmd()
+		return m, nil
+	}
+
+	if len(m.projects) > 0 {
+		next, cmd := m.goBackToProjectSelection()
+		back := next.(model)
+		back.statusMsg = "Loading cancelled"
+		return back, cmd
+	}
+	// Started with -project, so the project list was never loaded
+	m.selectedProject = ""
+	return m.startLoading()
+}
+
+// expectsListing reports whether loaded VMs or resources still belong on screen;
+// results of a cancelled load arrive after the user has moved on
+func (m model) expectsListing() bool {
+	return m.state == StateLoadingVMs || m.state == StateSelectingVM
+}
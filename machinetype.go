@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CHANGE MACHINE TYPE
+// =============================================================================
+
+// MachineType is a machine type offered in a zone
+type MachineType struct {
+	Name      string `json:"name"`
+	GuestCPUs int    `json:"guestCpus"`
+	MemoryMB  int    `json:"memoryMb"`
+}
+
+// Family returns the machine family, e.g. "n2" for n2-standard-4
+func (t MachineType) Family() string {
+	family, _, _ := strings.Cut(t.Name, "-")
+	return family
+}
+
+// Option renders the machine type as a picker option
+func (t MachineType) Option() string {
+	option := fmt.Sprintf("%-22s %3d vCPU %7.1f GB", t.Name, t.GuestCPUs, float64(t.MemoryMB)/1024)
+	if cost, ok := estimateMonthlyCost(t.Name); ok {
+		option += "  " + formatCost(cost)
+	}
+	return option
+}
+
+// MachineTypesLoadedMsg carries the machine types available for a VM
+type MachineTypesLoadedMsg struct {
+	VM    VM
+	Types []MachineType
+	Err   error
+}
+
+// canChangeMachineType reports whether the node is a stopped instance
+func canChangeMachineType(m model, node *TreeNode) bool {
+	return isInstance(m, node) && VMStatus(node.VM.Status) == StatusTerminated && !m.gcpService.native
+}
+
+// startChangeMachineType loads the machine types of the instance's zone
+func (m model) startChangeMachineType(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Loading machine types in %s...", node.VM.ZoneName())
+	return m, m.gcpService.ListMachineTypes(m.selectedProject, *node.VM)
+}
+
+// ListMachineTypes lists the machine types that aren't deprecated in the VM's zone
+func (gcp *GCPService) ListMachineTypes(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "machine-types", "list",
+			"--project", project,
+			"--zones", vm.ZoneName(),
+			"--filter", "-deprecated.state:*",
+			"--format", "json(name,guestCpus,memoryMb)")
+		if err != nil {
+			return MachineTypesLoadedMsg{VM: vm, Err: fmt.Errorf("failed to list machine types: %w", err)}
+		}
+		var types []MachineType
+		if err := json.Unmarshal(output, &types); err != nil {
+			return MachineTypesLoadedMsg{VM: vm, Err: fmt.Errorf("failed to parse machine types: %w", err)}
+		}
+		sort.Slice(types, func(i, j int) bool {
+			if types[i].GuestCPUs != types[j].GuestCPUs {
+				return types[i].GuestCPUs < types[j].GuestCPUs
+			}
+			if types[i].MemoryMB != types[j].MemoryMB {
+				return types[i].MemoryMB < types[j].MemoryMB
+			}
+			return types[i].Name < types[j].Name
+		})
+		return MachineTypesLoadedMsg{VM: vm, Types: types}
+	}
+}
+
+// handleMachineTypesLoaded asks for the machine family, the current one first
+func (m model) handleMachineTypesLoaded(msg MachineTypesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	m.statusMsg = ""
+	vm := msg.VM
+	current, _, _ := strings.Cut(vm.MachineTypeName(), "-")
+
+	byFamily := make(map[string][]MachineType)
+	families := []string{}
+	for _, t := range msg.Types {
+		if _, seen := byFamily[t.Family()]; !seen {
+			families = append(families, t.Family())
+		}
+		byFamily[t.Family()] = append(byFamily[t.Family()], t)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		if (families[i] == current) != (families[j] == current) {
+			return families[i] == current
+		}
+		return families[i] < families[j]
+	})
+	if len(families) == 0 {
+		m.statusMsg = fmt.Sprintf("No machine types found in %s", vm.ZoneName())
+		return m, nil
+	}
+
+	prompt := fmt.Sprintf("Machine family for %s (now %s)", vm.Name, vm.MachineTypeName())
+	return m.askPick(prompt, families, func(m model, family string) (tea.Model, tea.Cmd) {
+		types := byFamily[family]
+		options := make([]string, len(types))
+		for i, t := range types {
+			options[i] = t.Option()
+		}
+		return m.askPick(fmt.Sprintf("Machine type for %s (now %s)", vm.Name, vm.MachineTypeName()), options, func(m model, option string) (tea.Model, tea.Cmd) {
+			name, _, _ := strings.Cut(option, " ")
+			if name == vm.MachineTypeName() {
+				m.statusMsg = fmt.Sprintf("%s already is %s", vm.Name, name)
+				return m, nil
+			}
+			m.statusMsg = fmt.Sprintf("Changing %s to %s...", vm.Name, name)
+			return m, m.gcpService.SetMachineType(m.selectedProject, vm, name)
+		})
+	})
+}
+
+// SetMachineType changes the machine type of a stopped VM
+func (gcp *GCPService) SetMachineType(project string, vm VM, machineType string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := gcp.runGcloud("compute", "instances", "set-machine-type", vm.Name,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			"--machine-type", machineType)
+		if err != nil {
+			return OperationDoneMsg{Err: fmt.Errorf("failed to change machine type of %s: %w", vm.Name, err)}
+		}
+		return OperationDoneMsg{Description: fmt.Sprintf("Changed %s to %s", vm.Name, machineType)}
+	}
+}
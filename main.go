@@ -1,20 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
-	"syscall"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/oauth2"
 )
 
 // =============================================================================
@@ -27,6 +36,9 @@ var (
 	DefaultHeight = 14
 	MinHeight     = 5
 	UIOverhead    = 7 // title, margins, help text
+
+	// Share of the window width kept by the list when the details pane is open
+	DetailsSplitPercent = 55
 )
 
 // =============================================================================
@@ -42,15 +54,30 @@ type Styles struct {
 	QuitText     lipgloss.Style
 	NoItems      lipgloss.Style
 	Filter       lipgloss.Style
+	Details      lipgloss.Style
+	Label        lipgloss.Style
+	Prompt       lipgloss.Style
+	StatusLine   lipgloss.Style
+
+	// Badges
+	Protected      lipgloss.Style
+	LastSession    lipgloss.Style
+	Cost           lipgloss.Style
+	Capability     lipgloss.Style
+	Recommendation lipgloss.Style
+	Production     lipgloss.Style
+	Finding        lipgloss.Style
 
 	// Status colors
 	Running      lipgloss.Style
 	Terminated   lipgloss.Style
 	Provisioning lipgloss.Style
 	Stopping     lipgloss.Style
+	Suspended    lipgloss.Style
 
 	// Tree styles
 	Group     lipgloss.Style
+	GKEGroup  lipgloss.Style
 	Expanded  lipgloss.Style
 	Collapsed lipgloss.Style
 }
@@ -65,35 +92,118 @@ func NewStyles() Styles {
 		QuitText:     lipgloss.NewStyle().Margin(1, 0, 2, 4),
 		NoItems:      lipgloss.NewStyle().MarginLeft(2).PaddingLeft(4),
 		Filter:       lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		Details:      lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).Padding(0, 1).MarginLeft(2),
+		Label:        lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
+		Prompt:       lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true),
+		StatusLine:   lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+
+		Protected:      lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		LastSession:    lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
+		Cost:           lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
+		Capability:     lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+		Recommendation: lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Italic(true),
+		Production:     lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true),
+		Finding:        lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
 
 		Running:      lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
 		Terminated:   lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
 		Provisioning: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
 		Stopping:     lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+		Suspended:    lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
 
 		Group:     lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
+		GKEGroup:  lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Italic(true),
 		Expanded:  lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
 		Collapsed: lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
 	}
 }
 
+// Density controls how tightly list rows are rendered
+type Density int
+
+const (
+	DensityNormal Density = iota
+	DensityCompact
+	DensityComfortable
+)
+
+// String returns the display name of the density
+func (d Density) String() string {
+	switch d {
+	case DensityCompact:
+		return "compact"
+	case DensityComfortable:
+		return "comfortable"
+	default:
+		return "normal"
+	}
+}
+
+// Next returns the density that follows in the toggle cycle
+func (d Density) Next() Density {
+	return (d + 1) % 3
+}
+
 // =============================================================================
 // DOMAIN MODELS
 // =============================================================================
 
 // Project represents a GCP project
 type Project struct {
-	ProjectID string `json:"projectId"`
-	Name      string `json:"name"`
-	Status    string `json:"lifecycleState"`
+	ProjectID string        `json:"projectId"`
+	Name      string        `json:"name"`
+	Status    string        `json:"lifecycleState"`
+	Parent    ProjectParent `json:"parent"`
 }
 
 // VM represents a GCP VM instance
 type VM struct {
-	Name     string    `json:"name"`
-	Zone     string    `json:"zone"`
-	Status   string    `json:"status"`
-	Metadata *Metadata `json:"metadata,omitempty"`
+	ID                 string            `json:"id,omitempty"`
+	Name               string            `json:"name"`
+	Zone               string            `json:"zone"`
+	Status             string            `json:"status"`
+	MachineType        string            `json:"machineType"`
+	CreationTimestamp  string            `json:"creationTimestamp,omitempty"`
+	LastStartTimestamp string            `json:"lastStartTimestamp,omitempty"`
+	DeletionProtection bool              `json:"deletionProtection"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	Tags               Tags              `json:"tags,omitempty"`
+	Scheduling         Scheduling        `json:"scheduling,omitempty"`
+	GuestAccelerators  []Accelerator     `json:"guestAccelerators,omitempty"`
+	Confidential       ConfidentialSpec  `json:"confidentialInstanceConfig,omitempty"`
+	Metadata           *Metadata         `json:"metadata,omitempty"`
+	Disks              []Disk            `json:"disks,omitempty"`
+
+	NetworkInterfaces []NetworkInterface `json:"networkInterfaces,omitempty"`
+	ServiceAccounts   []ServiceAccount   `json:"serviceAccounts,omitempty"`
+}
+
+// NetworkInterface represents a VM network interface
+type NetworkInterface struct {
+	Network       string         `json:"network"`
+	NetworkIP     string         `json:"networkIP"`
+	AccessConfigs []AccessConfig `json:"accessConfigs,omitempty"`
+}
+
+// Tags holds the network tags firewall rules target
+type Tags struct {
+	Items []string `json:"items,omitempty"`
+}
+
+// AccessConfig represents the external NAT configuration of an interface
+type AccessConfig struct {
+	NatIP string `json:"natIP"`
+}
+
+// Disk represents a disk attached to a VM
+type Disk struct {
+	DeviceName string   `json:"deviceName,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	Boot       bool     `json:"boot,omitempty"`
+	DiskSizeGB string   `json:"diskSizeGb,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Mode       string   `json:"mode,omitempty"`
+	Licenses   []string `json:"licenses,omitempty"`
 }
 
 // Metadata represents VM metadata
@@ -115,6 +225,8 @@ const (
 	StatusTerminated   VMStatus = "TERMINATED"
 	StatusProvisioning VMStatus = "PROVISIONING"
 	StatusStopping     VMStatus = "STOPPING"
+	StatusSuspending   VMStatus = "SUSPENDING"
+	StatusSuspended    VMStatus = "SUSPENDED"
 )
 
 // GetAbbreviation returns single-letter status abbreviation
@@ -128,6 +240,8 @@ func (s VMStatus) GetAbbreviation() string {
 		return "P"
 	case StatusStopping:
 		return "S"
+	case StatusSuspending, StatusSuspended:
+		return "Z"
 	default:
 		return "?"
 	}
@@ -144,11 +258,68 @@ func (s VMStatus) GetStyle(styles Styles) lipgloss.Style {
 		return styles.Provisioning
 	case StatusStopping:
 		return styles.Stopping
+	case StatusSuspending, StatusSuspended:
+		return styles.Suspended
 	default:
 		return styles.Item
 	}
 }
 
+// ZoneName returns the short zone name from the zone URL
+func (vm VM) ZoneName() string {
+	zoneParts := strings.Split(vm.Zone, "/")
+	return zoneParts[len(zoneParts)-1]
+}
+
+// InternalIP returns the primary internal IP address, or "" if there is none
+func (vm VM) InternalIP() string {
+	if len(vm.NetworkInterfaces) == 0 {
+		return ""
+	}
+	return vm.NetworkInterfaces[0].NetworkIP
+}
+
+// ExternalIP returns the first external IP address, or "" if there is none
+func (vm VM) ExternalIP() string {
+	for _, iface := range vm.NetworkInterfaces {
+		for _, access := range iface.AccessConfigs {
+			if access.NatIP != "" {
+				return access.NatIP
+			}
+		}
+	}
+	return ""
+}
+
+// GetMetadata returns the value of a metadata key, or "" if it is not set
+func (vm VM) GetMetadata(key string) string {
+	if vm.Metadata == nil {
+		return ""
+	}
+	for _, item := range vm.Metadata.Items {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return ""
+}
+
+// GKENodePool returns the cluster and node pool of a GKE node VM
+func (vm VM) GKENodePool() (cluster, pool string, ok bool) {
+	cluster = vm.GetMetadata("cluster-name")
+	if cluster == "" {
+		return "", "", false
+	}
+
+	// kube-labels is a comma-separated list of node labels set by GKE
+	for _, label := range strings.Split(vm.GetMetadata("kube-labels"), ",") {
+		if value, found := strings.CutPrefix(label, "cloud.google.com/gke-nodepool="); found {
+			return cluster, value, true
+		}
+	}
+	return cluster, "default", true
+}
+
 // GetInstanceGroup extracts instance group from VM metadata
 func (vm VM) GetInstanceGroup() string {
 	if vm.Metadata == nil || vm.Metadata.Items == nil {
@@ -178,6 +349,7 @@ type NodeType int
 const (
 	GroupNode NodeType = iota
 	InstanceNode
+	ResourceNode
 )
 
 // TreeNode represents a node in the tree structure
@@ -185,16 +357,58 @@ type TreeNode struct {
 	Type       NodeType
 	Name       string
 	VM         *VM
+	Resource   *Resource
 	GroupName  string
 	IsExpanded bool
+	IsGKE      bool
 	Children   []*TreeNode
 	Depth      int
+
+	// Lowercased name, computed on first match
+	searchKey string
+}
+
+// matches reports whether the node name contains the lowercased filter
+func (n *TreeNode) matches(filterLower string) bool {
+	if n.searchKey == "" {
+		n.searchKey = strings.ToLower(n.Name)
+	}
+	return strings.Contains(n.searchKey, filterLower)
 }
 
 // TreeManager handles tree operations
 type TreeManager struct {
-	nodes  []*TreeNode
-	styles Styles
+	nodes   []*TreeNode
+	vms     []VM
+	styles  Styles
+	density Density
+
+	// GKE node VMs are grouped by cluster/node pool unless hidden
+	hideGKENodes bool
+
+	// Stopped instances are left out, along with groups that only had those
+	hideTerminated bool
+
+	// VMs are grouped by zone instead of by instance group, from group_by
+	groupByZone bool
+
+	// Names that are never shown, from the config exclude list
+	exclude namePatterns
+
+	// Estimated monthly cost is appended to rows when enabled
+	showCost bool
+
+	// Order of instances within groups and at the top level
+	sortOrder SortOrder
+
+	// MIG health by group name, fetched when a group is expanded
+	groupHealth map[string]GroupHealth
+
+	// Rendered instance and resource rows, reused until the tree or display options change
+	rows map[*TreeNode]cachedRow
+
+	// Bumped on every rebuild so cached filter results can be discarded
+	generation int
 }
 
 // NewTreeManager creates a new tree manager
@@ -206,13 +420,39 @@ func NewTreeManager(styles Styles) *TreeManager {
 
 // BuildFromVMs creates tree structure from VM list
 func (tm *TreeManager) BuildFromVMs(vms []VM) {
+	// Remember expanded groups so reloads keep the tree shape
+	expanded := make(map[string]bool)
+	for _, node := range tm.nodes {
+		if node.Type == GroupNode && node.IsExpanded {
+			expanded[node.Name] = true
+		}
+	}
+
+	tm.vms = vms
 	groups := make(map[string][]*VM)
+	gkeGroups := make(map[string]bool)
 	var ungrouped []*VM
 
-	// Group VMs by instance group
+	// Group VMs by instance group, GKE nodes by cluster and node pool, or all of them by zone
 	for i := range vms {
 		vm := &vms[i]
-		if groupName := vm.GetInstanceGroup(); groupName != "" {
+		if tm.hideTerminated && VMStatus(vm.Status) == StatusTerminated {
+			continue
+		}
+		if tm.exclude.Match(vm.Name) {
+			continue
+		}
+		cluster, pool, isGKE := vm.GKENodePool()
+		if isGKE && tm.hideGKENodes {
+			continue
+		}
+		if tm.groupByZone {
+			groups[vm.ZoneName()] = append(groups[vm.ZoneName()], vm)
+		} else if isGKE {
+			groupName := cluster + "/" + pool
+			groups[groupName] = append(groups[groupName], vm)
+			gkeGroups[groupName] = true
+		} else if groupName := vm.GetInstanceGroup(); groupName != "" {
 			groups[groupName] = append(groups[groupName], vm)
 		} else {
 			ungrouped = append(ungrouped, vm)
@@ -225,7 +465,9 @@ func (tm *TreeManager) BuildFromVMs(vms []VM) {
 	var groupNames []string
 	for groupName := range groups {
 		groupNames = append(groupNames, groupName)
+		sortVMs(groups[groupName], tm.sortOrder)
 	}
+	sortVMs(ungrouped, tm.sortOrder)
 	sort.Strings(groupNames)
 
 	for _, groupName := range groupNames {
@@ -233,7 +475,8 @@ func (tm *TreeManager) BuildFromVMs(vms []VM) {
 			Type:       GroupNode,
 			Name:       groupName,
 			GroupName:  groupName,
-			IsExpanded: false,
+			IsExpanded: expanded[groupName],
+			IsGKE:      gkeGroups[groupName],
 			Depth:      0,
 			Children:   make([]*TreeNode, 0),
 		}
@@ -264,7 +507,19 @@ func (tm *TreeManager) BuildFromVMs(vms []VM) {
 		nodes = append(nodes, instanceNode)
 	}
 
+	tm.setNodes(nodes)
+}
+
+// setNodes replaces the tree and drops everything derived from the old one
+func (tm *TreeManager) setNodes(nodes []*TreeNode) {
 	tm.nodes = nodes
+	tm.generation++
+	tm.invalidateRows()
+}
+
+// invalidateRows drops cached rows after a display option or instance changed
+func (tm *TreeManager) invalidateRows() {
+	tm.rows = nil
 }
 
 // GetNodes returns all tree nodes
@@ -272,11 +527,42 @@ func (tm *TreeManager) GetNodes() []*TreeNode {
 	return tm.nodes
 }
 
-// FlattenForDisplay converts tree to flat list for UI
-func (tm *TreeManager) FlattenForDisplay() []*TreeNode {
-	var result []*TreeNode
+// SetHideGKENodes toggles GKE node visibility and rebuilds the tree
+func (tm *TreeManager) SetHideGKENodes(hide bool) {
+	tm.hideGKENodes = hide
+	if tm.vms != nil {
+		tm.BuildFromVMs(tm.vms)
+	}
+}
+
+// SetHideTerminated toggles visibility of stopped instances and rebuilds the tree
+func (tm *TreeManager) SetHideTerminated(hide bool) {
+	tm.hideTerminated = hide
+	if tm.vms != nil {
+		tm.BuildFromVMs(tm.vms)
+	}
+}
 
+// FindVM returns the VM with the given name, or nil if it is not in the tree
+func (tm *TreeManager) FindVM(name string) *VM {
 	for _, node := range tm.nodes {
+		if node.Type == InstanceNode && node.VM.Name == name {
+			return node.VM
+		}
+		for _, child := range node.Children {
+			if child.VM != nil && child.VM.Name == name {
+				return child.VM
+			}
+		}
+	}
+	return nil
+}
+
+// FlattenForDisplay converts the given top-level nodes to a flat list for UI
+func (tm *TreeManager) FlattenForDisplay(nodes []*TreeNode) []*TreeNode {
+	result := make([]*TreeNode, 0, len(nodes))
+
+	for _, node := range nodes {
 		result = append(result, node)
 		if node.Type == GroupNode && node.IsExpanded {
 			result = append(result, node.Children...)
@@ -300,9 +586,82 @@ func (tm *TreeManager) ToggleNode(targetNode *TreeNode) {
 	}
 }
 
-// RenderNode returns formatted string for a tree node
+// statusBadge renders the status abbreviation, bracketed unless compact
+func (tm *TreeManager) statusBadge(status VMStatus) string {
+	badge := status.GetAbbreviation()
+	if tm.density != DensityCompact {
+		badge = "[" + badge + "]"
+	}
+	return status.GetStyle(tm.styles).Render(badge)
+}
+
+// groupStatus counts a group's running children, among those that report a status
+func groupStatus(node *TreeNode) (running, total int) {
+	for _, child := range node.Children {
+		var status string
+		switch {
+		case child.VM != nil:
+			status = child.VM.Status
+		case child.Resource != nil:
+			status = child.Resource.Status
+		}
+		if status == "" {
+			continue
+		}
+		total++
+		if VMStatus(status) == StatusRunning {
+			running++
+		}
+	}
+	return running, total
+}
+
+// groupStatusStyle colors a group summary: all running, some running, none running
+func (tm *TreeManager) groupStatusStyle(running, total int) lipgloss.Style {
+	switch running {
+	case total:
+		return tm.styles.Running
+	case 0:
+		return tm.styles.Terminated
+	default:
+		return tm.styles.Provisioning
+	}
+}
+
+// cachedRow is a rendered leaf row with the age badge it was rendered with
+type cachedRow struct {
+	Row string
+	Age string
+}
+
+// RenderNode returns formatted string for a tree node. Group rows are cheap
+// and depend on expansion and health, so only leaf rows are cached; an
+// instance row is rendered again once its age badge moves on.
 func (tm *TreeManager) RenderNode(node *TreeNode) string {
+	if node.Type == GroupNode {
+		return tm.renderNode(node)
+	}
+	age := ""
+	if node.VM != nil {
+		age = tm.ageBadge(node.VM)
+	}
+	if cached, ok := tm.rows[node]; ok && cached.Age == age {
+		return cached.Row
+	}
+	if tm.rows == nil {
+		tm.rows = make(map[*TreeNode]cachedRow, len(tm.vms))
+	}
+	row := tm.renderNode(node)
+	tm.rows[node] = cachedRow{Row: row, Age: age}
+	return row
+}
+
+// renderNode formats a tree node without consulting the cache
+func (tm *TreeManager) renderNode(node *TreeNode) string {
 	indent := strings.Repeat("  ", node.Depth)
+	if tm.density == DensityCompact {
+		indent = strings.Repeat(" ", node.Depth)
+	}
 
 	if node.Type == GroupNode {
 		icon := "▶"
@@ -311,18 +670,66 @@ func (tm *TreeManager) RenderNode(node *TreeNode) string {
 			icon = "▼"
 			style = tm.styles.Expanded
 		}
-		return fmt.Sprintf("%s%s %s (%d instances)",
+		groupStyle := tm.styles.Group
+		if node.IsGKE {
+			groupStyle = tm.styles.GKEGroup
+		}
+		// Children without a status, e.g. ssh_config hosts, are only counted
+		summary := fmt.Sprintf("(%d instances)", len(node.Children))
+		if tm.density == DensityCompact {
+			summary = fmt.Sprintf("(%d)", len(node.Children))
+		}
+		if running, total := groupStatus(node); total > 0 {
+			summary = fmt.Sprintf("(%d/%d running)", running, total)
+			if tm.density == DensityCompact {
+				summary = fmt.Sprintf("(%d/%d)", running, total)
+			}
+			summary = tm.groupStatusStyle(running, total).Render(summary)
+		}
+		line := fmt.Sprintf("%s%s %s %s",
 			indent,
 			style.Render(icon),
-			tm.styles.Group.Render(node.Name),
-			len(node.Children))
+			groupStyle.Render(node.Name),
+			summary)
+		if health, ok := tm.groupHealth[node.Name]; ok && !node.IsGKE {
+			line += " " + tm.styles.Label.Render(health.Summary())
+		}
+		if tm.showCost {
+			if total, ok := groupCost(node); ok {
+				line += " " + tm.styles.Cost.Render(formatCost(total))
+			} else if total > 0 {
+				line += " " + tm.styles.Cost.Render(formatCost(total)+"+")
+			}
+		}
+		return line
+	}
+
+	if node.Type == ResourceNode {
+		if node.Resource.Status == "" {
+			// Inventories like ssh_config know nothing about the host's state
+			return indent + node.Name
+		}
+		return fmt.Sprintf("%s%s %s", indent, tm.statusBadge(VMStatus(node.Resource.Status)), node.Name)
 	}
 
 	// Instance node
 	status := VMStatus(node.VM.Status)
-	statusStyle := status.GetStyle(tm.styles)
-	coloredStatus := statusStyle.Render("[" + status.GetAbbreviation() + "]")
-	return fmt.Sprintf("%s%s %s", indent, coloredStatus, node.Name)
+	line := fmt.Sprintf("%s%s %s", indent, tm.statusBadge(status), node.Name)
+	if node.VM.DeletionProtection {
+		line += " " + tm.styles.Protected.Render("[protected]")
+	}
+	for _, badge := range node.VM.CapabilityBadges() {
+		line += " " + tm.styles.Capability.Render("["+badge+"]")
+	}
+	if age := tm.ageBadge(node.VM); age != "" {
+		line += " " + tm.styles.Cost.Render(age)
+	}
+	if tm.showCost {
+		if cost, ok := node.VM.MonthlyCost(); ok {
+			line += " " + tm.styles.Cost.Render(formatCost(cost))
+		}
+	}
+	return line
 }
 
 // =============================================================================
@@ -332,6 +739,12 @@ func (tm *TreeManager) RenderNode(node *TreeNode) string {
 // FilterService handles tree filtering
 type FilterService struct {
 	treeManager *TreeManager
+
+	// Previous filter and its result; typing more characters only narrows
+	// the match, so the next filter can start from this instead of the tree
+	lastFilter     string
+	lastResult     []*TreeNode
+	lastGeneration int
 }
 
 // NewFilterService creates a new filter service
@@ -347,18 +760,31 @@ func (fs *FilterService) Filter(nodes []*TreeNode, filterText string) []*TreeNod
 		return nodes
 	}
 
-	var filtered []*TreeNode
 	filterLower := strings.ToLower(filterText)
+	if fs.lastFilter != "" && fs.lastGeneration == fs.treeManager.generation &&
+		strings.Contains(filterLower, fs.lastFilter) {
+		nodes = fs.lastResult
+	}
+
+	filtered := fs.filter(nodes, filterLower)
+	fs.lastFilter, fs.lastResult, fs.lastGeneration = filterLower, filtered, fs.treeManager.generation
+	return filtered
+}
+
+// filter matches nodes against an already lowercased filter
+func (fs *FilterService) filter(nodes []*TreeNode, filterLower string) []*TreeNode {
+	var filtered []*TreeNode
 
 	for _, node := range nodes {
 		if node.Type == GroupNode {
-			if strings.Contains(strings.ToLower(node.Name), filterLower) {
+			if node.matches(filterLower) {
 				// Group name matches - include entire group
 				filteredGroup := &TreeNode{
 					Type:       GroupNode,
 					Name:       node.Name,
 					GroupName:  node.GroupName,
 					IsExpanded: true, // Auto-expand
+					IsGKE:      node.IsGKE,
 					Children:   node.Children,
 					Depth:      node.Depth,
 				}
@@ -369,7 +795,7 @@ func (fs *FilterService) Filter(nodes []*TreeNode, filterText string) []*TreeNod
 			// Check for matching children (by name only)
 			var matchingChildren []*TreeNode
 			for _, child := range node.Children {
-				if strings.Contains(strings.ToLower(child.Name), filterLower) {
+				if child.matches(filterLower) {
 					matchingChildren = append(matchingChildren, child)
 				}
 			}
@@ -380,6 +806,7 @@ func (fs *FilterService) Filter(nodes []*TreeNode, filterText string) []*TreeNod
 					Name:       node.Name,
 					GroupName:  node.GroupName,
 					IsExpanded: true, // Auto-expand
+					IsGKE:      node.IsGKE,
 					Children:   matchingChildren,
 					Depth:      node.Depth,
 				}
@@ -387,7 +814,7 @@ func (fs *FilterService) Filter(nodes []*TreeNode, filterText string) []*TreeNod
 			}
 		} else {
 			// Instance node - search name only
-			if strings.Contains(strings.ToLower(node.Name), filterLower) {
+			if node.matches(filterLower) {
 				filtered = append(filtered, node)
 			}
 		}
@@ -401,27 +828,184 @@ func (fs *FilterService) Filter(nodes []*TreeNode, filterText string) []*TreeNod
 // =============================================================================
 
 // GCPService handles GCP operations
-type GCPService struct{}
+type GCPService struct {
+	*gcpState
+	// Context of the load this service was handed to by forLoad, if any
+	load context.Context
+}
+
+// gcpState is shared by a GCPService and the per-load services made from it
+type gcpState struct {
+	// Upper bound for a single non-interactive gcloud call
+	timeout time.Duration
+
+	// Access token for direct API calls, shared by concurrent requests
+	tokenMu      sync.Mutex
+	token        string
+	tokenFetched time.Time
+
+	// Application Default Credentials and built-in SSH stand in for gcloud
+	native      bool
+	tokenSource oauth2.TokenSource
+
+	// Quota project sent with API calls, from -billing-project
+	billingProject string
+
+	// Server-side filter for VM listings, from -filter
+	vmFilter string
+	// Zones or regions VM listings are limited to, from -zones
+	zoneScope []string
+	// User and flag overrides for SSH sessions, from ssh_users
+	sshUsers []SSHUserRule
+	// Host key checking of the built-in SSH client
+	hostKeys HostKeyConfig
+	// Jump hosts for SSH sessions, with inventory bastions resolved
+	bastions []BastionRule
+	// SSH through IAP even to instances with an external IP, from iap
+	forceIAP bool
+	// Flags added to every gcloud compute ssh, from ssh_flags
+	sshFlags []string
+	// Session recording; recordTo is set for the session about to be launched
+	recording RecordingConfig
+	recordTo  string
+
+	// Most recently started call, shown on loading screens
+	activityMu sync.Mutex
+	activity   string
+
+	// Cancels the load behind the loading screen
+	loadMu     sync.Mutex
+	cancelLoad context.CancelFunc
+}
+
+// baseContext returns the context new calls are made under
+func (gcp *GCPService) baseContext() context.Context {
+	if gcp.load != nil {
+		return gcp.load
+	}
+	return context.Background()
+}
+
+// forLoad returns a service whose calls CancelLoad aborts, for the load
+// behind a loading screen; other calls are never affected
+func (gcp *GCPService) forLoad() *GCPService {
+	ctx, cancel := context.WithCancel(context.Background())
+	gcp.loadMu.Lock()
+	defer gcp.loadMu.Unlock()
+	gcp.cancelLoad = cancel
+	return &GCPService{gcpState: gcp.gcpState, load: ctx}
+}
+
+// CancelLoad aborts the calls of the most recent load from forLoad
+func (gcp *GCPService) CancelLoad() {
+	gcp.loadMu.Lock()
+	defer gcp.loadMu.Unlock()
+	if gcp.cancelLoad != nil {
+		gcp.cancelLoad()
+		gcp.cancelLoad = nil
+	}
+}
+
+// setActivity records the call about to be made
+func (gcp *GCPService) setActivity(activity string) {
+	gcp.activityMu.Lock()
+	defer gcp.activityMu.Unlock()
+	gcp.activity = activity
+}
+
+// Activity returns the most recently started call
+func (gcp *GCPService) Activity() string {
+	gcp.activityMu.Lock()
+	defer gcp.activityMu.Unlock()
+	return gcp.activity
+}
+
+// Used when neither -timeout nor the config sets one
+const defaultGcloudTimeout = 2 * time.Minute
 
 // NewGCPService creates a new GCP service
-func NewGCPService() *GCPService {
-	return &GCPService{}
+func NewGCPService(timeout time.Duration) *GCPService {
+	if timeout <= 0 {
+		timeout = defaultGcloudTimeout
+	}
+	return &GCPService{gcpState: &gcpState{timeout: timeout}}
+}
+
+// runGcloud executes gcloud and returns its stdout, folding stderr into the error
+func (gcp *GCPService) runGcloud(args ...string) ([]byte, error) {
+	return gcp.runGcloudWithEnv(nil, args...)
+}
+
+// runGcloudWithEnv executes gcloud with extra environment variables
+func (gcp *GCPService) runGcloudWithEnv(env []string, args ...string) (output []byte, err error) {
+	if gcp.native {
+		return nil, fmt.Errorf("gcloud %s: %w", gcloudCommandName(args), errNoGcloud)
+	}
+	started := time.Now()
+	defer func() {
+		debugf("gcloud %s: %d bytes in %s, err=%v", strings.Join(args, " "), len(output), time.Since(started).Round(time.Millisecond), err)
+		auditCommand("gcloud", "", append([]string{"gcloud"}, args...), started, err)
+	}()
+
+	gcp.setActivity("gcloud " + strings.Join(args, " "))
+	ctx, cancel := context.WithTimeout(gcp.baseContext(), gcp.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gcloud", args...)
+	// Prompts can't be answered from inside the TUI; fail instead of waiting on one
+	cmd.Env = append(os.Environ(), "CLOUDSDK_CORE_DISABLE_PROMPTS=1")
+	cmd.Env = append(cmd.Env, env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err = cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("gcloud %s %w after %s (check your network, proxy or credentials)", gcloudCommandName(args), errCommandTimeout, gcp.timeout)
+	}
+	if ctx.Err() == context.Canceled {
+		return nil, fmt.Errorf("gcloud %s: %w", gcloudCommandName(args), context.Canceled)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// gcloudCommandName returns the command words of a gcloud invocation, without flags
+func gcloudCommandName(args []string) string {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
 }
 
 // LoadProjects loads available GCP projects
 func (gcp *GCPService) LoadProjects() tea.Cmd {
 	return func() tea.Msg {
-		cmd := exec.Command("gcloud", "projects", "list",
-			"--format", "json(projectId,name,lifecycleState)")
+		if gcp.native {
+			projects, err := gcp.listProjectsAPI()
+			if err != nil {
+				return ErrorMsg{Err: fmt.Errorf("failed to list projects: %w", err)}
+			}
+			return ProjectsLoadedMsg{projects}
+		}
 
-		output, err := cmd.Output()
+		output, err := gcp.runGcloud("projects", "list",
+			"--format", "json(projectId,name,lifecycleState,parent)")
 		if err != nil {
-			return ErrorMsg{fmt.Errorf("failed to list projects: %w", err)}
+			return ErrorMsg{Err: fmt.Errorf("failed to list projects: %w", err)}
 		}
 
 		var projects []Project
 		if err := json.Unmarshal(output, &projects); err != nil {
-			return ErrorMsg{fmt.Errorf("failed to parse project data: %w", err)}
+			return ErrorMsg{Err: fmt.Errorf("failed to parse project data: %w", err)}
 		}
 
 		// Filter only active projects
@@ -431,49 +1015,62 @@ func (gcp *GCPService) LoadProjects() tea.Cmd {
 				activeProjects = append(activeProjects, project)
 			}
 		}
+		debugf("parsed %d projects, %d active", len(projects), len(activeProjects))
 
 		return ProjectsLoadedMsg{activeProjects}
 	}
 }
 
-// LoadVMs loads VMs from GCP project
-func (gcp *GCPService) LoadVMs(project string) tea.Cmd {
-	return func() tea.Msg {
-		cmd := exec.Command("gcloud", "compute", "instances", "list",
-			"--project", project,
-			"--format", "json(name,zone,status,metadata.items)")
-
-		output, err := cmd.Output()
-		if err != nil {
-			return ErrorMsg{fmt.Errorf("failed to list VMs: %w", err)}
-		}
-
-		var vms []VM
-		if err := json.Unmarshal(output, &vms); err != nil {
-			return ErrorMsg{fmt.Errorf("failed to parse VM data: %w", err)}
-		}
-
-		return VMsLoadedMsg{vms}
+// SSHArgs returns the gcloud command line that opens an SSH session to the VM
+func (gcp *GCPService) SSHArgs(project string, vm VM) []string {
+	host := vm.Name
+	rule, ok := gcp.sshUserRule(project, vm)
+	if ok && rule.User != "" {
+		host = rule.User + "@" + vm.Name
+	}
+	args := []string{
+		"gcloud", "compute", "ssh", host,
+		"--project", project,
+		"--zone", vm.ZoneName(),
 	}
+	if jump, ok := gcp.bastionFor(project, vm); ok {
+		// Single word: gcloud splits --ssh-flag values on whitespace
+		args = append(args, "--internal-ip", "--ssh-flag=-oProxyJump="+jump)
+	} else if gcp.forceIAP {
+		args = append(args, "--tunnel-through-iap")
+	}
+	args = append(args, gcp.sshFlags...)
+	return append(args, rule.Flags...)
 }
 
-// ConnectSSH establishes SSH connection to VM
-func (gcp *GCPService) ConnectSSH(project, vmName, zone string) error {
-	zoneParts := strings.Split(zone, "/")
-	zoneName := zoneParts[len(zoneParts)-1]
+// ExecLaunch hands the terminal over to the launch command
+func (gcp *GCPService) ExecLaunch(launch Launch) error {
+	if launch.Run != nil {
+		started := time.Now()
+		err := launch.Run()
+		auditCommand("launch", launch.Title, nil, started, err)
+		return err
+	}
 
-	gcloudPath, err := exec.LookPath("gcloud")
+	path, err := exec.LookPath(launch.Args[0])
 	if err != nil {
-		return fmt.Errorf("gcloud not found in PATH: %w", err)
+		return fmt.Errorf("%s not found in PATH: %w", launch.Args[0], err)
 	}
-
-	args := []string{
-		"gcloud", "compute", "ssh", vmName,
-		"--project", project,
-		"--zone", zoneName,
+	args := launch.Args
+	if gcp.recordTo != "" {
+		args = gcp.recording.recordArgs(args, gcp.recordTo)
+		if path, err = exec.LookPath(args[0]); err != nil {
+			return fmt.Errorf("%s not found in PATH for session recording: %w", args[0], err)
+		}
+	}
+	env := launch.Env
+	if env == nil {
+		env = os.Environ()
 	}
 
-	return syscall.Exec(gcloudPath, args, os.Environ())
+	// Recorded up front: on success execProcess never returns
+	auditCommand("launch", launch.Title, args, time.Now(), nil)
+	return execProcess(path, args, env)
 }
 
 // =============================================================================
@@ -487,12 +1084,17 @@ type ProjectsLoadedMsg struct {
 
 // VMsLoadedMsg indicates VMs have been loaded
 type VMsLoadedMsg struct {
-	VMs []VM
+	Project string
+	VMs     []VM
+	// Warning reports a partial load, e.g. zones that could not be listed
+	Warning string
 }
 
 // ErrorMsg indicates an error occurred
 type ErrorMsg struct {
 	Err error
+	// Retry re-dispatches the failed command, if it can be retried
+	Retry tea.Cmd
 }
 
 // =============================================================================
@@ -503,7 +1105,9 @@ type ErrorMsg struct {
 type AppState int
 
 const (
-	StateLoadingProjects AppState = iota
+	StateCheckingAuth AppState = iota
+	StateAuthRequired
+	StateLoadingProjects
 	StateSelectingProject
 	StateLoadingVMs
 	StateSelectingVM
@@ -524,21 +1128,89 @@ type model struct {
 	gcpService    *GCPService
 	treeManager   *TreeManager
 	filterService *FilterService
+	tunnelManager *TunnelManager
 	styles        Styles
 
 	// Data
 	projects        []Project
 	selectedProject string
 	selectedVM      *VM
+	resourceKind    ResourceKind
+	launch          *Launch
 	err             error
+	retry           tea.Cmd // Re-runs the command behind err
+	authErr         error
+	lastSession     *SessionRecord
+	latency         LatencyHistory
+	projectUsage    ProjectUsage
+	hierarchy       *projectHierarchy // Organizations and folders, loaded on first use
+	hierarchyRows   []hierarchyRow    // What each project list row stands for in the folder view
+	showHierarchy   bool
+	// Project list order; most used first unless toggled with O
+	projectsAlphabetical bool
+	config               Config
+	recommendations      map[string]Recommendation // Keyed zone/name, loaded after the VMs
+	reachability         map[string]Reachability   // SSH preflight results, keyed zone/name
+	groupWatch           *groupWatch               // Group being refreshed until it converges
+	rollout              *rollout                  // Rolling action being tracked, if any
+	runningWatch         map[string]time.Time      // Instances to notify about once RUNNING, keyed zone/name, with deadlines
+	runningWatchPending  bool                      // A reload for the running watch is scheduled
+	connectWhenRunning   string                    // Watched instance to connect to once sshd answers, zone/name
+	sshWait              *sshWait                  // Instance whose sshd is probed before connecting
+	sshReady             string                    // Instance whose probe passed or gave up, zone/name
+	previews             map[string]previewResult  // Preview command output, keyed zone/name
+	previewPending       map[string]bool           // Previews being run, keyed zone/name
+	previewSeq           int                       // Bumped on each cursor move so stale preview ticks are ignored
 
 	// UI
+	width                   int
+	height                  int
+	density                 Density
+	loadingProgress         string // Shown in the title while VM pages stream in
+	loadingSince            time.Time
+	loadingNote             string // Retry attempt shown under the spinner
+	spinner                 spinner.Model
 	list                    list.Model
 	currentlyDisplayedNodes []*TreeNode // Track what's currently shown in the list
 
 	// Filtering
 	filtering  bool
 	filterText string
+	// Applied to the first listing after -resume
+	resumeFilter string
+
+	// Actions
+	showDetails  bool
+	showPreview  bool
+	showTunnels  bool
+	tunnelCursor int
+	showTasks    bool
+	taskCursor   int
+	tasks        []task
+	confirm      *confirmation
+	input        *inputPrompt
+	picker       *picker
+	viewer       *textViewer
+	sftp         *sftpBrowser
+	statusMsg    string
+	marked       map[string]bool
+
+	// gcloud's key was checked against the OS Login profile this session
+	osLoginChecked bool
+	// Recent utilization from Cloud Monitoring, keyed zone/name
+	metrics map[string]InstanceMetrics
+	// Ops Agent heartbeats keyed zone/name; nil when they couldn't be loaded
+	opsAgent map[string]OpsAgentStatus
+	// VM Manager patch compliance keyed zone/name, with patch_compliance
+	patches map[string]PatchState
+	// Open Security Command Center findings keyed zone/name, most severe first
+	findings map[string][]Finding
+	// Snapshot batches being created, each tracked until READY or its deadline
+	snapshots   []snapshotProgress
+	snapshotSeq int
+	// Projects open as tabs, each with the tree it showed when left
+	tabs      []projectTab
+	activeTab int
 }
 
 // =============================================================================
@@ -546,15 +1218,21 @@ type model struct {
 // =============================================================================
 
 type itemDelegate struct {
-	styles Styles
+	styles  Styles
+	density Density
 }
 
 type item string
 
 func (i item) FilterValue() string { return string(i) }
 
-func (d itemDelegate) Height() int                             { return 1 }
-func (d itemDelegate) Spacing() int                            { return 0 }
+func (d itemDelegate) Height() int { return 1 }
+func (d itemDelegate) Spacing() int {
+	if d.density == DensityComfortable {
+		return 1
+	}
+	return 0
+}
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
 	i, ok := listItem.(item)
@@ -563,6 +1241,9 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	str := fmt.Sprintf("%d. %s", index+1, string(i))
+	if d.density == DensityCompact {
+		str = string(i)
+	}
 
 	fn := d.styles.Item.PaddingLeft(4).Render
 	if index == m.Index() {
@@ -571,6 +1252,15 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		}
 	}
 
+	if d.density == DensityCompact {
+		fn = d.styles.Item.PaddingLeft(2).Render
+		if index == m.Index() {
+			fn = func(s ...string) string {
+				return d.styles.SelectedItem.PaddingLeft(0).Render("> " + strings.Join(s, " "))
+			}
+		}
+	}
+
 	fmt.Fprint(w, fn(str))
 }
 
@@ -579,30 +1269,31 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 // =============================================================================
 
 // newModel creates a new application model
-func newModel(project string) model {
+func newModel(project string, hideGKENodes bool, config Config) model {
 	styles := NewStyles()
-	gcpService := NewGCPService()
+	gcpService := NewGCPService(config.Timeout)
+	gcpService.native = config.NoGcloud
+	gcpService.billingProject = config.BillingProject
+	// Checked by loadConfig and main
+	gcpService.vmFilter, _ = computeFilter(config.Filter)
+	gcpService.zoneScope = config.Zones
+	gcpService.sshUsers = config.SSHUsers
+	gcpService.hostKeys = config.HostKeys
+	gcpService.bastions = config.Bastions
+	gcpService.forceIAP = config.IAP
+	gcpService.sshFlags = config.SSHFlags
+	gcpService.recording = config.Recording
 	treeManager := NewTreeManager(styles)
+	treeManager.hideGKENodes = hideGKENodes
+	treeManager.hideTerminated = config.HideTerminated
+	treeManager.groupByZone = config.GroupBy == GroupByZone
+	// Already validated by loadConfig
+	treeManager.exclude, _ = compilePatterns(config.Exclude)
 	filterService := NewFilterService(treeManager)
 
-	var items []list.Item
-	var state AppState
-	var title string
-
-	if project != "" {
-		// Project provided via command line - skip to loading VMs
-		state = StateLoadingVMs
-		title = "Loading VMs..."
-		items = []list.Item{}
-	} else {
-		// No project provided - start by loading available projects
-		state = StateLoadingProjects
-		title = "Loading GCP Projects..."
-		items = []list.Item{}
-	}
-
-	l := list.New(items, itemDelegate{styles: styles}, DefaultWidth, DefaultHeight)
-	l.Title = title
+	// Credentials are verified before anything is listed
+	l := list.New([]list.Item{}, itemDelegate{styles: styles}, DefaultWidth, DefaultHeight)
+	l.Title = "Checking gcloud credentials..."
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
 	l.Styles.Title = styles.Title
@@ -610,15 +1301,33 @@ func newModel(project string) model {
 	l.Styles.HelpStyle = styles.Help
 	l.Styles.NoItems = styles.NoItems
 
-	return model{
-		state:           state,
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = styles.Prompt
+
+	m := model{
+		state:           StateCheckingAuth,
 		gcpService:      gcpService,
 		treeManager:     treeManager,
 		filterService:   filterService,
+		tunnelManager:   NewTunnelManager(),
 		styles:          styles,
 		selectedProject: project,
+		lastSession:     loadLastSession(),
+		latency:         loadLatencyHistory(),
+		projectUsage:    loadProjectUsage(),
+		config:          config,
 		list:            l,
+		spinner:         s,
+		loadingSince:    time.Now(),
+	}
+
+	// Other sources need neither gcloud credentials nor a project
+	if rt, ok := sourceType(config.Source); ok {
+		m.resourceKind = rt.Kind
+		m.state = StateLoadingVMs
 	}
+	return m
 }
 
 // getCurrentNode returns the currently selected tree node
@@ -643,11 +1352,7 @@ func (m *model) updateVMList() {
 		nodesToShow = m.treeManager.GetNodes()
 	}
 
-	// Update tree manager nodes for display temporarily
-	originalNodes := m.treeManager.GetNodes()
-	m.treeManager.nodes = nodesToShow
-	flatNodes := m.treeManager.FlattenForDisplay()
-	m.treeManager.nodes = originalNodes // Restore original
+	flatNodes := m.treeManager.FlattenForDisplay(nodesToShow)
 
 	// Store the currently displayed nodes for getCurrentNode()
 	m.currentlyDisplayedNodes = flatNodes
@@ -655,13 +1360,55 @@ func (m *model) updateVMList() {
 	// Create list items
 	items := make([]list.Item, len(flatNodes))
 	for i, node := range flatNodes {
-		items[i] = item(m.treeManager.RenderNode(node))
+		row := m.treeManager.RenderNode(node)
+		if m.isLastSession(node) {
+			row += " " + m.styles.LastSession.Render("[last]")
+		}
+		if rec, ok := m.recommendationFor(node); ok {
+			row += " " + m.styles.Recommendation.Render("["+rec.Badge()+"]")
+		}
+		if node.Type == InstanceNode {
+			if problem := m.opsAgentProblem(*node.VM); problem != "" {
+				row += " " + m.styles.Recommendation.Render("["+problem+"]")
+			}
+			if badge := m.patches[markKey(*node.VM)].Badge(); badge != "" {
+				row += " " + m.styles.Recommendation.Render("["+badge+"]")
+			}
+			if badge := m.findingsBadge(*node.VM); badge != "" {
+				row += " " + m.styles.Finding.Render("[⚠ "+badge+"]")
+			}
+		}
+		if m.isProductionVMNode(node) {
+			row += " " + m.styles.Production.Render("[prod]")
+		}
+		if m.isMarked(node) {
+			row += " " + m.styles.Prompt.Render("[✓]")
+		}
+		items[i] = item(row)
 	}
 
 	m.list.SetItems(items)
 
 	// Update title
-	baseTitle := fmt.Sprintf("Sunrise Parabellum\nSelect VM from project: %s", m.selectedProject)
+	baseTitle := fmt.Sprintf("Sunrise Parabellum\nSelect %s from project: %s", resourceType(m.resourceKind).Singular, m.selectedProject)
+	if m.onSource() {
+		baseTitle = fmt.Sprintf("Sunrise Parabellum\nSelect %s", resourceType(m.resourceKind).Singular)
+	}
+	if m.config.ReadOnly {
+		baseTitle += " (read-only)"
+	}
+	if m.config.Filter != "" && m.resourceKind == KindInstances {
+		baseTitle += " " + m.styles.Filter.Render("["+m.config.Filter+"]")
+	}
+	if len(m.config.Zones) > 0 && m.resourceKind == KindInstances {
+		baseTitle += " " + m.styles.Filter.Render("["+strings.Join(m.config.Zones, ",")+"]")
+	}
+	if m.loadingProgress != "" {
+		baseTitle += " " + m.styles.Label.Render(m.loadingProgress)
+	}
+	if len(m.tabs) > 1 && !m.onSource() {
+		baseTitle += "\n" + m.renderProjectTabs()
+	}
 	if m.filtering {
 		filterText := m.styles.Filter.Render("Filter:") + " " + m.filterText
 		m.list.Title = fmt.Sprintf("%s\n%s", baseTitle, filterText)
@@ -670,67 +1417,316 @@ func (m *model) updateVMList() {
 	}
 }
 
+// resizeList fits the list to the window, leaving room for the details pane
+func (m *model) resizeList() {
+	width := m.width
+	if (m.showDetails || m.showPreview) && m.state == StateSelectingVM {
+		width = width * DetailsSplitPercent / 100
+	}
+	m.list.SetWidth(width)
+}
+
+// cycleDensity switches to the next row density and re-renders the list
+func (m model) cycleDensity() (tea.Model, tea.Cmd) {
+	m.density = m.density.Next()
+	m.treeManager.density = m.density
+	m.treeManager.invalidateRows()
+	m.list.SetDelegate(itemDelegate{styles: m.styles, density: m.density})
+	if m.state == StateSelectingVM {
+		m.updateVMList()
+	}
+	m.statusMsg = fmt.Sprintf("Row density: %s", m.density)
+	return m, nil
+}
+
 // Init implements tea.Model
 func (m model) Init() tea.Cmd {
-	if m.selectedProject != "" && m.state == StateLoadingVMs {
-		return m.gcpService.LoadVMs(m.selectedProject)
-	} else if m.state == StateLoadingProjects {
-		return m.gcpService.LoadProjects()
+	if m.state == StateCheckingAuth {
+		return tea.Batch(m.gcpService.CheckAuth(), m.spinner.Tick)
+	}
+	if m.state == StateLoadingVMs {
+		return tea.Batch(m.loadCmd(), m.spinner.Tick)
 	}
 	return nil
 }
 
+// startLoading loads VMs for the preselected project, or the project list otherwise
+func (m model) startLoading() (tea.Model, tea.Cmd) {
+	if m.selectedProject != "" {
+		// Project provided via command line - skip to loading VMs
+		if usage, err := recordProjectVisit(m.selectedProject); err == nil {
+			m.projectUsage = usage
+		}
+		return m.loadResources()
+	}
+
+	// No project provided - start by loading available projects
+	m.state = StateLoadingProjects
+	m.list.Title = "Loading GCP Projects..."
+	spin := m.startSpinner()
+	return m, tea.Batch(withRetry("GCP Projects", m.gcpService.forLoad().LoadProjects()), spin)
+}
+
 // Update implements tea.Model
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	debugMsg(msg)
 	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		return m.handleSpinnerTick(msg)
+
 	case tea.WindowSizeMsg:
 		availableHeight := msg.Height - UIOverhead
+		if m.hasSourceTabs() {
+			availableHeight--
+		}
 		if availableHeight < MinHeight {
 			availableHeight = MinHeight
 		}
-		m.list.SetWidth(msg.Width)
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resizeList()
 		m.list.SetHeight(availableHeight)
 		return m, nil
 
 	case tea.KeyMsg:
-		// Handle navigation keys first (up/down arrows) - always pass to list
+		// A pending confirmation captures all input
 		keypress := msg.String()
+		if m.err != nil {
+			return m.handleErrorKey(keypress)
+		}
+		if keypress == "esc" && (m.state == StateLoadingVMs || m.state == StateLoadingProjects) {
+			return m.cancelLoad()
+		}
+		if m.confirm != nil {
+			return m.handleConfirmInput(keypress)
+		}
+		if m.input != nil {
+			return m.handleInputKey(msg)
+		}
+		if m.picker != nil {
+			return m.handlePickerInput(keypress)
+		}
+		if m.viewer != nil {
+			return m.handleViewerKey(msg)
+		}
+		if m.sftp != nil {
+			return m.handleSFTPKey(keypress)
+		}
+		if m.showTunnels {
+			return m.handleTunnelsPanelKeys(keypress)
+		}
+		if m.showTasks {
+			return m.handleTasksPanelKeys(keypress)
+		}
+
+		// Handle navigation keys first (up/down arrows) - always pass to list
 		if m.shouldHandleNavigation(keypress) {
 			var cmd tea.Cmd
 			m.list, cmd = m.list.Update(msg)
-			return m, cmd
+			return m, tea.Batch(cmd, m.schedulePreview())
 		}
 
 		// Handle custom keys
 		return m.handleKeyPress(msg)
 
 	case ProjectsLoadedMsg:
-		m.projects = msg.Projects
-		m.state = StateSelectingProject
-
-		// Create list items for projects
-		items := make([]list.Item, len(m.projects))
-		for i, project := range m.projects {
-			items[i] = item(fmt.Sprintf("%s (%s)", project.ProjectID, project.Name))
+		m.projects = m.config.Projects.filter(msg.Projects)
+		if m.onSource() {
+			// Switched to another source while the projects were loading
+			return m, nil
 		}
+		if len(m.projects) < len(msg.Projects) {
+			m.statusMsg = fmt.Sprintf("%d of %d projects hidden by config", len(msg.Projects)-len(m.projects), len(msg.Projects))
+		}
+		m.state = StateSelectingProject
 
-		m.list.SetItems(items)
+		m.sortProjects()
+		m.refreshProjectList()
 		m.list.Title = "Select GCP Project"
 		return m, nil
 
 	case VMsLoadedMsg:
-		m.state = StateSelectingVM
-		m.filtering = false
-		m.filterText = ""
+		if m.resourceKind != KindInstances || msg.Project != m.selectedProject || !m.expectsListing() {
+			// The user switched types, tabs or cancelled while this was loading
+			return m, nil
+		}
+		if m.state != StateSelectingVM {
+			// Keep the filter when this completes a streamed load or a reload
+			m.state = StateSelectingVM
+			m.resetFilter()
+		}
+		m.loadingProgress = ""
+		if msg.Warning != "" {
+			m.statusMsg = msg.Warning
+		}
 		m.treeManager.BuildFromVMs(msg.VMs)
 		m.updateVMList() // This will set currentlyDisplayedNodes
-		return m, nil
+		var watchCmd, runningCmd, notifyCmd tea.Cmd
+		m, watchCmd = m.advanceGroupWatch()
+		m, runningCmd = m.advanceRunningWatch()
+		m, notifyCmd = m.notifySlowLoad(fmt.Sprintf("%d VMs", len(msg.VMs)))
+		return m, tea.Batch(
+			m.gcpService.LoadRecommendations(m.selectedProject, recommendationZones(msg.VMs)),
+			m.gcpService.LoadMetrics(m.selectedProject, msg.VMs),
+			m.gcpService.LoadOpsAgent(m.selectedProject, msg.VMs),
+			m.loadPatches(msg.VMs),
+			m.gcpService.LoadFindings(m.selectedProject),
+			watchCmd, runningCmd, notifyCmd)
+
+	case RunningWatchTickMsg:
+		return m.handleRunningWatchTick()
+
+	case SSHProbedMsg:
+		return m.handleSSHProbed(msg)
+
+	case SSHProbeTickMsg:
+		return m.handleSSHProbeTick()
+
+	case PreviewTickMsg:
+		return m.handlePreviewTick(msg)
+
+	case PreviewLoadedMsg:
+		return m.handlePreviewLoaded(msg)
+
+	case VMsPageMsg:
+		return m.handleVMsPage(msg)
+
+	case RecommendationsLoadedMsg:
+		return m.handleRecommendationsLoaded(msg)
+
+	case MetricsLoadedMsg:
+		return m.handleMetricsLoaded(msg)
+
+	case OpsAgentLoadedMsg:
+		return m.handleOpsAgentLoaded(msg)
+
+	case PatchesLoadedMsg:
+		return m.handlePatchesLoaded(msg)
+
+	case FindingsLoadedMsg:
+		return m.handleFindingsLoaded(msg)
+
+	case SnapshotsStartedMsg:
+		return m.handleSnapshotsStarted(msg)
+
+	case SnapshotPollMsg:
+		return m.handleSnapshotPoll(msg)
+
+	case SnapshotStatusMsg:
+		return m.handleSnapshotStatus(msg)
+
+	case MachineTypesLoadedMsg:
+		return m.handleMachineTypesLoaded(msg)
+
+	case FreeDisksLoadedMsg:
+		return m.handleFreeDisksLoaded(msg)
+
+	case TemplateZonesLoadedMsg:
+		return m.handleTemplateZonesLoaded(msg)
+
+	case TaskStartedMsg:
+		return m.handleTaskStarted(msg)
+
+	case TaskPollMsg:
+		return m.handleTaskPoll(msg)
+
+	case TaskStatusMsg:
+		return m.handleTaskStatus(msg)
+
+	case ReachabilityCheckedMsg:
+		return m.handleReachabilityChecked(msg)
+
+	case GroupResizedMsg:
+		return m.handleGroupResized(msg)
+
+	case RolloutStartedMsg:
+		return m.handleRolloutStarted(msg)
+
+	case RolloutProgressMsg:
+		return m.handleRolloutProgress(msg)
+
+	case GroupHealthMsg:
+		return m.handleGroupHealth(msg)
+
+	case ResourcesLoadedMsg:
+		return m.handleResourcesLoaded(msg)
+
+	case HierarchyLoadedMsg:
+		return m.handleHierarchyLoaded(msg)
+
+	case ClusterCredentialsMsg:
+		return m.handleClusterCredentials(msg)
+
+	case AuthCheckedMsg:
+		return m.handleAuthChecked(msg)
+
+	case AuthLoginFinishedMsg:
+		return m.handleAuthLoginFinished(msg)
+
+	case DeletionProtectionUpdatedMsg:
+		return m.handleDeletionProtectionUpdated(msg)
+
+	case OperationDoneMsg:
+		return m.handleOperationDone(msg)
+
+	case ScreenshotSavedMsg:
+		return m.handleScreenshotSaved(msg)
+
+	case StartupScriptLoadedMsg:
+		return m.handleStartupScriptLoaded(msg)
+
+	case ContainersDiscoveredMsg:
+		return m.handleContainersDiscovered(msg)
+
+	case TunnelExitedMsg:
+		return m.handleTunnelExited(msg)
+
+	case SessionClonedMsg:
+		return m.handleSessionCloned(msg)
+
+	case SSHFSResolvedMsg:
+		return m.handleSSHFSResolved(msg)
+
+	case SSHuttleResolvedMsg:
+		return m.handleSSHuttleResolved(msg)
+
+	case OSLoginCheckedMsg:
+		return m.handleOSLoginChecked(msg)
+
+	case OSLoginEnrolledMsg:
+		return m.handleOSLoginEnrolled(msg)
+
+	case SFTPConnectedMsg:
+		return m.handleSFTPConnected(msg)
+
+	case SFTPListedMsg:
+		return m.handleSFTPListed(msg)
+
+	case SFTPDoneMsg:
+		return m.handleSFTPDone(msg)
+
+	case RetryScheduledMsg:
+		return m.handleRetryScheduled(msg)
 
 	case ErrorMsg:
+		if errors.Is(msg.Err, context.Canceled) {
+			// The user cancelled this load and has already moved on
+			return m, nil
+		}
 		m.err = msg.Err
+		m.retry = msg.Retry
 		return m, nil
 	}
 
+	// Cursor blinks and similar messages belong to an open text prompt
+	if m.input != nil {
+		pending := *m.input
+		var cmd tea.Cmd
+		pending.Input, cmd = pending.Input.Update(msg)
+		m.input = &pending
+		return m, cmd
+	}
+
 	return m, nil
 }
 
@@ -748,6 +1744,10 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleVMSelection(keypress)
 	}
 
+	if m.state == StateAuthRequired {
+		return m.handleAuthRequiredKeys(keypress)
+	}
+
 	// Handle global keys
 	return m.handleGlobalKeys(keypress)
 }
@@ -769,11 +1769,9 @@ func (m model) handleFilteringInput(keypress string) (tea.Model, tea.Cmd) {
 	case "enter":
 		currentNode := m.getCurrentNode()
 		if currentNode != nil {
-			if currentNode.Type == InstanceNode {
-				m.selectedVM = currentNode.VM
-				m.state = StateReadyToConnect
-				return m, tea.Quit
-			} else if currentNode.Type == GroupNode {
+			if currentNode.Type != GroupNode {
+				return m.enterNode(currentNode)
+			} else {
 				// Find and toggle the original node in the tree manager
 				for _, originalNode := range m.treeManager.GetNodes() {
 					if originalNode.Type == GroupNode && originalNode.Name == currentNode.Name {
@@ -811,11 +1809,17 @@ func (m model) shouldHandleNavigation(keypress string) bool {
 
 // handleVMSelection handles VM selection navigation
 func (m model) handleVMSelection(keypress string) (tea.Model, tea.Cmd) {
+	if next, cmd, ok := m.handleTabKey(keypress); ok {
+		return next, cmd
+	}
 	switch keypress {
+	case "tab":
+		return m.switchSource(1)
+	case "shift+tab":
+		return m.switchSource(-1)
 	case "right":
 		if currentNode := m.getCurrentNode(); currentNode != nil && currentNode.Type == GroupNode && !currentNode.IsExpanded {
-			m.treeManager.ToggleNode(currentNode)
-			m.updateVMList()
+			return m.toggleGroup(currentNode)
 		}
 		return m, nil
 	case "left":
@@ -826,8 +1830,7 @@ func (m model) handleVMSelection(keypress string) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "space":
 		if currentNode := m.getCurrentNode(); currentNode != nil && currentNode.Type == GroupNode {
-			m.treeManager.ToggleNode(currentNode)
-			m.updateVMList()
+			return m.toggleGroup(currentNode)
 		}
 		return m, nil
 	case "enter":
@@ -839,12 +1842,96 @@ func (m model) handleVMSelection(keypress string) (tea.Model, tea.Cmd) {
 			m.updateVMList()
 		}
 		return m, nil
+	case "t":
+		return m.cycleResourceKind()
+	case "=":
+		return m.cycleDensity()
+	case "T":
+		m.showTunnels = true
+		m.tunnelCursor = 0
+		return m, nil
+	case "J":
+		m.showTasks = true
+		m.taskCursor = 0
+		return m, nil
+	case "O":
+		return m.cycleSortOrder()
+	case "$":
+		m.treeManager.showCost = !m.treeManager.showCost
+		m.treeManager.invalidateRows()
+		m.updateVMList()
+		if m.treeManager.showCost {
+			m.statusMsg = "Showing estimated monthly costs (on-demand list prices)"
+		} else {
+			m.statusMsg = ""
+		}
+		return m, nil
+	case "G":
+		if !m.listsInstances() {
+			m.statusMsg = "G only applies to instances"
+			return m, nil
+		}
+		m.treeManager.SetHideGKENodes(!m.treeManager.hideGKENodes)
+		m.updateVMList()
+		if m.treeManager.hideGKENodes {
+			m.statusMsg = "GKE nodes hidden"
+		} else {
+			m.statusMsg = "GKE nodes grouped by node pool"
+		}
+		return m, nil
+	case "H":
+		if !m.listsInstances() {
+			m.statusMsg = "H only applies to instances"
+			return m, nil
+		}
+		m.treeManager.SetHideTerminated(!m.treeManager.hideTerminated)
+		m.updateVMList()
+		if m.treeManager.hideTerminated {
+			m.statusMsg = "Terminated instances hidden"
+		} else {
+			m.statusMsg = "Showing terminated instances"
+		}
+		return m, nil
+	case "i":
+		m.showDetails = !m.showDetails
+		m.resizeList()
+		return m, nil
+	case "v":
+		return m.togglePreview()
+	case "r":
+		if !m.showPreview {
+			break
+		}
+		return m.refreshPreview()
 	case "esc":
+		if m.sshWait != nil || m.connectWhenRunning != "" {
+			m.sshWait = nil
+			m.connectWhenRunning = ""
+			m.statusMsg = "Won't connect automatically"
+			return m, nil
+		}
+		if m.onSource() {
+			return m, nil
+		}
 		return m.goBackToProjectSelection()
 	case "q":
+		if running := m.tunnelManager.Running(); running > 0 {
+			return m.askConfirm(fmt.Sprintf("Stop %d running tunnel(s) and quit?", running), func(m model) (tea.Model, tea.Cmd) {
+				m.quitting = true
+				return m, tea.Quit
+			})
+		}
 		m.quitting = true
 		return m, tea.Quit
 	}
+
+	if keypress == "a" {
+		return m.openActionMenu(m.getCurrentNode())
+	}
+	if action := m.findAction(keypress, m.getCurrentNode()); action != nil {
+		m.statusMsg = ""
+		return m.runAction(*action, m.getCurrentNode())
+	}
 	return m, nil
 }
 
@@ -856,14 +1943,20 @@ func (m model) handleEnterOnVM() (tea.Model, tea.Cmd) {
 	}
 
 	if currentNode.Type == GroupNode {
-		m.treeManager.ToggleNode(currentNode)
-		m.updateVMList()
-	} else if currentNode.Type == InstanceNode {
-		m.selectedVM = currentNode.VM
-		m.state = StateReadyToConnect
-		return m, tea.Quit
+		return m.toggleGroup(currentNode)
 	}
-	return m, nil
+	return m.enterNode(currentNode)
+}
+
+// enterNode picks or connects to a non-group node, confirming production connections
+func (m model) enterNode(node *TreeNode) (tea.Model, tea.Cmd) {
+	if m.config.Pick {
+		// Printing a name touches nothing, so production needs no confirmation
+		return m.pickNode(node)
+	}
+	return m.guard(EffectConnect, "connect", node, func(m model) (tea.Model, tea.Cmd) {
+		return m.connect(node)
+	})
 }
 
 // handleGlobalKeys handles global keyboard shortcuts
@@ -877,35 +1970,134 @@ func (m model) handleGlobalKeys(keypress string) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 		}
-	case "enter":
+	case "=":
+		if m.state == StateSelectingProject {
+			return m.cycleDensity()
+		}
+	case "O":
+		if m.state == StateSelectingProject {
+			return m.toggleProjectOrder()
+		}
+	case "h":
+		if m.state == StateSelectingProject {
+			return m.toggleHierarchy()
+		}
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9", "ctrl+tab":
 		if m.state == StateSelectingProject {
-			if i, ok := m.list.SelectedItem().(item); ok {
-				// Extract project ID from the display string "projectId (projectName)"
-				projectDisplay := string(i)
-				projectID := strings.Split(projectDisplay, " (")[0]
-				m.selectedProject = projectID
-				m.state = StateLoadingVMs
-				m.list.Title = "Loading VMs..."
-				return m, m.gcpService.LoadVMs(m.selectedProject)
+			next, cmd, _ := m.handleTabKey(keypress)
+			return next, cmd
+		}
+	case "tab", "shift+tab":
+		if m.state == StateSelectingProject {
+			if keypress == "tab" {
+				return m.switchSource(1)
+			}
+			return m.switchSource(-1)
+		}
+	case "o":
+		if m.state == StateSelectingProject {
+			if projectID, ok := m.selectedProjectID(); ok {
+				return m.openInConsole(projectConsoleURL(projectID))
 			}
 		}
+	case "enter", "space":
+		if m.state != StateSelectingProject {
+			break
+		}
+		if row, ok := m.selectedHierarchyRow(); ok && row.Container != "" {
+			return m.toggleContainer(row.Container)
+		}
+		if projectID, ok := m.selectedProjectID(); ok && keypress == "enter" {
+			if i := m.tabIndex(projectID); i >= 0 {
+				// Already open: show the tab as it was left
+				return m.switchTab(i)
+			}
+			m.selectedProject = projectID
+			if usage, err := recordProjectVisit(projectID); err == nil {
+				m.projectUsage = usage
+			}
+			return m.loadResources()
+		}
 	}
 	return m, nil
 }
 
-// goBackToProjectSelection returns to project selection
-func (m model) goBackToProjectSelection() (tea.Model, tea.Cmd) {
+// projectLabel renders a project as "projectId (projectName)", followed by any aliases
+func (m model) projectLabel(project Project) string {
+	row := fmt.Sprintf("%s (%s)", project.ProjectID, project.Name)
+	if aliases := m.config.aliasesOf(project.ProjectID); len(aliases) > 0 {
+		row += " " + m.styles.Label.Render("["+strings.Join(aliases, ", ")+"]")
+	}
+	return row
+}
+
+// refreshProjectList fills the list with projects, flat or by folder
+func (m *model) refreshProjectList() {
+	if m.showHierarchy && m.hierarchy != nil {
+		var items []list.Item
+		items, m.hierarchyRows = m.hierarchyItems()
+		m.list.SetItems(items)
+		return
+	}
+
+	m.hierarchyRows = nil
 	items := make([]list.Item, len(m.projects))
 	for i, project := range m.projects {
-		items[i] = item(fmt.Sprintf("%s (%s)", project.ProjectID, project.Name))
+		items[i] = item(m.projectLabel(project))
 	}
 	m.list.SetItems(items)
+}
+
+// selectedProjectID returns the project under the cursor on the selection screen
+func (m model) selectedProjectID() (string, bool) {
+	if m.showHierarchy {
+		row, ok := m.selectedHierarchyRow()
+		return row.ProjectID, ok && row.ProjectID != ""
+	}
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return "", false
+	}
+	// Extract project ID from the display string "projectId (projectName)"
+	return strings.Split(string(i), " (")[0], true
+}
+
+// goBackToProjectSelection returns to project selection
+func (m model) goBackToProjectSelection() (tea.Model, tea.Cmd) {
+	m.saveTab()
+	m.sortProjects()
+	m.refreshProjectList()
 	m.list.Title = "Select GCP Project"
+	if len(m.tabs) > 0 {
+		m.list.Title += "\n" + m.renderProjectTabs()
+	}
 	m.state = StateSelectingProject
-	m.currentlyDisplayedNodes = nil // Clear displayed nodes
+	m.resetSelection()
 	return m, nil
 }
 
+// resetSelection drops state tied to the tree being left
+func (m *model) resetSelection() {
+	m.currentlyDisplayedNodes = nil // Clear displayed nodes
+	m.marked = nil
+	m.recommendations = nil
+	m.metrics = nil
+	m.opsAgent = nil
+	m.patches = nil
+	m.findings = nil
+	m.reachability = nil
+	m.groupWatch = nil
+	m.runningWatch = nil
+	m.connectWhenRunning = ""
+	m.sshWait = nil
+	m.sshReady = ""
+	m.previews = nil
+	m.previewPending = nil
+	m.rollout = nil
+	m.treeManager.groupHealth = nil
+	m.statusMsg = ""
+}
+
 // isValidFilterChar checks if character is valid for filtering
 func isValidFilterChar(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
@@ -918,31 +2110,92 @@ func (m model) View() string {
 		return m.styles.QuitText.Render("Goodbye!")
 	}
 
+	// Failed loads keep their loading state, so errors come first
+	if m.err != nil {
+		help := "Press Esc to go back, 'q' to quit."
+		if m.retry != nil {
+			help = "Press 'r' to retry, Esc to go back, 'q' to quit."
+		}
+		return fmt.Sprintf("\n  Error: %v\n\n  %s\n", m.err, help)
+	}
+
+	if m.state == StateCheckingAuth {
+		return m.renderLoading("Checking gcloud credentials...")
+	}
+
+	if m.state == StateAuthRequired {
+		return m.renderAuthRequired()
+	}
+
 	if m.state == StateLoadingProjects {
-		return "\n  Loading GCP projects...\n\n"
+		return m.renderLoading("Loading GCP projects...")
 	}
 
 	if m.state == StateLoadingVMs {
-		return fmt.Sprintf("\n  Loading VMs for project: %s\n\n", m.selectedProject)
+		if m.onSource() {
+			return m.renderLoading(fmt.Sprintf("Loading %s...", resourceType(m.resourceKind).Plural))
+		}
+		return m.renderLoading(fmt.Sprintf("Loading %s for project: %s", resourceType(m.resourceKind).Plural, m.selectedProject))
 	}
 
 	if m.state == StateReadyToConnect {
-		return fmt.Sprintf("\n  Connecting to %s...\n\n", m.selectedVM.Name)
+		return fmt.Sprintf("\n  Connecting to %s...\n\n", m.launch.Title)
 	}
 
-	if m.err != nil {
-		return fmt.Sprintf("\n  Error: %v\n\n  Press 'q' to quit.\n", m.err)
+	if m.viewer != nil {
+		return m.renderViewer()
+	}
+	if m.sftp != nil {
+		if m.confirm != nil {
+			return m.renderSFTP() + "\n\n  " + m.styles.Prompt.Render(m.confirm.Prompt) + " (y/N)"
+		}
+		return m.renderSFTP()
 	}
 
 	s := "\n" + m.list.View()
+	if m.state == StateSelectingVM && (m.showDetails || m.showPreview) {
+		var panes []string
+		if m.showDetails {
+			panes = append(panes, m.renderDetails(m.getCurrentNode()))
+		}
+		if m.showPreview {
+			panes = append(panes, m.renderPreview(m.getCurrentNode()))
+		}
+		s = "\n" + lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), lipgloss.JoinVertical(lipgloss.Left, panes...))
+	}
+	if m.hasSourceTabs() {
+		s = "\n" + m.renderSourceTabs() + s[1:]
+	}
+
+	if m.confirm != nil {
+		return s + "\n\n  " + m.styles.Prompt.Render(m.confirm.Prompt) + " (y/N)"
+	}
+	if m.input != nil {
+		return s + "\n\n" + m.renderInput()
+	}
+	if m.picker != nil {
+		return s + "\n\n" + m.renderPicker()
+	}
+	if m.showTunnels {
+		return s + "\n" + m.renderTunnels()
+	}
+	if m.showTasks {
+		return s + "\n" + m.renderTasks()
+	}
+	if m.statusMsg != "" && (m.state == StateSelectingVM || m.state == StateSelectingProject) {
+		s += "\n  " + m.styles.StatusLine.Render(m.statusMsg)
+	}
 
 	if m.state == StateSelectingProject {
-		s += "\n\n  Press Enter to select, 'q' to quit"
+		s += "\n\n  Press Enter to select, 'o' to open in Cloud Console, '=' for density, 'O' to sort, 'h' for folders, 'q' to quit"
 	} else if m.state == StateSelectingVM {
 		if m.filtering {
 			s += "\n  Press Enter to connect, Backspace to edit, Esc to clear filter, 'q' to quit"
 		} else {
-			s += "\n\n  Press Enter to select/expand, → to expand, ← to collapse, Space to toggle, '/' to filter, Esc to go back, 'q' to quit"
+			s += "\n\n  Press Enter to select/expand, Alt+Enter for an extra session, 't' to switch resource type, → to expand, ← to collapse, Space to toggle, '/' to filter, '=' for density, 'G' to hide GKE nodes, 'H' to hide terminated, '$' for costs, 'O' to sort, 'a' for the action menu, 'T' for tunnels, 'J' for tasks, 'i' for details, 'v' for a remote preview ('r' runs it), Esc to go back, 'q' to quit"
+			if hints := m.actionHints(m.getCurrentNode()); hints != "" {
+				s += "\n  Actions: " + hints
+			}
 		}
 	}
 
@@ -954,39 +2207,280 @@ func (m model) View() string {
 // =============================================================================
 
 func main() {
+	// Invoked by ssh's LocalCommand once a tracked connection is up
+	if isLatencyCallback() {
+		if err := recordLatencyCallback(); err != nil {
+			fmt.Fprintf(os.Stderr, "werkroom: could not record connection time: %v\n", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			os.Exit(runConfigCommand(os.Args[2:]))
+		case "update":
+			os.Exit(runUpdateCommand(os.Args[2:]))
+		}
+	}
+
 	// Parse command line arguments
-	projectFlag := flag.String("project", "", "GCP project ID to use (skips project selection)")
+	projectFlag := flag.String("project", "", "GCP project ID or alias to use (skips project selection)")
+	hideGKEFlag := flag.Bool("hide-gke-nodes", false, "Hide GKE node VMs instead of grouping them by node pool")
+	readOnlyFlag := flag.Bool("read-only", false, "Disable all actions that change resources")
+	noGcloudFlag := flag.Bool("no-gcloud", false, "Use Application Default Credentials and the built-in SSH client instead of gcloud")
+	timeoutFlag := flag.Duration("timeout", 0, "Limit for each gcloud call (default 2m)")
+	filterFlag := flag.String("filter", "", "Compute Engine filter for listed VMs, e.g. \"labels.env=prod AND status=RUNNING\"")
+	zonesFlag := flag.String("zones", "", "Comma-separated zones or regions to list VMs in, e.g. us-central1-a,europe-west1")
+	billingProjectFlag := flag.String("billing-project", "", "Project to charge API quota and billing to")
+	debugFlag := flag.Bool("debug", false, "Log gcloud calls, timings and UI messages to the debug log")
+	debugLogFlag := flag.String("debug-log", "", "Debug log file (default: werkroom state dir/debug.log)")
+	configFlag := flag.String("config", "", "Path to config.yaml (default: user config dir/werkroom/config.yaml)")
+	sourceFlag := flag.String("source", "", "Inventory to browse: "+strings.Join(sourceNames(), ", ")+" (default gcp)")
+	inventoryFlag := flag.String("inventory", "", "YAML or JSON host catalog to browse; implies -source=inventory")
+	stdinFlag := flag.Bool("stdin", false, "Pick from lines or JSON objects piped to stdin and print the choice; same as -source=stdin")
+	sourcesFlag := flag.String("sources", "", "Comma-separated sources to switch between with Tab, e.g. gcp,tailscale")
+	recordFlag := flag.Bool("record", false, "Record interactive sessions with asciinema or script")
+	resumeFlag := flag.Bool("resume", false, "Reopen the project, tabs, filter and expanded groups of the last run")
+	profileFlag := flag.String("profile", "", "Apply a named profile from the config: project, filter, zones, grouping and more")
+	connectMethodFlag := flag.String("connect-method", "", "How to connect to instances: ssh (default) or et")
+	pickFlag := flag.Bool("pick", false, "Print the selected project/zone/name to stdout instead of connecting")
+	outputTemplateFlag := flag.String("output-template", "", "Go template for -pick output, e.g. '{{.Project}} {{.Name}} {{.InternalIP}}'; implies -pick")
+	cpuProfileFlag := flag.String("cpuprofile", "", "Write a CPU profile to this file")
+	memProfileFlag := flag.String("memprofile", "", "Write a heap profile to this file on exit")
+	versionFlag := flag.Bool("version", false, "Print the version and exit")
+	flag.Usage = usage
 	flag.Parse()
+	if *versionFlag {
+		fmt.Println(versionString())
+		return
+	}
+
+	stopProfiling, err := startProfiling(*cpuProfileFlag, *memProfileFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	configPath := *configFlag
+	if configPath == "" {
+		configPath = os.Getenv(envConfig)
+	}
+	if configPath == "" {
+		var err error
+		if configPath, err = defaultConfigPath(); err != nil {
+			log.Fatalf("Could not locate the config directory: %v", err)
+		}
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	envProject, err := config.applyEnv(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// A chosen profile beats the ambient environment
+	profileName := *profileFlag
+	if profileName == "" {
+		profileName = os.Getenv(envProfile)
+	}
+	var profile ProfileConfig
+	if profileName != "" {
+		if profile, err = config.applyProfile(profileName); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if config.Theme == ThemeMono {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	config.ReadOnly = config.ReadOnly || *readOnlyFlag
+	config.NoGcloud = config.NoGcloud || *noGcloudFlag
+	if *outputTemplateFlag != "" {
+		config.OutputTemplate = *outputTemplateFlag
+	}
+	if config.OutputTemplate != "" {
+		if _, err := parseOutputTemplate(config.OutputTemplate); err != nil {
+			log.Fatal(err)
+		}
+	}
+	config.Pick = *pickFlag || *outputTemplateFlag != ""
+	config.Recording.Enabled = config.Recording.Enabled || *recordFlag
+	if *connectMethodFlag != "" {
+		config.ConnectMethod = *connectMethodFlag
+	}
+	if method := config.ConnectMethod; method != "" && method != ConnectSSH && method != ConnectET {
+		log.Fatalf("Unknown connect method %q, expected %s or %s", method, ConnectSSH, ConnectET)
+	}
+	if *inventoryFlag != "" {
+		config.Inventory = *inventoryFlag
+		if *sourceFlag == "" {
+			config.Source = "inventory"
+		}
+	}
+	if config.Bastions, err = resolveBastions(config.Bastions, config.Inventory); err != nil {
+		log.Fatal(err)
+	}
+	if *sourcesFlag != "" {
+		config.Sources = splitList(*sourcesFlag)
+	}
+	for _, source := range config.Sources {
+		if _, ok := sourceType(source); source != "gcp" && !ok {
+			log.Fatalf("Unknown source %q in sources, expected any of: %s", source, strings.Join(sourceNames(), ", "))
+		}
+	}
+	if *stdinFlag {
+		config.Source = "stdin"
+	} else if *sourceFlag != "" {
+		config.Source = *sourceFlag
+	} else if config.Source == "" && len(config.Sources) > 0 {
+		config.Source = config.Sources[0]
+	}
+	if config.Source == "gcp" {
+		config.Source = ""
+	}
+	if _, ok := sourceType(config.Source); config.Source != "" && !ok {
+		log.Fatalf("Unknown source %q, expected one of: %s", config.Source, strings.Join(sourceNames(), ", "))
+	}
+	if *timeoutFlag > 0 {
+		config.Timeout = *timeoutFlag
+	}
+	if *filterFlag != "" {
+		config.Filter = *filterFlag
+	}
+	if _, err := computeFilter(config.Filter); err != nil {
+		log.Fatal(err)
+	}
+	if *zonesFlag != "" {
+		config.Zones = splitList(*zonesFlag)
+	}
+	if *billingProjectFlag != "" {
+		config.BillingProject = *billingProjectFlag
+	}
+	if config.BillingProject != "" {
+		// Inherited by every gcloud werkroom runs, including SSH sessions and tunnels
+		os.Setenv("CLOUDSDK_BILLING_QUOTA_PROJECT", config.BillingProject)
+	}
+
+	if *debugFlag {
+		logPath := *debugLogFlag
+		if logPath == "" {
+			var err error
+			if logPath, err = debugLogPath(); err != nil {
+				log.Fatalf("Could not locate the state directory: %v", err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		logFile, err := enableDebugLog(logPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer logFile.Close()
+		debugf("werkroom starting: project=%q profile=%q read-only=%v config=%s", *projectFlag, profileName, config.ReadOnly, configPath)
+	}
+	migrateLegacyState()
 
-	// Check dependencies
-	if _, err := exec.LookPath("gcloud"); err != nil {
-		log.Fatal("gcloud CLI is required but not installed. Please install Google Cloud SDK.")
+	// Without gcloud, fall back to Application Default Credentials
+	usesGCP := config.Source == "" || slices.Contains(config.Sources, "gcp")
+	if _, err := exec.LookPath("gcloud"); err != nil && !config.NoGcloud && usesGCP {
+		fmt.Fprintln(os.Stderr, "werkroom: gcloud not found, using Application Default Credentials (features that need gcloud are unavailable)")
+		config.NoGcloud = true
 	}
 
 	// If project is provided, validate it exists (but don't exit if it doesn't - let gcloud handle the error)
-	selectedProject := *projectFlag
+	project := *projectFlag
+	if project == "" {
+		project = profile.Project
+	}
+	if project == "" {
+		project = envProject
+	}
+	if project == "" {
+		project = config.Project
+	}
+	selectedProject := config.resolveProject(project)
 
 	// Create and run application
-	program := tea.NewProgram(newModel(selectedProject), tea.WithAltScreen())
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if config.Source == "stdin" || config.Pick {
+		// Stdout carries the pick (and stdin the items), so the UI talks to the terminal
+		options = append(options, tea.WithInputTTY(), tea.WithOutput(os.Stderr))
+	}
+	var resume *Workspace
+	if (*resumeFlag || config.Resume) && config.Source == "" && !config.Pick {
+		if resume = loadWorkspace(); resume != nil && selectedProject == "" {
+			selectedProject = resume.Project
+		}
+	}
+	initial := newModel(selectedProject, *hideGKEFlag, config)
+	if resume != nil {
+		initial.restoreWorkspace(*resume)
+	}
+	program := tea.NewProgram(initial, options...)
 
 	finalModel, err := program.Run()
+	if m, ok := finalModel.(model); ok {
+		// Tunnels live only as long as the browser that started them
+		m.tunnelManager.StopAll()
+		if ws, ok := m.workspace(); ok {
+			if err := saveWorkspace(ws); err != nil {
+				debugf("could not save workspace: %v", err)
+			}
+		}
+	}
+	// Profiles cover the TUI only; the SSH session replaces this process
+	stopProfiling()
 	if err != nil {
 		fmt.Printf("Error running program: %v", err)
 		os.Exit(1)
 	}
 
 	// Handle SSH connection
-	handleSSHConnection(finalModel)
+	if !handleSSHConnection(finalModel) && (config.Source == "stdin" || config.Pick) {
+		// Like other pickers, tell the script that nothing was chosen
+		os.Exit(1)
+	}
 }
 
-// handleSSHConnection handles SSH connection after program exit
-func handleSSHConnection(finalModel tea.Model) {
-	if m, ok := finalModel.(model); ok && m.state == StateReadyToConnect && !m.quitting {
-		fmt.Printf("Connecting to %s in project %s...\n", m.selectedVM.Name, m.selectedProject)
+// handleSSHConnection handles SSH connection after program exit, reporting whether there was one
+func handleSSHConnection(finalModel tea.Model) bool {
+	m, ok := finalModel.(model)
+	if !ok || m.state != StateReadyToConnect || m.quitting {
+		return false
+	}
+	if m.launch.Pick {
+		fmt.Println(m.launch.Output)
+		return true
+	}
 
-		if err := m.gcpService.ConnectSSH(m.selectedProject, m.selectedVM.Name, m.selectedVM.Zone); err != nil {
-			fmt.Printf("SSH connection failed: %v\n", err)
-			os.Exit(1)
+	if m.onSource() {
+		fmt.Printf("Connecting to %s...\n", m.launch.Title)
+	} else {
+		fmt.Printf("Connecting to %s in project %s...\n", m.launch.Title, m.selectedProject)
+	}
+
+	if m.selectedVM != nil {
+		if err := saveLastSession(m.selectedProject, *m.selectedVM); err != nil {
+			fmt.Printf("Warning: could not record session: %v\n", err)
 		}
 	}
+	if m.config.Recording.Enabled {
+		scope := m.selectedProject
+		if m.onSource() {
+			scope = m.currentSource()
+		}
+		path, err := m.config.Recording.recordingPath(scope, m.launch.Title, time.Now())
+		if err != nil {
+			fmt.Printf("Warning: not recording session: %v\n", err)
+		} else {
+			m.gcpService.recordTo = path
+			fmt.Printf("Recording session to %s\n", path)
+		}
+	}
+
+	if err := m.gcpService.ExecLaunch(*m.launch); err != nil {
+		fmt.Printf("Connection failed: %v\n", err)
+		os.Exit(1)
+	}
+	return true
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// MULTI-SELECTION
+// =============================================================================
+
+// markKey identifies a VM across reloads
+func markKey(vm VM) string {
+	return vm.ZoneName() + "/" + vm.Name
+}
+
+// isMarked reports whether the node is a marked VM
+func (m model) isMarked(node *TreeNode) bool {
+	return node.Type == InstanceNode && m.marked[markKey(*node.VM)]
+}
+
+// toggleMark marks or unmarks the selected VM for bulk actions
+func (m model) toggleMark(node *TreeNode) (tea.Model, tea.Cmd) {
+	key := markKey(*node.VM)
+
+	// Copy on write: the map is shared with earlier model values
+	marked := make(map[string]bool, len(m.marked)+1)
+	for k := range m.marked {
+		marked[k] = true
+	}
+	if marked[key] {
+		delete(marked, key)
+	} else {
+		marked[key] = true
+	}
+	m.marked = marked
+
+	m.statusMsg = fmt.Sprintf("%d instance(s) marked (M to clear)", len(marked))
+	m.updateVMList()
+	return m, nil
+}
+
+// clearMarks unmarks all VMs
+func (m model) clearMarks() (tea.Model, tea.Cmd) {
+	m.marked = nil
+	m.statusMsg = "Marks cleared"
+	m.updateVMList()
+	return m, nil
+}
+
+// targetVMs returns the marked VMs, or the node's VM when nothing is marked
+func (m model) targetVMs(node *TreeNode) []VM {
+	var targets []VM
+	if len(m.marked) > 0 {
+		for _, vm := range m.treeManager.vms {
+			if m.marked[markKey(vm)] {
+				targets = append(targets, vm)
+			}
+		}
+	}
+	if len(targets) == 0 && node != nil && node.VM != nil {
+		targets = append(targets, *node.VM)
+	}
+	return targets
+}
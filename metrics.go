@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// CLOUD MONITORING METRICS
+// =============================================================================
+
+const (
+	// How far back the details pane sparklines go
+	metricsWindow = time.Hour
+	// Points are averaged over this period
+	metricsAlignment = 5 * time.Minute
+)
+
+// Filters for the series shown in the details pane. Memory is only reported
+// by instances running the Ops Agent.
+const (
+	cpuMetricFilter    = `metric.type="compute.googleapis.com/instance/cpu/utilization" AND resource.type="gce_instance"`
+	memoryMetricFilter = `metric.type="agent.googleapis.com/memory/percent_used" AND metric.labels.state="used" AND resource.type="gce_instance"`
+)
+
+// InstanceMetrics holds recent utilization of an instance, oldest point first
+type InstanceMetrics struct {
+	// CPU utilization as a fraction of the allocated vCPUs
+	CPU []float64
+	// Memory used in percent, empty without the Ops Agent
+	Memory []float64
+}
+
+// MetricsLoadedMsg carries metrics keyed like marks (zone/name)
+type MetricsLoadedMsg struct {
+	Project string
+	Metrics map[string]InstanceMetrics
+	Err     error
+}
+
+// timeSeries is the subset of a Cloud Monitoring time series we use
+type timeSeries struct {
+	Metric struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metric"`
+	Resource struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"resource"`
+	Points []struct {
+		Interval struct {
+			EndTime time.Time `json:"endTime"`
+		} `json:"interval"`
+		Value struct {
+			DoubleValue float64 `json:"doubleValue"`
+		} `json:"value"`
+	} `json:"points"`
+}
+
+// LoadMetrics fetches the last hour of CPU and memory utilization of the project's instances
+func (gcp *GCPService) LoadMetrics(project string, vms []VM) tea.Cmd {
+	return func() tea.Msg {
+		// Series identify instances by ID, which is unique where names are not
+		keys := make(map[string]string, len(vms))
+		for _, vm := range vms {
+			if vm.ID != "" {
+				keys[vm.ID] = markKey(vm)
+			}
+		}
+		metrics := make(map[string]InstanceMetrics)
+		if len(keys) == 0 {
+			return MetricsLoadedMsg{Project: project, Metrics: metrics}
+		}
+
+		cpu, err := gcp.listTimeSeries(project, cpuMetricFilter, "ALIGN_MEAN")
+		if err != nil {
+			return MetricsLoadedMsg{Project: project, Metrics: metrics, Err: err}
+		}
+		memory, err := gcp.listTimeSeries(project, memoryMetricFilter, "ALIGN_MEAN")
+		for _, series := range cpu {
+			if key, ok := keys[series.Resource.Labels["instance_id"]]; ok {
+				entry := metrics[key]
+				entry.CPU = series.values()
+				metrics[key] = entry
+			}
+		}
+		for _, series := range memory {
+			if key, ok := keys[series.Resource.Labels["instance_id"]]; ok {
+				entry := metrics[key]
+				entry.Memory = series.values()
+				metrics[key] = entry
+			}
+		}
+		return MetricsLoadedMsg{Project: project, Metrics: metrics, Err: err}
+	}
+}
+
+// listTimeSeries lists the series matching a filter over the metrics window,
+// aligned to metricsAlignment with the given aligner, e.g. ALIGN_MEAN
+func (gcp *GCPService) listTimeSeries(project, filter, aligner string) ([]timeSeries, error) {
+	end := time.Now().UTC()
+	query := url.Values{
+		"filter":                       {filter},
+		"interval.startTime":           {end.Add(-metricsWindow).Format(time.RFC3339)},
+		"interval.endTime":             {end.Format(time.RFC3339)},
+		"aggregation.alignmentPeriod":  {fmt.Sprintf("%ds", int(metricsAlignment.Seconds()))},
+		"aggregation.perSeriesAligner": {aligner},
+	}
+
+	var all []timeSeries
+	for {
+		endpoint := fmt.Sprintf("https://monitoring.googleapis.com/v3/projects/%s/timeSeries?%s", project, query.Encode())
+		data, err := gcp.callAPI(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return all, fmt.Errorf("failed to list metrics: %w", err)
+		}
+		var page struct {
+			TimeSeries    []timeSeries `json:"timeSeries"`
+			NextPageToken string       `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return all, fmt.Errorf("failed to parse metrics: %w", err)
+		}
+		all = append(all, page.TimeSeries...)
+		if page.NextPageToken == "" {
+			return all, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// values returns the series' points oldest first; the API lists newest first
+func (s timeSeries) values() []float64 {
+	values := make([]float64, len(s.Points))
+	for i, point := range s.Points {
+		values[len(values)-1-i] = point.Value.DoubleValue
+	}
+	return values
+}
+
+// handleMetricsLoaded stores metrics for the current project
+func (m model) handleMetricsLoaded(msg MetricsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Project != m.selectedProject {
+		return m, nil
+	}
+	// Like recommendations, metrics are advisory: a disabled Monitoring API
+	// or a missing permission just leaves the details pane without them
+	if msg.Err != nil {
+		debugf("metrics: %v", msg.Err)
+	}
+	m.metrics = msg.Metrics
+	return m, nil
+}
+
+// latestValue returns the most recent value of a series
+func latestValue(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[len(values)-1], true
+}
+
+// averageValue returns the mean of a series
+func averageValue(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// valueSparkline draws a series scaled to a fixed maximum, so an idle
+// instance looks idle rather than stretched to full height
+func valueSparkline(values []float64, maximum float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	var b strings.Builder
+	for _, v := range values {
+		level := int(v / maximum * float64(len(blocks)-1))
+		level = max(0, min(level, len(blocks)-1))
+		b.WriteRune(blocks[level])
+	}
+	return b.String()
+}
+
+// formatUtilization renders a series as a sparkline with the current and average value
+func formatUtilization(values []float64, maximum float64) string {
+	now, _ := latestValue(values)
+	return fmt.Sprintf("%s %.0f%% now, %.0f%% avg 1h", valueSparkline(values, maximum),
+		now/maximum*100, averageValue(values)/maximum*100)
+}
+
+// leastLoaded returns the group member with the lowest current CPU utilization
+func (m model) leastLoaded(group *TreeNode) (string, float64, bool) {
+	var (
+		name   string
+		lowest float64
+		found  bool
+	)
+	for _, child := range group.Children {
+		if child.VM == nil || VMStatus(child.VM.Status) != StatusRunning {
+			continue
+		}
+		cpu, ok := latestValue(m.metrics[markKey(*child.VM)].CPU)
+		if ok && (!found || cpu < lowest) {
+			name, lowest, found = child.Name, cpu, true
+		}
+	}
+	return name, lowest, found
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// MANAGED INSTANCE GROUP HEALTH AND AUTOSCALER
+// =============================================================================
+
+// GroupHealth summarizes a MIG's instances and autoscaler
+type GroupHealth struct {
+	Total   int
+	Healthy int
+	// HealthChecked is false when no autohealing health check reports state,
+	// in which case Healthy counts running instances instead
+	HealthChecked bool
+
+	TargetSize  int
+	Autoscaled  bool
+	Mode        string
+	MinReplicas int
+	MaxReplicas int
+	Recommended int
+}
+
+// Summary returns the compact text appended to the group header
+func (h GroupHealth) Summary() string {
+	word := "running"
+	if h.HealthChecked {
+		word = "healthy"
+	}
+	summary := fmt.Sprintf("%d/%d %s", h.Healthy, h.Total, word)
+	if h.Autoscaled {
+		summary += fmt.Sprintf(" · autoscale %d-%d → %d", h.MinReplicas, h.MaxReplicas, h.Recommended)
+		if h.Mode != "" && h.Mode != "ON" {
+			summary += " (" + h.Mode + ")"
+		}
+	}
+	return summary
+}
+
+// GroupHealthMsg carries the health of a group fetched on expansion
+type GroupHealthMsg struct {
+	Group  string
+	Health GroupHealth
+	Err    error
+}
+
+// groupDescription is the subset of `instance-groups managed describe` we use
+type groupDescription struct {
+	TargetSize int `json:"targetSize"`
+	Autoscaler *struct {
+		RecommendedSize   int `json:"recommendedSize"`
+		AutoscalingPolicy struct {
+			Mode           string `json:"mode"`
+			MinNumReplicas int    `json:"minNumReplicas"`
+			MaxNumReplicas int    `json:"maxNumReplicas"`
+		} `json:"autoscalingPolicy"`
+	} `json:"autoscaler,omitempty"`
+}
+
+// LoadGroupHealth fetches instance health and autoscaler state of a MIG
+func (gcp *GCPService) LoadGroupHealth(project string, group ManagedGroup) tea.Cmd {
+	return func() tea.Msg {
+		args := append([]string{"compute", "instance-groups", "managed", "list-instances", group.Name,
+			"--format", "json(instanceStatus,instanceHealth[].detailedHealthState)"}, group.gcloudArgs(project)...)
+		output, err := gcp.runGcloud(args...)
+		if err != nil {
+			return GroupHealthMsg{Group: group.Name, Err: fmt.Errorf("failed to list instances of %s: %w", group.Name, err)}
+		}
+
+		var instances []struct {
+			InstanceStatus string `json:"instanceStatus"`
+			InstanceHealth []struct {
+				DetailedHealthState string `json:"detailedHealthState"`
+			} `json:"instanceHealth"`
+		}
+		if err := json.Unmarshal(output, &instances); err != nil {
+			return GroupHealthMsg{Group: group.Name, Err: fmt.Errorf("failed to parse instances: %w", err)}
+		}
+
+		var health GroupHealth
+		for _, instance := range instances {
+			health.Total++
+			if len(instance.InstanceHealth) > 0 {
+				health.HealthChecked = true
+			}
+		}
+		for _, instance := range instances {
+			if health.HealthChecked {
+				if len(instance.InstanceHealth) > 0 && instance.InstanceHealth[0].DetailedHealthState == "HEALTHY" {
+					health.Healthy++
+				}
+			} else if VMStatus(instance.InstanceStatus) == StatusRunning {
+				health.Healthy++
+			}
+		}
+
+		args = append([]string{"compute", "instance-groups", "managed", "describe", group.Name,
+			"--format", "json"}, group.gcloudArgs(project)...)
+		output, err = gcp.runGcloud(args...)
+		if err != nil {
+			return GroupHealthMsg{Group: group.Name, Err: fmt.Errorf("failed to describe %s: %w", group.Name, err)}
+		}
+
+		var description groupDescription
+		if err := json.Unmarshal(output, &description); err != nil {
+			return GroupHealthMsg{Group: group.Name, Err: fmt.Errorf("failed to parse %s: %w", group.Name, err)}
+		}
+		health.TargetSize = description.TargetSize
+		if autoscaler := description.Autoscaler; autoscaler != nil {
+			health.Autoscaled = true
+			health.Mode = autoscaler.AutoscalingPolicy.Mode
+			health.MinReplicas = autoscaler.AutoscalingPolicy.MinNumReplicas
+			health.MaxReplicas = autoscaler.AutoscalingPolicy.MaxNumReplicas
+			health.Recommended = autoscaler.RecommendedSize
+		}
+
+		return GroupHealthMsg{Group: group.Name, Health: health}
+	}
+}
+
+// toggleGroup expands or collapses a group, refreshing MIG health on expansion
+func (m model) toggleGroup(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.treeManager.ToggleNode(node)
+	m.updateVMList()
+
+	// The displayed node is a copy while filtering, so check the tree's own state
+	group, ok := managedGroupOf(node)
+	if !ok || !m.treeManager.isExpanded(node.Name) {
+		return m, nil
+	}
+	return m, m.gcpService.LoadGroupHealth(m.selectedProject, group)
+}
+
+// isExpanded reports whether the named group is expanded
+func (tm *TreeManager) isExpanded(name string) bool {
+	for _, node := range tm.nodes {
+		if node.Type == GroupNode && node.Name == name {
+			return node.IsExpanded
+		}
+	}
+	return false
+}
+
+// handleGroupHealth stores a group's health for its header
+func (m model) handleGroupHealth(msg GroupHealthMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	if m.treeManager.groupHealth == nil {
+		m.treeManager.groupHealth = make(map[string]GroupHealth)
+	}
+	m.treeManager.groupHealth[msg.Group] = msg.Health
+	m.updateVMList()
+	return m, nil
+}
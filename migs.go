@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// MANAGED INSTANCE GROUPS
+// =============================================================================
+
+// How often and for how long the list is refreshed while a group converges
+const (
+	groupWatchInterval = 5 * time.Second
+	groupWatchTimeout  = 10 * time.Minute
+)
+
+// ManagedGroup identifies a managed instance group and where it lives
+type ManagedGroup struct {
+	Name string
+	// Either "--zone" or "--region"
+	ScopeFlag string
+	Location  string
+}
+
+// gcloudArgs returns the flags locating the group
+func (g ManagedGroup) gcloudArgs(project string) []string {
+	return []string{"--project", project, g.ScopeFlag, g.Location}
+}
+
+// ManagedGroup returns the MIG that created the VM, parsed from created-by metadata:
+// projects/NUMBER/(zones|regions)/LOCATION/instanceGroupManagers/NAME
+func (vm VM) ManagedGroup() (ManagedGroup, bool) {
+	parts := strings.Split(vm.GetMetadata("created-by"), "/")
+	for i := 0; i+3 < len(parts); i++ {
+		if parts[i+2] != "instanceGroupManagers" {
+			continue
+		}
+		group := ManagedGroup{Name: parts[i+3], Location: parts[i+1]}
+		switch parts[i] {
+		case "zones":
+			group.ScopeFlag = "--zone"
+		case "regions":
+			group.ScopeFlag = "--region"
+		default:
+			return ManagedGroup{}, false
+		}
+		return group, true
+	}
+	return ManagedGroup{}, false
+}
+
+// managedGroupOf returns the MIG behind a group node, judged by its first instance.
+// Zone groups from group_by: zone aren't MIGs even when their first instance is in one.
+func managedGroupOf(node *TreeNode) (ManagedGroup, bool) {
+	if node.Type != GroupNode || node.IsGKE || len(node.Children) == 0 {
+		return ManagedGroup{}, false
+	}
+	group, ok := node.Children[0].VM.ManagedGroup()
+	return group, ok && group.Name == node.Name
+}
+
+// isManagedGroup reports whether the node is a MIG outside of GKE
+func isManagedGroup(_ model, node *TreeNode) bool {
+	_, ok := managedGroupOf(node)
+	return ok
+}
+
+// groupWatch tracks a group converging after a change so the list keeps refreshing
+type groupWatch struct {
+	Group    string
+	Target   int
+	Deadline time.Time
+}
+
+// GroupResizedMsg indicates a resize request was accepted
+type GroupResizedMsg struct {
+	Group  string
+	Target int
+	Err    error
+}
+
+// ResizeGroup sets the target size of a managed instance group
+func (gcp *GCPService) ResizeGroup(project string, group ManagedGroup, size int) tea.Cmd {
+	return func() tea.Msg {
+		args := append([]string{"compute", "instance-groups", "managed", "resize", group.Name,
+			"--size", strconv.Itoa(size)}, group.gcloudArgs(project)...)
+		if _, err := gcp.runGcloud(args...); err != nil {
+			return GroupResizedMsg{Group: group.Name, Err: fmt.Errorf("failed to resize %s: %w", group.Name, err)}
+		}
+		return GroupResizedMsg{Group: group.Name, Target: size}
+	}
+}
+
+// startResizeGroup prompts for the new target size of the selected MIG
+func (m model) startResizeGroup(node *TreeNode) (tea.Model, tea.Cmd) {
+	group, _ := managedGroupOf(node)
+	current := len(node.Children)
+
+	prompt := fmt.Sprintf("New size for %s (currently %d):", group.Name, current)
+	return m.askInput(prompt, strconv.Itoa(current), func(m model, value string) (tea.Model, tea.Cmd) {
+		size, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || size < 0 {
+			m.statusMsg = fmt.Sprintf("Invalid size %q", value)
+			return m, nil
+		}
+		if size == current {
+			m.statusMsg = fmt.Sprintf("%s already has %d instances", group.Name, size)
+			return m, nil
+		}
+		m.statusMsg = fmt.Sprintf("Resizing %s to %d...", group.Name, size)
+		return m, m.gcpService.ResizeGroup(m.selectedProject, group, size)
+	})
+}
+
+// handleGroupResized starts watching the group until it reaches its new size
+func (m model) handleGroupResized(msg GroupResizedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	m.groupWatch = &groupWatch{Group: msg.Group, Target: msg.Target, Deadline: time.Now().Add(groupWatchTimeout)}
+	m.statusMsg = fmt.Sprintf("Resizing %s to %d...", msg.Group, msg.Target)
+	return m, withRetry("VMs", m.gcpService.LoadVMs(m.selectedProject))
+}
+
+// groupSize returns the number of listed instances in the named group
+func (tm *TreeManager) groupSize(name string) int {
+	for _, node := range tm.nodes {
+		if node.Type == GroupNode && node.Name == name {
+			return len(node.Children)
+		}
+	}
+	return 0
+}
+
+// advanceGroupWatch reports progress after a reload and schedules the next one
+func (m model) advanceGroupWatch() (model, tea.Cmd) {
+	watch := m.groupWatch
+	if watch == nil {
+		return m, nil
+	}
+
+	size := m.treeManager.groupSize(watch.Group)
+	if size == watch.Target {
+		m.groupWatch = nil
+		m.statusMsg = fmt.Sprintf("%s now has %d instances", watch.Group, size)
+		return m, nil
+	}
+	if time.Now().After(watch.Deadline) {
+		m.groupWatch = nil
+		m.statusMsg = fmt.Sprintf("Stopped watching %s at %d/%d instances", watch.Group, size, watch.Target)
+		return m, nil
+	}
+
+	m.statusMsg = fmt.Sprintf("Resizing %s: %d/%d instances...", watch.Group, size, watch.Target)
+	gcp, project := m.gcpService, m.selectedProject
+	return m, tea.Tick(groupWatchInterval, func(time.Time) tea.Msg {
+		return withRetry("VMs", gcp.LoadVMs(project))()
+	})
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/term"
+)
+
+// =============================================================================
+// NATIVE MODE (NO GCLOUD)
+// =============================================================================
+
+// errNoGcloud is returned by features that still shell out to gcloud
+var errNoGcloud = errors.New("not available without the gcloud CLI")
+
+// Scopes requested from Application Default Credentials; the email scope
+// identifies the OS Login user
+var adcScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/userinfo.email",
+}
+
+const (
+	// Lifetime of the ephemeral key registered with OS Login
+	osLoginKeyTTL = time.Hour
+	// OS Login keys take a moment to reach the VM
+	sshDialAttempts = 4
+	sshDialBackoff  = 3 * time.Second
+)
+
+// adcToken returns an access token from Application Default Credentials
+func (gcp *GCPService) adcToken() (string, error) {
+	if gcp.tokenSource == nil {
+		source, err := google.DefaultTokenSource(context.Background(), adcScopes...)
+		if err != nil {
+			return "", fmt.Errorf("no Application Default Credentials: %w", err)
+		}
+		gcp.tokenSource = oauth2.ReuseTokenSource(nil, source)
+	}
+	token, err := gcp.tokenSource.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// adcAccount returns the email the ADC token belongs to
+func (gcp *GCPService) adcAccount() (string, error) {
+	gcp.tokenMu.Lock()
+	token, err := gcp.adcToken()
+	gcp.tokenMu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	// Posted as a form so the token stays out of URLs and the debug log
+	req, err := http.NewRequestWithContext(gcp.baseContext(), http.MethodPost, "https://oauth2.googleapis.com/tokeninfo",
+		strings.NewReader(url.Values{"access_token": {token}}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := (&http.Client{Timeout: apiTimeout}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to parse token info: %w", err)
+	}
+	if info.Email == "" {
+		return "", errors.New("the credentials carry no email; OS Login needs a user or service account")
+	}
+	return info.Email, nil
+}
+
+// checkADC verifies Application Default Credentials in place of gcloud accounts
+func (gcp *GCPService) checkADC() tea.Msg {
+	account, err := gcp.adcAccount()
+	if err != nil {
+		return AuthCheckedMsg{Err: fmt.Errorf("failed to use Application Default Credentials: %w", err)}
+	}
+	return AuthCheckedMsg{Account: account}
+}
+
+// listProjectsAPI lists active projects through the Resource Manager API
+func (gcp *GCPService) listProjectsAPI() ([]Project, error) {
+	var projects []Project
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("filter", "lifecycleState:ACTIVE")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		data, err := gcp.callAPI("GET", "https://cloudresourcemanager.googleapis.com/v1/projects?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Projects      []Project `json:"projects"`
+			NextPageToken string    `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		projects = append(projects, page.Projects...)
+		if page.NextPageToken == "" {
+			return projects, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// importOSLoginKey registers a public key for the account and returns its POSIX username
+func (gcp *GCPService) importOSLoginKey(project, account string, publicKey ssh.PublicKey) (string, error) {
+	body := map[string]any{
+		"key":                strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		"expirationTimeUsec": strconv.FormatInt(time.Now().Add(osLoginKeyTTL).UnixMicro(), 10),
+	}
+	data, err := gcp.callAPI("POST", fmt.Sprintf("https://oslogin.googleapis.com/v1/users/%s:importSshPublicKey?projectId=%s",
+		url.PathEscape(account), url.QueryEscape(project)), body)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		LoginProfile struct {
+			PosixAccounts []struct {
+				Username string `json:"username"`
+				Primary  bool   `json:"primary"`
+			} `json:"posixAccounts"`
+		} `json:"loginProfile"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("failed to parse login profile: %w", err)
+	}
+	for _, posix := range response.LoginProfile.PosixAccounts {
+		if posix.Primary || len(response.LoginProfile.PosixAccounts) == 1 {
+			return posix.Username, nil
+		}
+	}
+	return "", fmt.Errorf("no POSIX account for %s in the OS Login profile", account)
+}
+
+// nativeSSH opens an interactive session with a built-in SSH client, authenticating
+// with an ephemeral key registered through OS Login. IAP tunnelling is not supported,
+// so the VM must be reachable on its external or internal IP.
+func (gcp *GCPService) nativeSSH(project string, vm VM) error {
+	host := vm.ExternalIP()
+	if host == "" {
+		host = vm.InternalIP()
+	}
+	if host == "" {
+		return fmt.Errorf("%s has no IP address to connect to", vm.Name)
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return err
+	}
+	account, err := gcp.adcAccount()
+	if err != nil {
+		return err
+	}
+	username, err := gcp.importOSLoginKey(project, account, signer.PublicKey())
+	if err != nil {
+		return fmt.Errorf("failed to register OS Login key: %w", err)
+	}
+	hostKeys, err := gcp.hostKeys.callback(vm.Name)
+	if err != nil {
+		return err
+	}
+
+	config := &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeys,
+		Timeout:         apiTimeout,
+	}
+	address := net.JoinHostPort(host, "22")
+	var client *ssh.Client
+	for attempt := 1; ; attempt++ {
+		client, err = ssh.Dial("tcp", address, config)
+		if err == nil || attempt == sshDialAttempts || !strings.Contains(err.Error(), "unable to authenticate") {
+			break
+		}
+		time.Sleep(sshDialBackoff)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s as %s: %w", address, username, err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		termType := os.Getenv("TERM")
+		if termType == "" {
+			termType = "xterm-256color"
+		}
+		if err := session.RequestPty(termType, height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+			return fmt.Errorf("failed to allocate a terminal: %w", err)
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+	}
+
+	if err := session.Shell(); err != nil {
+		return err
+	}
+	err = session.Wait()
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		// The remote shell's own exit status is not a connection failure
+		return nil
+	}
+	return err
+}
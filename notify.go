@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// NOTIFICATIONS
+// =============================================================================
+
+// NotificationConfig turns off the ways werkroom gets your attention
+type NotificationConfig struct {
+	// Don't show desktop notifications
+	NoDesktop bool `yaml:"no_desktop"`
+	// Don't ring the terminal bell
+	NoBell bool `yaml:"no_bell"`
+	// Notify when a listing took at least this long, e.g. 20s; off when unset
+	SlowLoad time.Duration `yaml:"slow_load"`
+}
+
+// notifySlowLoad notifies when the listing that just finished took long enough
+// for the user to have switched away. Background reloads don't count.
+func (m model) notifySlowLoad(what string) (model, tea.Cmd) {
+	started := m.loadingSince
+	m.loadingSince = time.Time{}
+	threshold := m.config.Notifications.SlowLoad
+	if threshold <= 0 || started.IsZero() || time.Since(started) < threshold {
+		return m, nil
+	}
+	scope := m.selectedProject
+	if m.onSource() {
+		scope = m.currentSource()
+	}
+	body := fmt.Sprintf("Loaded %s for %s in %s", what, scope, time.Since(started).Round(time.Second))
+	return m, m.config.Notifications.notify("werkroom", body)
+}
+
+// notify rings the bell and shows a desktop notification, as far as the platform allows
+func (c NotificationConfig) notify(title, body string) tea.Cmd {
+	return func() tea.Msg {
+		if !c.NoBell {
+			// The TUI owns stdout in pick mode too, so ring on the terminal it draws on
+			fmt.Fprint(os.Stderr, "\a")
+		}
+		if c.NoDesktop {
+			return nil
+		}
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+			cmd = exec.Command("osascript", "-e", script)
+		case "windows":
+			return nil
+		default:
+			if _, err := exec.LookPath("notify-send"); err != nil {
+				return nil
+			}
+			cmd = exec.Command("notify-send", "--app-name=werkroom", title, body)
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			debugf("desktop notification failed: %v: %s", err, output)
+		}
+		return nil
+	}
+}
+
+// appleScriptString quotes text as an AppleScript string literal
+func appleScriptString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// OPS AGENT
+// =============================================================================
+
+const (
+	// The agent reports its uptime every minute; silence for this long means it's down
+	opsAgentStaleAfter = 15 * time.Minute
+	// Freshly started instances get this long before a missing agent is flagged
+	opsAgentGrace = 10 * time.Minute
+	// Ops Agent heartbeat metric, labelled with the agent version
+	opsAgentFilter = `metric.type="agent.googleapis.com/agent/uptime" AND resource.type="gce_instance"`
+	// Google's installer, which sets up the package repository and installs the agent
+	opsAgentInstall = "curl -sSfO https://dl.google.com/cloudagents/add-google-cloud-ops-agent-repo.sh && " +
+		"sudo bash add-google-cloud-ops-agent-repo.sh --also-install"
+)
+
+// OpsAgentStatus is the last heartbeat of an instance's Ops Agent
+type OpsAgentStatus struct {
+	Version  string
+	LastSeen time.Time
+}
+
+// OpsAgentLoadedMsg carries agent heartbeats keyed like marks (zone/name)
+type OpsAgentLoadedMsg struct {
+	Project string
+	Agents  map[string]OpsAgentStatus
+	Err     error
+}
+
+// IsWindows reports whether the VM boots a Windows image
+func (vm VM) IsWindows() bool {
+	for _, disk := range vm.Disks {
+		for _, license := range disk.Licenses {
+			if strings.Contains(license, "/windows-cloud/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// supportsOpsAgent reports whether the agent can be installed over SSH
+func (vm VM) supportsOpsAgent() bool {
+	return !vm.IsWindows() && !vm.IsContainerOptimized()
+}
+
+// LoadOpsAgent looks up the latest Ops Agent heartbeat of each instance
+func (gcp *GCPService) LoadOpsAgent(project string, vms []VM) tea.Cmd {
+	return func() tea.Msg {
+		keys := make(map[string]string, len(vms))
+		for _, vm := range vms {
+			if vm.ID != "" {
+				keys[vm.ID] = markKey(vm)
+			}
+		}
+		series, err := gcp.listTimeSeries(project, opsAgentFilter, "ALIGN_NEXT_OLDER")
+		if err != nil {
+			return OpsAgentLoadedMsg{Project: project, Err: err}
+		}
+
+		agents := make(map[string]OpsAgentStatus)
+		for _, s := range series {
+			key, ok := keys[s.Resource.Labels["instance_id"]]
+			if !ok || len(s.Points) == 0 {
+				continue
+			}
+			// The agent's sub-processes report separately; the newest heartbeat wins
+			seen := s.Points[0].Interval.EndTime
+			if existing, ok := agents[key]; ok && !seen.After(existing.LastSeen) {
+				continue
+			}
+			version := s.Metric.Labels["version"]
+			if _, v, ok := strings.Cut(version, "/"); ok {
+				version = v
+			}
+			agents[key] = OpsAgentStatus{Version: version, LastSeen: seen}
+		}
+		return OpsAgentLoadedMsg{Project: project, Agents: agents}
+	}
+}
+
+// handleOpsAgentLoaded stores agent heartbeats for the current project
+func (m model) handleOpsAgentLoaded(msg OpsAgentLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Project != m.selectedProject {
+		return m, nil
+	}
+	if msg.Err != nil {
+		// Without heartbeats nothing can be said about the agent; flagging
+		// every instance as missing it would be wrong
+		debugf("ops agent: %v", msg.Err)
+		m.opsAgent = nil
+	} else {
+		m.opsAgent = msg.Agents
+	}
+	m.updateVMList()
+	return m, nil
+}
+
+// opsAgentProblem describes a missing or silent agent on a running instance,
+// or returns "" when it is healthy or nothing is known
+func (m model) opsAgentProblem(vm VM) string {
+	if m.opsAgent == nil || !vm.supportsOpsAgent() {
+		return ""
+	}
+	if uptime, ok := vm.Uptime(time.Now()); !ok || uptime < opsAgentGrace {
+		return ""
+	}
+	status, ok := m.opsAgent[markKey(vm)]
+	if !ok {
+		return "no agent"
+	}
+	if time.Since(status.LastSeen) > opsAgentStaleAfter {
+		return "agent down"
+	}
+	return ""
+}
+
+// opsAgentSummary describes the agent for the details pane
+func (m model) opsAgentSummary(vm VM) (string, bool) {
+	if m.opsAgent == nil || !vm.supportsOpsAgent() {
+		return "", false
+	}
+	status, ok := m.opsAgent[markKey(vm)]
+	switch {
+	case !ok:
+		return m.styles.Stopping.Render("not installed") + m.styles.Label.Render("  (n to install)"), true
+	case time.Since(status.LastSeen) > opsAgentStaleAfter:
+		return m.styles.Stopping.Render(fmt.Sprintf("not reporting for %s", formatAge(time.Since(status.LastSeen)))), true
+	default:
+		return m.styles.Running.Render("running") + " " + status.Version, true
+	}
+}
+
+// groupOpsAgentSummary counts the group's running instances whose agent reports
+func (m model) groupOpsAgentSummary(group *TreeNode) (string, bool) {
+	if m.opsAgent == nil || group.IsGKE {
+		return "", false
+	}
+	var running, reporting int
+	for _, child := range group.Children {
+		if child.VM == nil || VMStatus(child.VM.Status) != StatusRunning || !child.VM.supportsOpsAgent() {
+			continue
+		}
+		running++
+		if status, ok := m.opsAgent[markKey(*child.VM)]; ok && time.Since(status.LastSeen) <= opsAgentStaleAfter {
+			reporting++
+		}
+	}
+	if running == 0 {
+		return "", false
+	}
+	summary := fmt.Sprintf("%d/%d running instances reporting", reporting, running)
+	if reporting < running {
+		summary += m.styles.Label.Render("  (n to install)")
+	}
+	return summary, true
+}
+
+// canInstallOpsAgent reports whether the node has instances to install the agent on
+func canInstallOpsAgent(m model, node *TreeNode) bool {
+	switch node.Type {
+	case InstanceNode:
+		return node.VM != nil && node.VM.supportsOpsAgent() && !m.gcpService.native
+	case GroupNode:
+		return !node.IsGKE && !m.gcpService.native
+	}
+	return false
+}
+
+// opsAgentTargets returns the running Linux instances of a group, or the marked ones
+func (m model) opsAgentTargets(node *TreeNode) []VM {
+	var candidates []VM
+	if node.Type == GroupNode {
+		for _, child := range node.Children {
+			if child.VM != nil {
+				candidates = append(candidates, *child.VM)
+			}
+		}
+	} else {
+		candidates = m.targetVMs(node)
+	}
+	var targets []VM
+	for _, vm := range candidates {
+		if VMStatus(vm.Status) == StatusRunning && vm.supportsOpsAgent() {
+			targets = append(targets, vm)
+		}
+	}
+	return targets
+}
+
+// startInstallOpsAgent confirms and installs the Ops Agent on a group or the marked instances
+func (m model) startInstallOpsAgent(node *TreeNode) (tea.Model, tea.Cmd) {
+	targets := m.opsAgentTargets(node)
+	if len(targets) == 0 {
+		m.statusMsg = "No running Linux instances to install the Ops Agent on"
+		return m, nil
+	}
+	prompt := fmt.Sprintf("Install the Ops Agent on %s?", targets[0].Name)
+	if len(targets) > 1 {
+		prompt = fmt.Sprintf("Install the Ops Agent on %d running instances?", len(targets))
+	}
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		m.marked = nil
+		m.statusMsg = fmt.Sprintf("Installing the Ops Agent on %d instance(s)...", len(targets))
+		return m, m.gcpService.InstallOpsAgent(m.selectedProject, targets)
+	})
+}
+
+// InstallOpsAgent runs Google's install script on each VM over SSH
+func (gcp *GCPService) InstallOpsAgent(project string, vms []VM) tea.Cmd {
+	return func() tea.Msg {
+		byKey := make(map[string]VM, len(vms))
+		keys := make([]string, 0, len(vms))
+		for _, vm := range vms {
+			byKey[markKey(vm)] = vm
+			keys = append(keys, markKey(vm))
+		}
+		// Failures are collected rather than returned so one broken host
+		// doesn't hide how the others went
+		failures, _ := fanOut(keys, func(key string) ([]error, error) {
+			vm := byKey[key]
+			args := append(gcp.SSHArgs(project, vm)[1:],
+				"--command", opsAgentInstall,
+				"--ssh-flag=-T", "--ssh-flag=-oBatchMode=yes")
+			if _, err := gcp.runGcloud(args...); err != nil {
+				return []error{fmt.Errorf("%s: %w", vm.Name, err)}, nil
+			}
+			return nil, nil
+		})
+		if len(failures) > 0 {
+			return OperationDoneMsg{Err: fmt.Errorf("failed to install the Ops Agent on %d of %d instance(s): %w",
+				len(failures), len(vms), errors.Join(failures...))}
+		}
+		return OperationDoneMsg{Description: fmt.Sprintf("Installed the Ops Agent on %d instance(s); it reports within a few minutes", len(vms))}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// OS LOGIN KEY ENROLLMENT
+// =============================================================================
+
+// OSLoginCheckedMsg reports whether gcloud's SSH key is registered with OS Login
+type OSLoginCheckedMsg struct {
+	VM VM
+	// The key is missing locally or from the OS Login profile
+	Missing bool
+	// Connect to the VM once the key is in place
+	Connect bool
+	Err     error
+}
+
+// OSLoginEnrolledMsg reports a key upload to the OS Login profile
+type OSLoginEnrolledMsg struct {
+	VM      VM
+	Connect bool
+	Err     error
+}
+
+// gcloudKeyPath returns the private key gcloud compute ssh uses
+func gcloudKeyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ssh", "google_compute_engine"), nil
+}
+
+// usesOSLogin reports whether the instance itself turns on OS Login; a
+// project-wide setting is not visible in the instance listing
+func usesOSLogin(vm VM) bool {
+	return strings.EqualFold(vm.GetMetadata("enable-oslogin"), "true")
+}
+
+// needsOSLoginCheck reports whether connecting should first make sure the key
+// is enrolled; read-only mode never enrolls, so it doesn't check either
+func (m model) needsOSLoginCheck(vm VM) bool {
+	return !m.osLoginChecked && !m.gcpService.native && !m.config.ReadOnly && usesOSLogin(vm)
+}
+
+// CheckOSLoginKey looks for gcloud's public key in the caller's OS Login profile
+func (gcp *GCPService) CheckOSLoginKey(vm VM, connect bool) tea.Cmd {
+	return func() tea.Msg {
+		path, err := gcloudKeyPath()
+		if err != nil {
+			return OSLoginCheckedMsg{VM: vm, Connect: connect, Err: err}
+		}
+		publicKey, err := os.ReadFile(path + ".pub")
+		if errors.Is(err, os.ErrNotExist) {
+			return OSLoginCheckedMsg{VM: vm, Connect: connect, Missing: true}
+		}
+		if err != nil {
+			return OSLoginCheckedMsg{VM: vm, Connect: connect, Err: err}
+		}
+
+		output, err := gcp.runGcloud("compute", "os-login", "describe-profile", "--format", "json(sshPublicKeys)")
+		if err != nil {
+			return OSLoginCheckedMsg{VM: vm, Connect: connect, Err: fmt.Errorf("failed to read OS Login profile: %w", err)}
+		}
+		var profile struct {
+			SSHPublicKeys map[string]struct {
+				Key string `json:"key"`
+			} `json:"sshPublicKeys"`
+		}
+		if err := json.Unmarshal(output, &profile); err != nil {
+			return OSLoginCheckedMsg{VM: vm, Connect: connect, Err: fmt.Errorf("failed to parse OS Login profile: %w", err)}
+		}
+		for _, registered := range profile.SSHPublicKeys {
+			if sameSSHKey(registered.Key, string(publicKey)) {
+				return OSLoginCheckedMsg{VM: vm, Connect: connect}
+			}
+		}
+		return OSLoginCheckedMsg{VM: vm, Connect: connect, Missing: true}
+	}
+}
+
+// sameSSHKey compares authorized_keys lines by type and key, ignoring comments
+func sameSSHKey(a, b string) bool {
+	fieldsA, fieldsB := strings.Fields(a), strings.Fields(b)
+	return len(fieldsA) >= 2 && len(fieldsB) >= 2 && fieldsA[0] == fieldsB[0] && fieldsA[1] == fieldsB[1]
+}
+
+// handleOSLoginChecked offers enrollment for a missing key, or goes on connecting
+func (m model) handleOSLoginChecked(msg OSLoginCheckedMsg) (tea.Model, tea.Cmd) {
+	// Whatever the outcome, don't check again this session; declining the
+	// enrollment and pressing Enter again connects anyway
+	m.osLoginChecked = true
+	if msg.Err != nil {
+		debugf("OS Login key check: %v", msg.Err)
+		if msg.Connect {
+			return m.connectToVM(&msg.VM)
+		}
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	if !msg.Missing {
+		if msg.Connect {
+			return m.connectToVM(&msg.VM)
+		}
+		m.statusMsg = "gcloud's SSH key is registered with OS Login"
+		return m, nil
+	}
+
+	vm, connect := msg.VM, msg.Connect
+	if m.config.ReadOnly {
+		if connect {
+			return m.connectToVM(&vm)
+		}
+		m.statusMsg = "Your account has no OS Login key for gcloud; read-only mode doesn't add one"
+		return m, nil
+	}
+	prompt := fmt.Sprintf("%s uses OS Login and your account has no key for gcloud. Generate and upload one?", vm.Name)
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		m.statusMsg = "Adding SSH key to your OS Login profile..."
+		return m, m.gcpService.EnrollOSLoginKey(vm, connect)
+	})
+}
+
+// EnrollOSLoginKey creates gcloud's key pair if needed and adds it to the OS Login profile
+func (gcp *GCPService) EnrollOSLoginKey(vm VM, connect bool) tea.Cmd {
+	return func() tea.Msg {
+		path, err := gcloudKeyPath()
+		if err != nil {
+			return OSLoginEnrolledMsg{VM: vm, Connect: connect, Err: err}
+		}
+		if err := ensureSSHKey(path); err != nil {
+			return OSLoginEnrolledMsg{VM: vm, Connect: connect, Err: err}
+		}
+		_, err = gcp.runGcloud("compute", "os-login", "ssh-keys", "add", "--key-file", path+".pub")
+		if err != nil {
+			err = fmt.Errorf("failed to add key to OS Login profile: %w", err)
+		}
+		return OSLoginEnrolledMsg{VM: vm, Connect: connect, Err: err}
+	}
+}
+
+// ensureSSHKey generates a key pair at path unless one exists
+func ensureSSHKey(path string) error {
+	_, privateErr := os.Stat(path)
+	_, publicErr := os.Stat(path + ".pub")
+	switch {
+	case privateErr == nil && publicErr == nil:
+		return nil
+	case privateErr == nil:
+		return fmt.Errorf("%s has no .pub file; recreate it with ssh-keygen -y -f %s", path, path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	output, err := exec.Command("ssh-keygen", "-t", "rsa", "-b", "3072", "-N", "", "-q", "-f", path, "-C", localUser()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to generate SSH key: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// handleOSLoginEnrolled reports the upload and resumes connecting
+func (m model) handleOSLoginEnrolled(msg OSLoginEnrolledMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	if msg.Connect {
+		return m.connectToVM(&msg.VM)
+	}
+	m.statusMsg = "Added gcloud's SSH key to your OS Login profile"
+	return m, nil
+}
+
+// checkOSLoginKey is the action form of the check done before connecting
+func (m model) checkOSLoginKey(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = "Checking OS Login profile..."
+	return m, m.gcpService.CheckOSLoginKey(*node.VM, false)
+}
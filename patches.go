@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// OS PATCH COMPLIANCE
+// =============================================================================
+
+const (
+	// Patch jobs older than this say nothing about pending reboots anymore
+	patchJobLookback = 30 * 24 * time.Hour
+	// Most recent patch jobs checked for instances waiting on a reboot
+	patchJobLimit = 5
+)
+
+// PatchState is an instance's patch compliance according to VM Manager
+type PatchState struct {
+	// Updates the OS reports as available but not installed
+	Missing int
+	// Of those, Windows updates classified as critical or security
+	Critical int
+	// A patch job finished but needs a reboot the instance hasn't had yet
+	RebootPending bool
+	// When the OS Config agent last reported its inventory
+	Reported time.Time
+}
+
+// Badge returns the list badge of a non-compliant instance, or ""
+func (s PatchState) Badge() string {
+	switch {
+	case s.RebootPending:
+		return "reboot pending"
+	case s.Missing > 0:
+		return fmt.Sprintf("%d patches", s.Missing)
+	}
+	return ""
+}
+
+// PatchesLoadedMsg carries patch states keyed like marks (zone/name)
+type PatchesLoadedMsg struct {
+	Project string
+	Patches map[string]PatchState
+	Err     error
+}
+
+// osInventory is the subset of an OS Config inventory we use
+type osInventory struct {
+	Name  string `json:"name"`
+	Items map[string]struct {
+		Type             string `json:"type"`
+		AvailablePackage struct {
+			WUAPackage struct {
+				Categories []struct {
+					Name string `json:"name"`
+				} `json:"categories"`
+			} `json:"wuaPackage"`
+		} `json:"availablePackage"`
+	} `json:"items"`
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// patchJob is the subset of an OS Config patch job we use
+type patchJob struct {
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"createTime"`
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// LoadPatches fetches OS inventories and recent patch job results for the project's instances
+func (gcp *GCPService) LoadPatches(project string, vms []VM) tea.Cmd {
+	return func() tea.Msg {
+		byID := make(map[string]VM, len(vms))
+		for _, vm := range vms {
+			if vm.ID != "" {
+				byID[vm.ID] = vm
+			}
+		}
+
+		inventories, err := fanOut(recommendationZones(vms), func(zone string) ([]osInventory, error) {
+			return gcp.listInventories(project, zone)
+		})
+		if err != nil {
+			return PatchesLoadedMsg{Project: project, Err: err}
+		}
+
+		patches := make(map[string]PatchState, len(inventories))
+		for _, inventory := range inventories {
+			// projects/P/locations/ZONE/instances/ID/inventory
+			parts := strings.Split(inventory.Name, "/")
+			if len(parts) < 2 {
+				continue
+			}
+			vm, ok := byID[parts[len(parts)-2]]
+			if !ok {
+				continue
+			}
+			state := PatchState{Reported: inventory.UpdateTime}
+			for _, item := range inventory.Items {
+				if item.Type != "AVAILABLE_PACKAGE" {
+					continue
+				}
+				state.Missing++
+				for _, category := range item.AvailablePackage.WUAPackage.Categories {
+					if category.Name == "Critical Updates" || category.Name == "Security Updates" {
+						state.Critical++
+						break
+					}
+				}
+			}
+			patches[markKey(vm)] = state
+		}
+
+		// Pending reboots are only known from patch jobs. They're advisory, so a
+		// failure here still shows the inventories.
+		rebooting, err := gcp.rebootsPending(project)
+		for id, finished := range rebooting {
+			vm, ok := byID[id]
+			if !ok {
+				continue
+			}
+			started, parseErr := time.Parse(time.RFC3339, vm.LastStartTimestamp)
+			if parseErr == nil && started.After(finished) {
+				continue
+			}
+			state := patches[markKey(vm)]
+			state.RebootPending = true
+			patches[markKey(vm)] = state
+		}
+		return PatchesLoadedMsg{Project: project, Patches: patches, Err: err}
+	}
+}
+
+// listInventories lists the full OS inventories of the instances in a zone
+func (gcp *GCPService) listInventories(project, zone string) ([]osInventory, error) {
+	query := url.Values{"view": {"FULL"}}
+	var all []osInventory
+	for {
+		endpoint := fmt.Sprintf("https://osconfig.googleapis.com/v1/projects/%s/locations/%s/instances/-/inventories?%s",
+			project, zone, query.Encode())
+		data, err := gcp.callAPI(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return all, fmt.Errorf("failed to list OS inventories in %s: %w", zone, err)
+		}
+		var page struct {
+			Inventories   []osInventory `json:"inventories"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return all, fmt.Errorf("failed to parse OS inventories: %w", err)
+		}
+		all = append(all, page.Inventories...)
+		if page.NextPageToken == "" {
+			return all, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// rebootsPending returns the instances, by ID, whose latest patch job run
+// needs a reboot, with the time that job last changed
+func (gcp *GCPService) rebootsPending(project string) (map[string]time.Time, error) {
+	data, err := gcp.callAPI(http.MethodGet,
+		fmt.Sprintf("https://osconfig.googleapis.com/v1/projects/%s/patchJobs?pageSize=50", project), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patch jobs: %w", err)
+	}
+	var list struct {
+		PatchJobs []patchJob `json:"patchJobs"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse patch jobs: %w", err)
+	}
+	jobs := list.PatchJobs
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreateTime.After(jobs[j].CreateTime) })
+
+	pending := make(map[string]time.Time)
+	decided := make(map[string]bool)
+	for i, job := range jobs {
+		if i == patchJobLimit || time.Since(job.CreateTime) > patchJobLookback {
+			break
+		}
+		data, err := gcp.callAPI(http.MethodGet,
+			fmt.Sprintf("https://osconfig.googleapis.com/v1/%s/instanceDetails?pageSize=1000", job.Name), nil)
+		if err != nil {
+			return pending, fmt.Errorf("failed to list patch job results: %w", err)
+		}
+		var details struct {
+			PatchJobInstanceDetails []struct {
+				InstanceSystemID string `json:"instanceSystemId"`
+				State            string `json:"state"`
+			} `json:"patchJobInstanceDetails"`
+		}
+		if err := json.Unmarshal(data, &details); err != nil {
+			return pending, fmt.Errorf("failed to parse patch job results: %w", err)
+		}
+		for _, detail := range details.PatchJobInstanceDetails {
+			// Newer jobs come first and decide for the instance
+			if decided[detail.InstanceSystemID] {
+				continue
+			}
+			decided[detail.InstanceSystemID] = true
+			if detail.State == "SUCCEEDED_REBOOT_REQUIRED" {
+				pending[detail.InstanceSystemID] = job.UpdateTime
+			}
+		}
+	}
+	return pending, nil
+}
+
+// handlePatchesLoaded stores patch states for the current project
+func (m model) handlePatchesLoaded(msg PatchesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Project != m.selectedProject {
+		return m, nil
+	}
+	if msg.Err != nil {
+		// Usually the OS Config API isn't enabled; patch info is advisory
+		debugf("patch compliance: %v", msg.Err)
+	}
+	m.patches = msg.Patches
+	m.updateVMList()
+	return m, nil
+}
+
+// loadPatches returns the command loading patch compliance, if enabled
+func (m model) loadPatches(vms []VM) tea.Cmd {
+	if !m.config.PatchCompliance {
+		return nil
+	}
+	return m.gcpService.LoadPatches(m.selectedProject, vms)
+}
+
+// patchSummary describes an instance's patch compliance for the details pane
+func (m model) patchSummary(vm VM) (string, bool) {
+	if m.patches == nil {
+		return "", false
+	}
+	state, ok := m.patches[markKey(vm)]
+	if !ok {
+		return m.styles.Label.Render("no inventory (is the OS Config agent running?)"), true
+	}
+	var parts []string
+	switch {
+	case state.Missing == 0:
+		parts = append(parts, m.styles.Running.Render("compliant"))
+	case state.Critical > 0:
+		parts = append(parts, m.styles.Stopping.Render(fmt.Sprintf("%d missing, %d critical or security", state.Missing, state.Critical)))
+	default:
+		parts = append(parts, m.styles.Stopping.Render(fmt.Sprintf("%d missing", state.Missing)))
+	}
+	if state.RebootPending {
+		parts = append(parts, m.styles.Stopping.Render("reboot pending"))
+	}
+	summary := strings.Join(parts, ", ")
+	if !state.Reported.IsZero() {
+		summary += m.styles.Label.Render(fmt.Sprintf("  (reported %s ago)", formatAge(time.Since(state.Reported))))
+	}
+	return summary, true
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// =============================================================================
+// DIRECTORIES
+// =============================================================================
+
+// Files and directories that lived in the cache directory before state got its own
+var legacyStateFiles = []string{
+	"last_session.json",
+	"workspace.json",
+	"projects.json",
+	"latency.json",
+	"audit.jsonl",
+	"known_hosts",
+	"recordings",
+}
+
+// configDir returns the directory holding config.yaml:
+// $XDG_CONFIG_HOME/werkroom, else the platform's config directory. Older
+// versions ignored XDG_CONFIG_HOME, so on macOS a config in Application
+// Support is still used while the XDG directory doesn't exist.
+func configDir() (string, error) {
+	dir, err := appDir("XDG_CONFIG_HOME", os.UserConfigDir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		return dir, nil
+	}
+	if base, err := os.UserConfigDir(); err == nil {
+		legacy := filepath.Join(base, "werkroom")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy, nil
+		}
+	}
+	return dir, nil
+}
+
+// cacheDir returns the directory for files werkroom can recreate, such as
+// kubeconfigs and mount points: $XDG_CACHE_HOME/werkroom, else the platform's
+// cache directory. On Windows that is also the state directory, so the cache
+// gets a subdirectory there and clearing it leaves state alone.
+func cacheDir() (string, error) {
+	dir, err := appDir("XDG_CACHE_HOME", os.UserCacheDir)
+	if err != nil {
+		return "", err
+	}
+	if state, err := stateDir(); err == nil && state == dir {
+		return filepath.Join(dir, "cache"), nil
+	}
+	return dir, nil
+}
+
+// stateDir returns the directory for history, logs and the saved workspace:
+// $XDG_STATE_HOME/werkroom, else ~/.local/state/werkroom or the platform's equivalent
+func stateDir() (string, error) {
+	return appDir("XDG_STATE_HOME", userStateDir)
+}
+
+// appDir returns werkroom's directory under the base named by an XDG variable,
+// or under fallback's. Relative XDG paths are invalid per the spec and ignored.
+func appDir(env string, fallback func() (string, error)) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, "werkroom"), nil
+	}
+	base, err := fallback()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "werkroom"), nil
+}
+
+// userStateDir returns the platform's base directory for state. macOS and
+// Windows have none of their own, so Application Support and %LocalAppData% stand in.
+func userStateDir() (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return os.UserConfigDir()
+	case "windows":
+		return os.UserCacheDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// migrateLegacyState moves state files from the cache directory, where older
+// versions kept them, unless the state directory already has them
+func migrateLegacyState() {
+	legacy, err := cacheDir()
+	if err != nil {
+		return
+	}
+	state, err := stateDir()
+	if err != nil || state == legacy {
+		return
+	}
+	for _, name := range legacyStateFiles {
+		from, to := filepath.Join(legacy, name), filepath.Join(state, name)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if _, err := os.Stat(to); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := os.MkdirAll(state, 0o755); err != nil {
+			debugf("could not create %s: %v", state, err)
+			return
+		}
+		if err := os.Rename(from, to); err != nil {
+			debugf("could not move %s to %s: %v", from, to, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// NAME PATTERNS
+// =============================================================================
+
+// namePatterns matches names against shell globs, or regexps written as /expr/
+type namePatterns struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// compilePatterns parses config patterns such as "gke-*" or "/^tmp-[0-9]+$/"
+func compilePatterns(patterns []string) (namePatterns, error) {
+	var compiled namePatterns
+	for _, pattern := range patterns {
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return namePatterns{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			compiled.regexps = append(compiled.regexps, re)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return namePatterns{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled.globs = append(compiled.globs, pattern)
+	}
+	return compiled, nil
+}
+
+// Match reports whether any pattern matches the whole name (regexps may match part of it)
+func (p namePatterns) Match(name string) bool {
+	for _, glob := range p.globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	for _, re := range p.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
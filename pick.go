@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// PICK MODE
+// =============================================================================
+
+// connect opens the node, or in -pick mode prints it instead
+func (m model) connect(node *TreeNode) (tea.Model, tea.Cmd) {
+	if m.config.Pick {
+		return m.pickNode(node)
+	}
+	if rule, ok := m.connectRule(node); ok {
+		return m.connectWithTemplate(rule, node)
+	}
+	return resourceType(m.resourceKind).Connect(m, node)
+}
+
+// PickedItem is what -output-template is executed with
+type PickedItem struct {
+	// GCP project, or the source name outside GCP
+	Project     string
+	Source      string
+	Zone        string
+	Location    string
+	Name        string
+	Status      string
+	Group       string
+	MachineType string
+	InternalIP  string
+	ExternalIP  string
+	Labels      map[string]string
+	// Details pane values of non-VM items, by label
+	Fields map[string]string
+}
+
+// parseOutputTemplate compiles -output-template; each item is printed on its own line
+func parseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// pickNode exits and prints the node, or every marked instance, one per line
+func (m model) pickNode(node *TreeNode) (tea.Model, tea.Cmd) {
+	if m.config.OutputTemplate == "" && node.Type == ResourceNode && node.Resource.Kind == KindStdin {
+		// The piped line is already the most useful thing to print
+		return m.pickStdinItem(node)
+	}
+
+	var items []PickedItem
+	if node.Type == InstanceNode {
+		for _, vm := range m.targetVMs(node) {
+			items = append(items, m.pickedVM(vm))
+		}
+	} else {
+		items = append(items, m.pickedResource(node))
+	}
+
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = m.selectionPath(item.Location, item.Name)
+		if m.config.OutputTemplate == "" {
+			continue
+		}
+		line, err := renderOutputTemplate(m.config.OutputTemplate, item)
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		lines[i] = line
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Output: strings.Join(lines, "\n"), Pick: true})
+}
+
+// renderOutputTemplate formats one picked item
+func renderOutputTemplate(text string, item PickedItem) (string, error) {
+	tmpl, err := parseOutputTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, item); err != nil {
+		return "", fmt.Errorf("output template failed for %s: %w", item.Name, err)
+	}
+	return out.String(), nil
+}
+
+// pickedVM describes an instance for the output template
+func (m model) pickedVM(vm VM) PickedItem {
+	return PickedItem{
+		Project:     m.selectedProject,
+		Source:      m.currentSource(),
+		Zone:        vm.ZoneName(),
+		Location:    vm.ZoneName(),
+		Name:        vm.Name,
+		Status:      vm.Status,
+		MachineType: vm.MachineTypeName(),
+		InternalIP:  vm.InternalIP(),
+		ExternalIP:  vm.ExternalIP(),
+		Labels:      vm.Labels,
+	}
+}
+
+// pickedResource describes a non-VM item for the output template
+func (m model) pickedResource(node *TreeNode) PickedItem {
+	resource := node.Resource
+	item := PickedItem{
+		Project:  m.selectedProject,
+		Source:   m.currentSource(),
+		Location: resource.Location,
+		Name:     resource.Name,
+		Status:   resource.Status,
+		Group:    resource.Group,
+		Fields:   make(map[string]string, len(resource.Fields)),
+	}
+	if m.onSource() {
+		item.Project = m.currentSource()
+	}
+	for _, field := range resource.Fields {
+		item.Fields[field.Label] = field.Value
+	}
+	return item
+}
+
+// selectionPath identifies a picked item as project/location/name, with the
+// source in place of the project outside GCP
+func (m model) selectionPath(location, name string) string {
+	parts := []string{m.selectedProject}
+	if m.onSource() {
+		parts[0] = m.currentSource()
+	}
+	if location != "" {
+		parts = append(parts, location)
+	}
+	return strings.Join(append(parts, name), "/")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// PROVIDER PLUGINS
+// =============================================================================
+
+// Executables named werkroom-provider-<name> on PATH add -source=<name>.
+//
+//	werkroom-provider-<name> list
+//	    prints {"items": [PluginItem, ...]} as JSON on stdout
+//	werkroom-provider-<name> connect <id>
+//	    is given the terminal to open a session; the chosen item is also
+//	    passed as JSON in WERKROOM_ITEM
+const pluginPrefix = "werkroom-provider-"
+
+// PluginItem is one entry of a plugin's inventory
+type PluginItem struct {
+	// Passed back to connect; defaults to Name
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Group    string `json:"group,omitempty"`
+	Location string `json:"location,omitempty"`
+	// RUNNING, TERMINATED and the other instance statuses get the usual badges
+	Status string          `json:"status,omitempty"`
+	Fields []ResourceField `json:"fields,omitempty"`
+}
+
+// pluginPath returns the executable providing a source, if one is on PATH
+func pluginPath(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	return path, err == nil
+}
+
+// pluginNames lists the sources provided by plugins on PATH
+func pluginNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), pluginPrefix)
+			if !ok || entry.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPluginItems runs the plugin's list command for the configured source
+func LoadPluginItems(config Config) tea.Cmd {
+	return func() tea.Msg {
+		path, ok := pluginPath(config.Source)
+		if !ok {
+			return ErrorMsg{Err: fmt.Errorf("no %s%s on PATH", pluginPrefix, config.Source)}
+		}
+		output, err := runSourceCommand(config, path, "list")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list %s: %w", config.Source, err)}
+		}
+		var inventory struct {
+			Items []PluginItem `json:"items"`
+		}
+		if err := json.Unmarshal(output, &inventory); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse %s list output: %w", config.Source, err)}
+		}
+
+		resources := make([]Resource, 0, len(inventory.Items))
+		for _, item := range inventory.Items {
+			resource := Resource{
+				Kind:     KindPlugin,
+				Name:     item.Name,
+				Location: item.Location,
+				Status:   item.Status,
+				Group:    item.Group,
+				Fields:   item.Fields,
+			}
+			if item.ID != "" && item.ID != item.Name {
+				resource.Fields = append([]ResourceField{{Label: "ID", Value: item.ID}}, resource.Fields...)
+			}
+			resources = append(resources, resource)
+		}
+		return ResourcesLoadedMsg{Kind: KindPlugin, Source: config.Source, Resources: resources}
+	}
+}
+
+// connectToPluginItem hands the terminal to the plugin's connect command
+func (m model) connectToPluginItem(node *TreeNode) (tea.Model, tea.Cmd) {
+	path, ok := pluginPath(m.config.Source)
+	if !ok {
+		m.statusMsg = fmt.Sprintf("%s%s is no longer on PATH", pluginPrefix, m.config.Source)
+		return m, nil
+	}
+	resource := node.Resource
+	item := PluginItem{
+		ID:       resource.Field("ID"),
+		Name:     resource.Name,
+		Group:    resource.Group,
+		Location: resource.Location,
+		Status:   resource.Status,
+	}
+	for _, field := range resource.Fields {
+		if field.Label != "ID" {
+			item.Fields = append(item.Fields, field)
+		}
+	}
+	if item.ID == "" {
+		item.ID = item.Name
+	}
+	request, err := json.Marshal(item)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Failed to encode %s: %v", node.Name, err)
+		return m, nil
+	}
+	return m.launchAndQuit(Launch{
+		Title: node.Name,
+		Args:  []string{path, "connect", item.ID},
+		Env:   append(os.Environ(), "WERKROOM_ITEM="+string(request)),
+	})
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// REMOTE PREVIEW
+// =============================================================================
+
+const (
+	// Default for preview.command
+	defaultPreviewCommand = "uptime && df -h"
+	// Wait for the cursor to settle before running the command
+	previewDelay = 500 * time.Millisecond
+	// Rerun the command when the highlighted output is older than this
+	previewMaxAge = time.Minute
+	// Output lines shown in the pane
+	previewMaxLines = 30
+)
+
+// PreviewConfig tunes the remote preview pane
+type PreviewConfig struct {
+	// Shell command run on the highlighted VM, default "uptime && df -h"
+	Command string `yaml:"command"`
+	// Run the command whenever the cursor rests on a RUNNING VM instead of
+	// waiting for r; never in read-only mode or on production instances
+	Auto bool `yaml:"auto"`
+}
+
+// command returns the configured preview command
+func (c PreviewConfig) command() string {
+	if strings.TrimSpace(c.Command) == "" {
+		return defaultPreviewCommand
+	}
+	return c.Command
+}
+
+// previewResult is the output of one preview run
+type previewResult struct {
+	Output string
+	Err    error
+	At     time.Time
+}
+
+// PreviewTickMsg fires once the cursor has rested on an instance
+type PreviewTickMsg struct {
+	Key string
+	Seq int
+}
+
+// PreviewLoadedMsg carries the output of the preview command
+type PreviewLoadedMsg struct {
+	Key    string
+	Output string
+	Err    error
+}
+
+// togglePreview shows or hides the remote preview pane
+func (m model) togglePreview() (tea.Model, tea.Cmd) {
+	if m.gcpService.native && !m.showPreview {
+		m.statusMsg = "The remote preview needs gcloud"
+		return m, nil
+	}
+	m.showPreview = !m.showPreview
+	m.resizeList()
+	if !m.showPreview {
+		return m, nil
+	}
+	return m, m.schedulePreview()
+}
+
+// previewTarget returns the highlighted instance if it can be previewed
+func (m model) previewTarget() (VM, bool) {
+	node := m.getCurrentNode()
+	if node == nil || node.Type != InstanceNode || node.VM == nil {
+		return VM{}, false
+	}
+	if VMStatus(node.VM.Status) != StatusRunning {
+		return VM{}, false
+	}
+	return *node.VM, true
+}
+
+// schedulePreview runs the preview for the highlighted instance once the
+// cursor stops moving, if preview.auto is on and its output isn't fresh or
+// already on the way. Connecting unasked is left out in read-only mode and
+// on production, where it would skip the confirmation.
+func (m *model) schedulePreview() tea.Cmd {
+	if !m.showPreview || m.onSource() || !m.config.Preview.Auto || m.config.ReadOnly {
+		return nil
+	}
+	vm, ok := m.previewTarget()
+	if !ok || m.isProduction(m.getCurrentNode(), false) {
+		return nil
+	}
+	key := markKey(vm)
+	if result, ok := m.previews[key]; ok && time.Since(result.At) < previewMaxAge {
+		return nil
+	}
+	if m.previewPending[key] {
+		return nil
+	}
+	m.previewSeq++
+	seq := m.previewSeq
+	return tea.Tick(previewDelay, func(time.Time) tea.Msg {
+		return PreviewTickMsg{Key: key, Seq: seq}
+	})
+}
+
+// handlePreviewTick starts the command if the cursor is still on the same instance
+func (m model) handlePreviewTick(msg PreviewTickMsg) (tea.Model, tea.Cmd) {
+	if msg.Seq != m.previewSeq || !m.showPreview {
+		return m, nil
+	}
+	vm, ok := m.previewTarget()
+	if !ok || markKey(vm) != msg.Key {
+		return m, nil
+	}
+	return m.runPreview(vm)
+}
+
+// refreshPreview runs the preview command on the highlighted instance, on r
+func (m model) refreshPreview() (tea.Model, tea.Cmd) {
+	vm, ok := m.previewTarget()
+	if !ok {
+		m.statusMsg = "Preview needs a RUNNING instance"
+		return m, nil
+	}
+	if m.previewPending[markKey(vm)] {
+		return m, nil
+	}
+	return m.guard(EffectConnect, "run the preview command", m.getCurrentNode(), func(m model) (tea.Model, tea.Cmd) {
+		return m.runPreview(vm)
+	})
+}
+
+// runPreview marks the instance's preview as running and starts the command
+func (m model) runPreview(vm VM) (tea.Model, tea.Cmd) {
+	key := markKey(vm)
+	pending := make(map[string]bool, len(m.previewPending)+1)
+	for k, v := range m.previewPending {
+		pending[k] = v
+	}
+	pending[key] = true
+	m.previewPending = pending
+	return m, m.gcpService.RunPreview(m.selectedProject, vm, m.config.Preview.command())
+}
+
+// handlePreviewLoaded stores the output for the pane
+func (m model) handlePreviewLoaded(msg PreviewLoadedMsg) (tea.Model, tea.Cmd) {
+	pending := make(map[string]bool, len(m.previewPending))
+	for k, v := range m.previewPending {
+		if k != msg.Key {
+			pending[k] = v
+		}
+	}
+	m.previewPending = pending
+
+	previews := make(map[string]previewResult, len(m.previews)+1)
+	for k, v := range m.previews {
+		previews[k] = v
+	}
+	previews[msg.Key] = previewResult{Output: msg.Output, Err: msg.Err, At: time.Now()}
+	m.previews = previews
+	return m, nil
+}
+
+// RunPreview runs a short non-interactive command on the VM over SSH
+func (gcp *GCPService) RunPreview(project string, vm VM, command string) tea.Cmd {
+	return func() tea.Msg {
+		args := append(gcp.SSHArgs(project, vm)[1:],
+			"--command", command,
+			// Never stop to ask for a passphrase or host key inside the TUI
+			"--ssh-flag=-T", "--ssh-flag=-oBatchMode=yes", "--ssh-flag=-oConnectTimeout=10")
+		output, err := gcp.runGcloud(args...)
+		if err != nil {
+			err = fmt.Errorf("failed to run preview command: %w", err)
+		}
+		return PreviewLoadedMsg{Key: markKey(vm), Output: string(output), Err: err}
+	}
+}
+
+// renderPreview returns the preview pane for the highlighted node
+func (m model) renderPreview(node *TreeNode) string {
+	command := m.config.Preview.command()
+	lines := []string{m.styles.Label.Render("$ " + command)}
+	width := m.width - m.width*DetailsSplitPercent/100 - 6
+
+	vm, ok := m.previewTarget()
+	switch {
+	case node == nil || node.Type != InstanceNode:
+		lines = append(lines, m.styles.Label.Render("Highlight an instance to preview it"))
+	case !ok:
+		lines = append(lines, m.styles.Label.Render(fmt.Sprintf("Preview needs a RUNNING instance; %s is %s", node.Name, node.VM.Status)))
+	default:
+		key := markKey(vm)
+		result, done := m.previews[key]
+		if m.previewPending[key] {
+			lines = append(lines, m.styles.Label.Render(fmt.Sprintf("Running on %s…", vm.Name)))
+		} else if !done {
+			lines = append(lines, m.styles.Label.Render(fmt.Sprintf("Press r to run it on %s", vm.Name)))
+		}
+		if done {
+			if result.Err != nil {
+				lines = append(lines, m.styles.Stopping.Render(clipLine(result.Err.Error(), width)))
+			}
+			output := strings.Split(strings.TrimRight(result.Output, "\n"), "\n")
+			if len(output) > previewMaxLines {
+				output = append(output[:previewMaxLines], fmt.Sprintf("… %d more lines", len(output)-previewMaxLines))
+			}
+			for _, line := range output {
+				lines = append(lines, clipLine(strings.ReplaceAll(line, "\t", "    "), width))
+			}
+			lines = append(lines, m.styles.Label.Render(fmt.Sprintf("%s ago, r to rerun", formatAge(time.Since(result.At)))))
+		}
+	}
+	return m.styles.Details.Render(strings.Join(lines, "\n"))
+}
+
+// clipLine cuts a line of command output to the pane width
+func clipLine(line string, width int) string {
+	runes := []rune(line)
+	if width <= 1 || len(runes) <= width {
+		return line
+	}
+	return string(runes[:width-1]) + "…"
+}
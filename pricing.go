@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// COST ESTIMATES
+// =============================================================================
+
+// Hours billed in an average month
+const hoursPerMonth = 730
+
+// familyRate is the on-demand hourly price per vCPU and per GB of memory
+type familyRate struct {
+	VCPU   float64
+	Memory float64
+}
+
+// Approximate us-central1 on-demand rates in USD. Good enough to spot the
+// expensive machines, not a substitute for the billing report.
+var familyRates = map[string]familyRate{
+	"e2":  {VCPU: 0.021811, Memory: 0.002923},
+	"n1":  {VCPU: 0.031611, Memory: 0.004237},
+	"n2":  {VCPU: 0.031611, Memory: 0.004237},
+	"n2d": {VCPU: 0.027502, Memory: 0.003686},
+	"n4":  {VCPU: 0.030891, Memory: 0.004143},
+	"t2d": {VCPU: 0.027502, Memory: 0.003686},
+	"t2a": {VCPU: 0.0264, Memory: 0.0033},
+	"c2":  {VCPU: 0.03398, Memory: 0.00455},
+	"c2d": {VCPU: 0.029563, Memory: 0.003959},
+	"c3":  {VCPU: 0.03465, Memory: 0.003938},
+	"c3d": {VCPU: 0.029563, Memory: 0.003959},
+	"c4":  {VCPU: 0.03465, Memory: 0.003938},
+	"m1":  {VCPU: 0.0348, Memory: 0.0051},
+	"m3":  {VCPU: 0.0356, Memory: 0.00477},
+}
+
+// Monthly prices of shared-core machine types, which don't follow the family rates
+var sharedCorePrices = map[string]float64{
+	"e2-micro":  6.11,
+	"e2-small":  12.23,
+	"e2-medium": 24.46,
+	"f1-micro":  3.88,
+	"g1-small":  13.23,
+}
+
+// Memory per vCPU in GB for predefined shapes; n1 uses its own ratios
+var shapeMemory = map[string]float64{
+	"standard": 4,
+	"highmem":  8,
+	"highcpu":  1,
+	"megamem":  14.9,
+	"ultramem": 24,
+}
+
+var n1ShapeMemory = map[string]float64{
+	"standard": 3.75,
+	"highmem":  6.5,
+	"highcpu":  0.9,
+}
+
+// MachineTypeName returns the short machine type name from its URL
+func (vm VM) MachineTypeName() string {
+	parts := strings.Split(vm.MachineType, "/")
+	return parts[len(parts)-1]
+}
+
+// machineShape returns the vCPU count and memory in GB of a machine type
+func machineShape(machineType string) (family string, vcpus, memoryGB float64, ok bool) {
+	parts := strings.Split(machineType, "-")
+
+	// Custom types: custom-CPUS-MB (n1) or FAMILY-custom-CPUS-MB
+	if len(parts) >= 3 && (parts[0] == "custom" || parts[1] == "custom") {
+		family = "n1"
+		if parts[0] != "custom" {
+			family, parts = parts[0], parts[1:]
+		}
+		cpus, errCPU := strconv.ParseFloat(parts[1], 64)
+		memMB, errMem := strconv.ParseFloat(parts[2], 64)
+		if errCPU != nil || errMem != nil {
+			return "", 0, 0, false
+		}
+		return family, cpus, memMB / 1024, true
+	}
+
+	if len(parts) != 3 {
+		return "", 0, 0, false
+	}
+	family, shape := parts[0], parts[1]
+	cpus, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return "", 0, 0, false
+	}
+
+	ratios := shapeMemory
+	if family == "n1" {
+		ratios = n1ShapeMemory
+	}
+	ratio, known := ratios[shape]
+	if !known {
+		return "", 0, 0, false
+	}
+	return family, cpus, cpus * ratio, true
+}
+
+// estimateMonthlyCost returns the approximate monthly on-demand price of a machine type
+func estimateMonthlyCost(machineType string) (float64, bool) {
+	if price, ok := sharedCorePrices[machineType]; ok {
+		return price, true
+	}
+
+	family, vcpus, memoryGB, ok := machineShape(machineType)
+	if !ok {
+		return 0, false
+	}
+	rate, ok := familyRates[family]
+	if !ok {
+		return 0, false
+	}
+	return (vcpus*rate.VCPU + memoryGB*rate.Memory) * hoursPerMonth, true
+}
+
+// MonthlyCost returns the estimated monthly cost of a VM; stopped and suspended VMs cost nothing to run
+func (vm VM) MonthlyCost() (float64, bool) {
+	switch VMStatus(vm.Status) {
+	case StatusTerminated, StatusSuspended:
+		return 0, true
+	}
+	return estimateMonthlyCost(vm.MachineTypeName())
+}
+
+// formatCost renders a monthly cost estimate
+func formatCost(cost float64) string {
+	return fmt.Sprintf("~$%.0f/mo", cost)
+}
+
+// groupCost sums the estimates of a group's instances; ok is false if any is unknown
+func groupCost(group *TreeNode) (total float64, ok bool) {
+	ok = true
+	for _, child := range group.Children {
+		if child.VM == nil {
+			continue
+		}
+		cost, known := child.VM.MonthlyCost()
+		if !known {
+			ok = false
+			continue
+		}
+		total += cost
+	}
+	return total, ok
+}
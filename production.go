@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// PRODUCTION GUARD RAILS
+// =============================================================================
+
+// Effect classifies what an action does, for guard rails
+type Effect int
+
+const (
+	EffectRead Effect = iota
+	EffectConnect
+	EffectMutate
+	EffectDestroy
+)
+
+// isProductionProject reports whether the project matches a production pattern
+func (c ProductionConfig) isProductionProject(project string) bool {
+	for _, pattern := range c.Projects {
+		if matched, _ := path.Match(pattern, project); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isProductionVM reports whether the VM carries a production label value
+func (c ProductionConfig) isProductionVM(vm VM) bool {
+	for key, values := range c.Labels {
+		for _, value := range values {
+			if vm.Labels[key] == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isProduction reports whether the node, or any instance it stands for, is production.
+// With bulk set, the marked instances an action would apply to are checked too.
+func (m model) isProduction(node *TreeNode, bulk bool) bool {
+	production := m.config.Production
+	if production.isProductionProject(m.selectedProject) {
+		return true
+	}
+	if node == nil {
+		return false
+	}
+
+	var vms []VM
+	switch node.Type {
+	case InstanceNode:
+		vms = []VM{*node.VM}
+		if bulk {
+			vms = m.targetVMs(node)
+		}
+	case GroupNode:
+		for _, child := range node.Children {
+			if child.VM != nil {
+				vms = append(vms, *child.VM)
+			}
+		}
+	}
+	for _, vm := range vms {
+		if production.isProductionVM(vm) {
+			return true
+		}
+	}
+	return false
+}
+
+// isProductionVMNode reports whether an instance row should carry the production badge
+func (m model) isProductionVMNode(node *TreeNode) bool {
+	return node.Type == InstanceNode && m.config.Production.isProductionVM(*node.VM)
+}
+
+// guard runs next directly, or after an extra confirmation for production resources
+func (m model) guard(effect Effect, description string, node *TreeNode, next func(m model) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	if effect == EffectRead || !m.isProduction(node, effect != EffectConnect) {
+		return next(m)
+	}
+
+	if effect == EffectDestroy && m.config.Production.DisableDestructive {
+		m.statusMsg = fmt.Sprintf("'%s' is disabled for production resources", description)
+		return m, nil
+	}
+
+	prompt := fmt.Sprintf("%s is production. Really %s?", node.Name, description)
+	return m.askConfirm(prompt, next)
+}
+
+// runAction runs an action behind the production guard
+func (m model) runAction(action Action, node *TreeNode) (tea.Model, tea.Cmd) {
+	return m.guard(action.Effect, action.Label, node, func(m model) (tea.Model, tea.Cmd) {
+		return action.Run(m, node)
+	})
+}
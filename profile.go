@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// =============================================================================
+// PROFILING
+// =============================================================================
+
+// Flags left out of -help; they exist for capturing bug reports
+var hiddenFlags = map[string]bool{
+	"cpuprofile": true,
+	"memprofile": true,
+}
+
+// usage prints the flag defaults without the hidden flags
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(out)
+	flag.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	visible.PrintDefaults()
+	fmt.Fprintln(out, "\nCommands:")
+	fmt.Fprintln(out, "  config init\tWrite a config file by answering a few questions")
+	fmt.Fprintln(out, "  config check\tReport problems in the config file and print the effective configuration")
+	fmt.Fprintln(out, "  update\tReplace this binary with the latest release (-check to only look)")
+}
+
+// startProfiling begins a CPU profile and returns a func that finishes it and
+// writes the heap profile; either path may be empty
+func startProfiling(cpuPath, memPath string) (func(), error) {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuFile = f
+	}
+
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if memPath == "" {
+			return
+		}
+		f, err := os.Create(memPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "werkroom: failed to create memory profile: %v\n", err)
+			return
+		}
+		defer f.Close()
+		// Up-to-date statistics of what is still live
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "werkroom: failed to write memory profile: %v\n", err)
+		}
+	}, nil
+}
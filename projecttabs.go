@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// PROJECT TABS
+// =============================================================================
+
+// Projects open at once, one per number key
+const maxProjectTabs = 9
+
+// projectTab is an open project along with the tree it showed when left
+type projectTab struct {
+	Project string
+	Kind    ResourceKind
+	// Nil until the project's listing has been shown
+	Nodes       []*TreeNode
+	VMs         []VM
+	GroupHealth map[string]GroupHealth
+	Filtering   bool
+	FilterText  string
+	Cursor      int
+	Marked      map[string]bool
+	// Data loaded along with the VMs, keyed zone/name
+	Recommendations map[string]Recommendation
+	Reachability    map[string]Reachability
+	Metrics         map[string]InstanceMetrics
+	OpsAgent        map[string]OpsAgentStatus
+	Patches         map[string]PatchState
+	Findings        map[string][]Finding
+}
+
+// tabIndex returns the tab of a project, or -1
+func (m model) tabIndex(project string) int {
+	return slices.IndexFunc(m.tabs, func(tab projectTab) bool { return tab.Project == project })
+}
+
+// openTab makes the selected project the active tab, adding one if needed.
+// With all tabs taken, the active one is reused.
+func (m *model) openTab() {
+	if m.onSource() || m.selectedProject == "" {
+		return
+	}
+	if i := m.tabIndex(m.selectedProject); i >= 0 {
+		m.activeTab = i
+		return
+	}
+	tab := projectTab{Project: m.selectedProject, Kind: m.resourceKind}
+	// Copy on write: the slice is shared with earlier model values
+	tabs := slices.Clone(m.tabs)
+	if len(tabs) < maxProjectTabs {
+		tabs = append(tabs, tab)
+		m.activeTab = len(tabs) - 1
+	} else {
+		tabs[m.activeTab] = tab
+	}
+	m.tabs = tabs
+}
+
+// saveTab records the tree on screen in the active tab
+func (m *model) saveTab() {
+	if m.state != StateSelectingVM || m.onSource() || m.activeTab >= len(m.tabs) || m.tabs[m.activeTab].Project != m.selectedProject {
+		return
+	}
+	tabs := slices.Clone(m.tabs)
+	tabs[m.activeTab] = projectTab{
+		Project:         m.selectedProject,
+		Kind:            m.resourceKind,
+		Nodes:           m.treeManager.GetNodes(),
+		VMs:             m.treeManager.vms,
+		GroupHealth:     m.treeManager.groupHealth,
+		Filtering:       m.filtering,
+		FilterText:      m.filterText,
+		Cursor:          m.list.Index(),
+		Marked:          m.marked,
+		Recommendations: m.recommendations,
+		Reachability:    m.reachability,
+		Metrics:         m.metrics,
+		OpsAgent:        m.opsAgent,
+		Patches:         m.patches,
+		Findings:        m.findings,
+	}
+	m.tabs = tabs
+}
+
+// switchTab shows the tab at index i as it was left, loading it if it never was
+func (m model) switchTab(i int) (tea.Model, tea.Cmd) {
+	if m.onSource() || i < 0 || i >= len(m.tabs) {
+		return m, nil
+	}
+	if m.state == StateSelectingVM && m.tabs[i].Project == m.selectedProject {
+		return m, nil
+	}
+	m.saveTab()
+	tab := m.tabs[i]
+	m.activeTab = i
+	m.selectedProject = tab.Project
+	m.resourceKind = tab.Kind
+	m.resetSelection()
+	if tab.Nodes == nil {
+		m.treeManager.setNodes(nil)
+		return m.loadResources()
+	}
+
+	m.treeManager.vms = tab.VMs
+	m.treeManager.setNodes(tab.Nodes)
+	m.treeManager.groupHealth = tab.GroupHealth
+	m.marked = tab.Marked
+	m.recommendations = tab.Recommendations
+	m.reachability = tab.Reachability
+	m.metrics = tab.Metrics
+	m.opsAgent = tab.OpsAgent
+	m.patches = tab.Patches
+	m.findings = tab.Findings
+	m.filtering = tab.Filtering
+	m.filterText = tab.FilterText
+	m.state = StateSelectingVM
+	m.updateVMList()
+	m.list.Select(tab.Cursor)
+	m.resizeList()
+	return m, nil
+}
+
+// closeTab closes the active tab and shows a neighbor, or the project list after the last one
+func (m model) closeTab() (tea.Model, tea.Cmd) {
+	if m.onSource() || m.activeTab >= len(m.tabs) {
+		return m, nil
+	}
+	closed := m.tabs[m.activeTab].Project
+	m.tabs = slices.Delete(slices.Clone(m.tabs), m.activeTab, m.activeTab+1)
+	if len(m.tabs) == 0 {
+		m.activeTab = 0
+		return m.goBackToProjectSelection()
+	}
+	// The closed tab's tree is dropped rather than saved, as its project no longer has a tab
+	result, cmd := m.switchTab(min(m.activeTab, len(m.tabs)-1))
+	switched := result.(model)
+	switched.statusMsg = fmt.Sprintf("Closed %s", closed)
+	return switched, cmd
+}
+
+// handleTabKey switches tabs with 1-9 and ctrl+tab, and closes them with ctrl+w
+func (m model) handleTabKey(keypress string) (tea.Model, tea.Cmd, bool) {
+	if len(m.tabs) == 0 {
+		return m, nil, false
+	}
+	switch keypress {
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		next, cmd := m.switchTab(int(keypress[0] - '1'))
+		return next, cmd, true
+	case "ctrl+tab":
+		next, cmd := m.switchTab((m.activeTab + 1) % len(m.tabs))
+		return next, cmd, true
+	case "ctrl+w":
+		if m.state != StateSelectingVM {
+			return m, nil, false
+		}
+		next, cmd := m.closeTab()
+		return next, cmd, true
+	}
+	return m, nil, false
+}
+
+// renderProjectTabs draws the tab bar of open projects
+func (m model) renderProjectTabs() string {
+	tabs := make([]string, len(m.tabs))
+	for i, tab := range m.tabs {
+		label := fmt.Sprintf("%d %s", i+1, tab.Project)
+		if i == m.activeTab && m.state == StateSelectingVM {
+			tabs[i] = m.styles.Prompt.Render("[" + label + "]")
+		} else {
+			tabs[i] = m.styles.Label.Render(" " + label + " ")
+		}
+	}
+	return strings.Join(tabs, " ") + "  " + m.styles.Label.Render("1-9 to switch, ctrl+w to close")
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// RECOMMENDATIONS
+// =============================================================================
+
+// Recommenders queried for every zone that has instances
+var instanceRecommenders = []string{
+	"google.compute.instance.IdleResourceRecommender",
+	"google.compute.instance.MachineTypeRecommender",
+}
+
+// Recommendation is an active Recommender suggestion for a single instance
+type Recommendation struct {
+	Subtype     string
+	Description string
+	// Projected monthly savings in the billing currency, 0 if unknown
+	Savings  float64
+	Currency string
+}
+
+// Badge returns the short list badge for the recommendation
+func (r Recommendation) Badge() string {
+	if r.Subtype == "CHANGE_MACHINE_TYPE" {
+		return "oversized"
+	}
+	return "idle"
+}
+
+// RecommendationsLoadedMsg carries recommendations keyed like marks (zone/name)
+type RecommendationsLoadedMsg struct {
+	Project         string
+	Recommendations map[string]Recommendation
+	Err             error
+}
+
+// recommenderItem is the subset of a gcloud recommendation we use
+type recommenderItem struct {
+	Description        string `json:"description"`
+	RecommenderSubtype string `json:"recommenderSubtype"`
+	Content            struct {
+		Overview struct {
+			ResourceName string `json:"resourceName"`
+		} `json:"overview"`
+	} `json:"content"`
+	PrimaryImpact struct {
+		CostProjection struct {
+			Cost struct {
+				CurrencyCode string `json:"currencyCode"`
+				Units        string `json:"units"`
+				Nanos        int64  `json:"nanos"`
+			} `json:"cost"`
+			Duration string `json:"duration"`
+		} `json:"costProjection"`
+	} `json:"primaryImpact"`
+	StateInfo struct {
+		State string `json:"state"`
+	} `json:"stateInfo"`
+}
+
+// monthlySavings normalizes the projected cost change to a positive 30-day amount
+func (item recommenderItem) monthlySavings() float64 {
+	projection := item.PrimaryImpact.CostProjection
+	units, err := strconv.ParseFloat(projection.Cost.Units, 64)
+	if err != nil {
+		return 0
+	}
+	cost := units + float64(projection.Cost.Nanos)/1e9
+
+	if seconds, err := strconv.ParseFloat(strings.TrimSuffix(projection.Duration, "s"), 64); err == nil && seconds > 0 {
+		cost = cost * (30 * 24 * 3600) / seconds
+	}
+	if cost < 0 {
+		cost = -cost
+	}
+	return cost
+}
+
+// recommendationZones returns the distinct zones of the given VMs
+func recommendationZones(vms []VM) []string {
+	seen := make(map[string]bool)
+	var zones []string
+	for _, vm := range vms {
+		if zone := vm.ZoneName(); zone != "" && !seen[zone] {
+			seen[zone] = true
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// LoadRecommendations fetches idle and machine type recommendations per zone
+func (gcp *GCPService) LoadRecommendations(project string, zones []string) tea.Cmd {
+	return func() tea.Msg {
+		type keyed struct {
+			key string
+			rec Recommendation
+		}
+
+		found, err := fanOut(zones, func(zone string) ([]keyed, error) {
+			var recs []keyed
+			for _, recommender := range instanceRecommenders {
+				output, err := gcp.runGcloud("recommender", "recommendations", "list",
+					"--project", project,
+					"--location", zone,
+					"--recommender", recommender,
+					"--format", "json")
+				if err != nil {
+					return recs, fmt.Errorf("failed to list recommendations in %s: %w", zone, err)
+				}
+
+				var items []recommenderItem
+				if err := json.Unmarshal(output, &items); err != nil {
+					return recs, fmt.Errorf("failed to parse recommendations: %w", err)
+				}
+				for _, item := range items {
+					if item.StateInfo.State != "" && item.StateInfo.State != "ACTIVE" {
+						continue
+					}
+					// //compute.googleapis.com/projects/P/zones/Z/instances/NAME
+					parts := strings.Split(item.Content.Overview.ResourceName, "/")
+					recs = append(recs, keyed{
+						key: zone + "/" + parts[len(parts)-1],
+						rec: Recommendation{
+							Subtype:     item.RecommenderSubtype,
+							Description: item.Description,
+							Savings:     item.monthlySavings(),
+							Currency:    item.PrimaryImpact.CostProjection.Cost.CurrencyCode,
+						},
+					})
+				}
+			}
+			return recs, nil
+		})
+
+		recommendations := make(map[string]Recommendation, len(found))
+		for _, f := range found {
+			// Idle beats oversized: stopping saves more than resizing
+			if existing, ok := recommendations[f.key]; ok && existing.Badge() == "idle" {
+				continue
+			}
+			recommendations[f.key] = f.rec
+		}
+		return RecommendationsLoadedMsg{Project: project, Recommendations: recommendations, Err: err}
+	}
+}
+
+// handleRecommendationsLoaded stores recommendations for the current project
+func (m model) handleRecommendationsLoaded(msg RecommendationsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Project != m.selectedProject {
+		return m, nil
+	}
+	// Errors are expected when the Recommender API is disabled; the badges
+	// are advisory, so whatever did load is shown and the rest is dropped
+	m.recommendations = msg.Recommendations
+	m.updateVMList()
+	return m, nil
+}
+
+// recommendationFor returns the recommendation of an instance node, if any
+func (m model) recommendationFor(node *TreeNode) (Recommendation, bool) {
+	if node == nil || node.Type != InstanceNode {
+		return Recommendation{}, false
+	}
+	rec, ok := m.recommendations[markKey(*node.VM)]
+	return rec, ok
+}
+
+// formatSavings renders the projected savings of a recommendation
+func (r Recommendation) formatSavings() string {
+	if r.Savings <= 0 {
+		return ""
+	}
+	if r.Currency == "" || r.Currency == "USD" {
+		return fmt.Sprintf("saves ~$%.0f/mo", r.Savings)
+	}
+	return fmt.Sprintf("saves ~%.0f %s/mo", r.Savings, r.Currency)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// SESSION RECORDING
+// =============================================================================
+
+// RecordingConfig turns on recording of interactive sessions
+type RecordingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// "asciinema" or "script"; default is asciinema when installed, else script
+	Tool string `yaml:"tool"`
+	// Default is recordings in the werkroom state directory
+	Dir string `yaml:"dir"`
+}
+
+// validate checks the tool name when the config is loaded
+func (c RecordingConfig) validate() error {
+	switch c.Tool {
+	case "", "asciinema", "script":
+		return nil
+	}
+	return fmt.Errorf("unknown tool %q, expected asciinema or script", c.Tool)
+}
+
+// tool returns the recorder to use
+func (c RecordingConfig) tool() string {
+	if c.Tool != "" {
+		return c.Tool
+	}
+	if _, err := exec.LookPath("asciinema"); err == nil {
+		return "asciinema"
+	}
+	return "script"
+}
+
+// recordingPath names the recording after the project or source, the target and the start time
+func (c RecordingConfig) recordingPath(scope, target string, started time.Time) (string, error) {
+	dir := expandHome(c.Dir)
+	if dir == "" {
+		state, err := stateDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(state, "recordings")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	ext := ".log"
+	if c.tool() == "asciinema" {
+		ext = ".cast"
+	}
+	name := strings.Join([]string{fileSafe(scope), fileSafe(target), started.Format("20060102-150405")}, "_")
+	return filepath.Join(dir, name+ext), nil
+}
+
+// fileSafe replaces characters that don't belong in a file name
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' || r < ' ' {
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// recordArgs wraps a session command in the recorder, writing to path
+func (c RecordingConfig) recordArgs(args []string, path string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	command := strings.Join(quoted, " ")
+
+	switch {
+	case c.tool() == "asciinema":
+		return []string{"asciinema", "rec", "--quiet", "-c", command, path}
+	case runtime.GOOS == "darwin" || runtime.GOOS == "freebsd":
+		// BSD script takes the command as trailing arguments
+		return append([]string{"script", "-q", path}, args...)
+	default:
+		return []string{"script", "-q", "-f", "-e", "-c", command, path}
+	}
+}
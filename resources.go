@@ -0,0 +1,550 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// RESOURCE TYPES
+// =============================================================================
+
+// ResourceKind identifies which kind of resource the tree lists
+type ResourceKind int
+
+const (
+	KindInstances ResourceKind = iota
+	KindGKEClusters
+	KindCloudSQL
+	KindDataproc
+	KindTPUs
+	KindWorkbench
+	KindVSphere
+	KindTailscale
+	KindSSHConfig
+	KindAnsible
+	KindInventory
+	KindStdin
+	KindPlugin
+	KindKubernetes
+	KindDocker
+	KindInstanceTemplates
+)
+
+// ResourceType describes how a kind of resource is listed and connected to
+type ResourceType struct {
+	Kind     ResourceKind
+	Plural   string
+	Singular string
+	// Inventory outside GCP selected with -source; such types have no project
+	// and load with LoadSource instead of Load
+	Source     string
+	LoadSource func(config Config) tea.Cmd
+	Load       func(gcp *GCPService, project string) tea.Cmd
+	Connect    func(m model, node *TreeNode) (tea.Model, tea.Cmd)
+	ConsoleURL func(project string, r Resource) string
+	ListPath   string
+}
+
+// ListURL returns the Cloud Console page listing this resource type
+func (rt ResourceType) ListURL(project string) string {
+	return consoleURL(rt.ListPath, project)
+}
+
+// resourceTypes returns the resource types in toggle order
+func resourceTypes() []ResourceType {
+	return []ResourceType{
+		{
+			Kind:     KindInstances,
+			Plural:   "VMs",
+			Singular: "VM",
+			Load:     (*GCPService).LoadVMs,
+			Connect: func(m model, node *TreeNode) (tea.Model, tea.Cmd) {
+				return m.connectToVM(node.VM)
+			},
+			ListPath: "compute/instances",
+		},
+		{
+			Kind:     KindGKEClusters,
+			Plural:   "GKE clusters",
+			Singular: "GKE cluster",
+			Load:     (*GCPService).LoadGKEClusters,
+			Connect:  model.fetchClusterCredentials,
+			ConsoleURL: func(project string, r Resource) string {
+				return consoleURL(fmt.Sprintf("kubernetes/clusters/details/%s/%s/details", r.Location, r.Name), project)
+			},
+			ListPath: "kubernetes/list/overview",
+		},
+		{
+			Kind:     KindCloudSQL,
+			Plural:   "Cloud SQL instances",
+			Singular: "Cloud SQL instance",
+			Load:     (*GCPService).LoadCloudSQLInstances,
+			Connect:  model.startCloudSQLProxy,
+			ConsoleURL: func(project string, r Resource) string {
+				return consoleURL(fmt.Sprintf("sql/instances/%s/overview", r.Name), project)
+			},
+			ListPath: "sql/instances",
+		},
+		{
+			Kind:     KindDataproc,
+			Plural:   "Dataproc clusters",
+			Singular: "Dataproc cluster",
+			Load:     (*GCPService).LoadDataprocClusters,
+			Connect:  model.connectToDataprocMaster,
+			ConsoleURL: func(project string, r Resource) string {
+				return consoleURL(fmt.Sprintf("dataproc/clusters/%s/monitoring", r.Name), project) + "&region=" + r.Location
+			},
+			ListPath: "dataproc/clusters",
+		},
+		{
+			Kind:     KindTPUs,
+			Plural:   "TPU VMs",
+			Singular: "TPU VM",
+			Load:     (*GCPService).LoadTPUs,
+			Connect:  model.connectToTPU,
+			ListPath: "compute/tpus",
+		},
+		{
+			Kind:     KindWorkbench,
+			Plural:   "Workbench instances",
+			Singular: "Workbench instance",
+			Load:     (*GCPService).LoadWorkbenchInstances,
+			Connect:  model.openJupyterLab,
+			ListPath: "vertex-ai/workbench/instances",
+		},
+		{
+			Kind:     KindInstanceTemplates,
+			Plural:   "instance templates",
+			Singular: "instance template",
+			Load:     (*GCPService).LoadInstanceTemplates,
+			Connect:  model.createFromTemplate,
+			ListPath: "compute/instanceTemplates/list",
+		},
+		{
+			Kind:       KindVSphere,
+			Plural:     "vSphere VMs",
+			Singular:   "vSphere VM",
+			Source:     "vsphere",
+			LoadSource: LoadVSphereVMs,
+			Connect:    model.connectToVSphereVM,
+		},
+		{
+			Kind:       KindTailscale,
+			Plural:     "Tailscale devices",
+			Singular:   "Tailscale device",
+			Source:     "tailscale",
+			LoadSource: LoadTailscaleDevices,
+			Connect:    model.connectToTailscaleDevice,
+		},
+		{
+			Kind:       KindSSHConfig,
+			Plural:     "SSH hosts",
+			Singular:   "SSH host",
+			Source:     "ssh-config",
+			LoadSource: LoadSSHConfigHosts,
+			Connect:    model.connectToSSHConfigHost,
+		},
+		{
+			Kind:       KindAnsible,
+			Plural:     "Ansible hosts",
+			Singular:   "Ansible host",
+			Source:     "ansible",
+			LoadSource: LoadAnsibleHosts,
+			Connect:    model.connectToAnsibleHost,
+		},
+		{
+			Kind:       KindInventory,
+			Plural:     "hosts",
+			Singular:   "host",
+			Source:     "inventory",
+			LoadSource: LoadInventoryHosts,
+			Connect:    model.connectToInventoryHost,
+		},
+		{
+			Kind:       KindStdin,
+			Plural:     "items",
+			Singular:   "item",
+			Source:     "stdin",
+			LoadSource: LoadStdinItems,
+			Connect:    model.pickStdinItem,
+		},
+		{
+			Kind:       KindKubernetes,
+			Plural:     "pods",
+			Singular:   "pod",
+			Source:     "kubernetes",
+			LoadSource: LoadKubernetesPods,
+			Connect:    model.execIntoPod,
+		},
+		{
+			Kind:       KindDocker,
+			Plural:     "containers",
+			Singular:   "container",
+			Source:     "docker",
+			LoadSource: LoadDockerContainers,
+			Connect:    model.execIntoContainer,
+		},
+		{
+			// Stands for every werkroom-provider-* plugin; the name is config.Source
+			Kind:       KindPlugin,
+			Plural:     "items",
+			Singular:   "item",
+			Source:     "plugin",
+			LoadSource: LoadPluginItems,
+			Connect:    model.connectToPluginItem,
+		},
+	}
+}
+
+// resourceType returns the descriptor for a kind
+func resourceType(kind ResourceKind) ResourceType {
+	for _, rt := range resourceTypes() {
+		if rt.Kind == kind {
+			return rt
+		}
+	}
+	return resourceTypes()[0]
+}
+
+// sourceType returns the resource type of a non-GCP source, built in or a plugin
+func sourceType(source string) (ResourceType, bool) {
+	for _, rt := range resourceTypes() {
+		if rt.Kind != KindPlugin && rt.Source != "" && rt.Source == source {
+			return rt, true
+		}
+	}
+	if _, ok := pluginPath(source); ok {
+		return resourceType(KindPlugin), true
+	}
+	return ResourceType{}, false
+}
+
+// sourceNames lists the sources accepted by -source
+func sourceNames() []string {
+	names := []string{"gcp"}
+	for _, rt := range resourceTypes() {
+		if rt.Kind != KindPlugin && rt.Source != "" {
+			names = append(names, rt.Source)
+		}
+	}
+	return append(names, pluginNames()...)
+}
+
+// onSource reports whether the tree lists a non-GCP source rather than a project
+func (m model) onSource() bool {
+	return resourceType(m.resourceKind).Source != ""
+}
+
+// listsInstances reports whether the tree lists GCP instances, the only
+// listing the instance display toggles rebuild
+func (m model) listsInstances() bool {
+	return m.resourceKind == KindInstances && !m.onSource()
+}
+
+// sourceTimeout bounds a source's listing call, defaulting like gcloud calls
+func sourceTimeout(config Config) time.Duration {
+	if config.Timeout > 0 {
+		return config.Timeout
+	}
+	return defaultGcloudTimeout
+}
+
+// runSourceCommand runs a source's CLI and returns its stdout, with stderr folded into errors
+func runSourceCommand(config Config, name string, args ...string) (output []byte, err error) {
+	started := time.Now()
+	defer func() {
+		debugf("%s %s: %d bytes in %s, err=%v", name, strings.Join(args, " "), len(output), time.Since(started).Round(time.Millisecond), err)
+	}()
+
+	timeout := sourceTimeout(config)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err = cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s %w after %s", name, errCommandTimeout, timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// Resource is a non-VM item listed in the tree
+type Resource struct {
+	Kind     ResourceKind
+	Name     string
+	Location string
+	Status   string
+	Group    string
+	Fields   []ResourceField
+}
+
+// ResourceField is an extra label/value pair shown in the details pane
+type ResourceField struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Field returns the value of an extra field, or "" if it is not set
+func (r Resource) Field(label string) string {
+	for _, field := range r.Fields {
+		if field.Label == label {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// ResourcesLoadedMsg indicates non-VM resources have been loaded
+type ResourcesLoadedMsg struct {
+	Kind ResourceKind
+	// Plugin that listed the resources, since all plugins share KindPlugin
+	Source string
+	// Project the resources belong to, empty for other sources
+	Project   string
+	Resources []Resource
+}
+
+// Bound on concurrent per-location gcloud calls
+const locationWorkers = 8
+
+// fanOut lists items for each location concurrently and merges the results.
+// The first error is returned alongside whatever the other locations produced.
+func fanOut[T any](locations []string, list func(location string) ([]T, error)) ([]T, error) {
+	var (
+		mu        sync.Mutex
+		resources []T
+		firstErr  error
+	)
+	forEachLocation(locations, locationWorkers, func(location string) {
+		found, err := list(location)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		resources = append(resources, found...)
+	})
+	return resources, firstErr
+}
+
+// forEachLocation calls visit for each location, at most workers at a time,
+// and returns once all calls have
+func forEachLocation(locations []string, workers int, visit func(location string)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+
+	for _, location := range locations {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			visit(location)
+		}(location)
+	}
+	wg.Wait()
+}
+
+// Launch is the command werkroom hands the terminal over to on exit
+type Launch struct {
+	Title string
+	Args  []string
+	Env   []string
+	// Runs the session in-process instead of executing Args
+	Run func() error
+	// Printed to stdout instead of connecting, for -stdin and -pick picks
+	Output string
+	// Set for picks, whose Output is printed even when empty
+	Pick bool
+}
+
+// BuildFromResources creates tree structure from a resource list, grouped by Group
+func (tm *TreeManager) BuildFromResources(resources []Resource) {
+	expanded := make(map[string]bool)
+	for _, node := range tm.nodes {
+		if node.Type == GroupNode && node.IsExpanded {
+			expanded[node.Name] = true
+		}
+	}
+
+	// The VMs of an earlier instance listing no longer match the tree
+	tm.vms = nil
+	groups := make(map[string][]*Resource)
+	var ungrouped []*Resource
+	for i := range resources {
+		resource := &resources[i]
+		if tm.exclude.Match(resource.Name) {
+			continue
+		}
+		if resource.Group != "" {
+			groups[resource.Group] = append(groups[resource.Group], resource)
+		} else {
+			ungrouped = append(ungrouped, resource)
+		}
+	}
+
+	var groupNames []string
+	for groupName := range groups {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+
+	var nodes []*TreeNode
+	for _, groupName := range groupNames {
+		groupNode := &TreeNode{
+			Type:       GroupNode,
+			Name:       groupName,
+			GroupName:  groupName,
+			IsExpanded: expanded[groupName],
+			Children:   make([]*TreeNode, 0),
+		}
+		for _, resource := range groups[groupName] {
+			groupNode.Children = append(groupNode.Children, &TreeNode{
+				Type:      ResourceNode,
+				Name:      resource.Name,
+				Resource:  resource,
+				GroupName: groupName,
+				Depth:     1,
+			})
+		}
+		nodes = append(nodes, groupNode)
+	}
+
+	for _, resource := range ungrouped {
+		nodes = append(nodes, &TreeNode{
+			Type:     ResourceNode,
+			Name:     resource.Name,
+			Resource: resource,
+		})
+	}
+
+	tm.setNodes(nodes)
+}
+
+// loadResources loads the currently selected resource kind for the project
+func (m model) loadResources() (tea.Model, tea.Cmd) {
+	m.openTab()
+	rt := resourceType(m.resourceKind)
+	m.state = StateLoadingVMs
+	m.list.Title = fmt.Sprintf("Loading %s...", rt.Plural)
+	spin := m.startSpinner()
+	return m, tea.Batch(m.loadCmd(), spin)
+}
+
+// loadCmd lists the current resource kind, retrying transient failures
+func (m model) loadCmd() tea.Cmd {
+	rt := resourceType(m.resourceKind)
+	if rt.Source != "" {
+		return withRetry(rt.Plural, rt.LoadSource(m.config))
+	}
+	project, load := m.selectedProject, rt.Load(m.gcpService.forLoad(), m.selectedProject)
+	return withRetry(rt.Plural, func() tea.Msg {
+		msg := load()
+		if loaded, ok := msg.(ResourcesLoadedMsg); ok {
+			loaded.Project = project
+			return loaded
+		}
+		return msg
+	})
+}
+
+// cycleResourceKind switches the tree to the next resource type of the project
+func (m model) cycleResourceKind() (tea.Model, tea.Cmd) {
+	if m.onSource() {
+		return m, nil
+	}
+	var types []ResourceType
+	for _, rt := range resourceTypes() {
+		if rt.Source == "" {
+			types = append(types, rt)
+		}
+	}
+	for i, rt := range types {
+		if rt.Kind == m.resourceKind {
+			m.resourceKind = types[(i+1)%len(types)].Kind
+			break
+		}
+	}
+
+	m.treeManager.setNodes(nil)
+	m.statusMsg = ""
+	return m.loadResources()
+}
+
+// handleResourcesLoaded shows freshly loaded resources
+func (m model) handleResourcesLoaded(msg ResourcesLoadedMsg) (tea.Model, tea.Cmd) {
+	debugf("loaded %d %s", len(msg.Resources), resourceType(msg.Kind).Plural)
+	if msg.Kind != m.resourceKind || msg.Source != "" && msg.Source != m.config.Source || !m.expectsListing() {
+		// The user switched types or cancelled while this was loading
+		return m, nil
+	}
+	if resourceType(msg.Kind).Source == "" && msg.Project != m.selectedProject {
+		// Listed for a project tab the user has since left
+		return m, nil
+	}
+
+	m.state = StateSelectingVM
+	m.resetFilter()
+	m.treeManager.BuildFromResources(msg.Resources)
+	m.updateVMList()
+	return m.notifySlowLoad(fmt.Sprintf("%d %s", len(msg.Resources), resourceType(msg.Kind).Plural))
+}
+
+// connectToVM hands the terminal to an SSH session on exit
+func (m model) connectToVM(vm *VM) (tea.Model, tea.Cmd) {
+	switch VMStatus(vm.Status) {
+	case StatusTerminated, StatusSuspended:
+		return m.offerStartAndConnect(*vm)
+	}
+	m.selectedVM = vm
+	if m.gcpService.native {
+		gcp, project, target := m.gcpService, m.selectedProject, *vm
+		return m.launchAndQuit(Launch{Title: vm.Name, Run: func() error {
+			return gcp.nativeSSH(project, target)
+		}})
+	}
+	if m.needsOSLoginCheck(*vm) {
+		m.statusMsg = fmt.Sprintf("Checking OS Login key for %s...", vm.Name)
+		return m, m.gcpService.CheckOSLoginKey(*vm, true)
+	}
+	if m.config.SSHProbe && m.sshReady != markKey(*vm) {
+		return m.waitForSSH(*vm, m.config.probeTimeout())
+	}
+	if m.config.ConnectMethod == ConnectET {
+		return m.connectWithET(vm)
+	}
+	args, env := withLatencyTracking(m.gcpService.SSHArgs(m.selectedProject, *vm), m.selectedProject, *vm)
+	launch := Launch{Title: vm.Name, Args: args}
+	if env != nil {
+		launch.Env = append(os.Environ(), env...)
+	}
+	return m.launchAndQuit(launch)
+}
+
+// launchAndQuit exits the TUI and runs the launch command in its place
+func (m model) launchAndQuit(launch Launch) (tea.Model, tea.Cmd) {
+	m.launch = &launch
+	m.state = StateReadyToConnect
+	return m, tea.Quit
+}
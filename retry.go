@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// RETRIES
+// =============================================================================
+
+// Listing calls are attempted this many times before the error screen
+const maxLoadAttempts = 4
+
+// Backoff before the second attempt; doubled for every further one
+const retryBaseDelay = time.Second
+
+// A command werkroom runs hit its own time limit. Waiting that long again is
+// unlikely to help, so it is never retried.
+var errCommandTimeout = errors.New("timed out")
+
+// Error fragments that indicate a failure worth retrying
+var transientMarkers = []string{
+	"timed out",
+	"i/o timeout",
+	"connection reset",
+	"connection refused",
+	"temporary failure",
+	"tls handshake",
+	"unexpected eof",
+	"rate limit",
+	"ratelimitexceeded",
+	"unavailable",
+	"internal error",
+	"backenderror",
+	"http 429",
+	"http 500",
+	"http 502",
+	"http 503",
+	"http 504",
+}
+
+// RetryScheduledMsg reports a failed attempt that will be retried after Delay
+type RetryScheduledMsg struct {
+	What    string
+	Attempt int // The attempt about to be made
+	Err     error
+	Delay   time.Duration
+	Next    tea.Cmd
+}
+
+// isTransient reports whether an error is likely to go away on its own
+func isTransient(err error) bool {
+	if errors.Is(err, errCommandTimeout) {
+		return false
+	}
+	text := strings.ToLower(err.Error())
+	for _, marker := range transientMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given attempt, with up to 50% jitter
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 2)
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// withRetry runs a loading command, turning transient ErrorMsgs into scheduled retries
+func withRetry(what string, load tea.Cmd) tea.Cmd {
+	return retryAttempt(what, 1, load)
+}
+
+// retryAttempt runs one attempt and schedules the next on a transient failure
+func retryAttempt(what string, attempt int, load tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := load()
+		failed, ok := msg.(ErrorMsg)
+		if !ok {
+			return msg
+		}
+		if attempt >= maxLoadAttempts || !isTransient(failed.Err) {
+			failed.Retry = withRetry(what, load)
+			return failed
+		}
+		debugf("%s failed on attempt %d, retrying: %v", what, attempt, failed.Err)
+		return RetryScheduledMsg{
+			What:    what,
+			Attempt: attempt + 1,
+			Err:     failed.Err,
+			Delay:   backoff(attempt + 1),
+			Next:    retryAttempt(what, attempt+1, load),
+		}
+	}
+}
+
+// handleErrorKey retries the failed command or leaves the error screen
+func (m model) handleErrorKey(keypress string) (tea.Model, tea.Cmd) {
+	switch keypress {
+	case "r":
+		if m.retry == nil {
+			return m, nil
+		}
+		retry := m.retry
+		m.err, m.retry = nil, nil
+		return m, retry
+	case "tab":
+		return m.switchSource(1)
+	case "shift+tab":
+		return m.switchSource(-1)
+	case "esc":
+		if m.onSource() {
+			// There is no project list to go back to
+			m.quitting = true
+			return m, tea.Quit
+		}
+		m.err, m.retry = nil, nil
+		if len(m.projects) > 0 {
+			return m.goBackToProjectSelection()
+		}
+		// Started with -project, so the project list was never loaded
+		m.selectedProject = ""
+		return m.startLoading()
+	case "q", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// handleRetryScheduled shows the attempt counter and waits out the backoff
+func (m model) handleRetryScheduled(msg RetryScheduledMsg) (tea.Model, tea.Cmd) {
+	reason, _, _ := strings.Cut(msg.Err.Error(), "\n")
+	if len(reason) > 100 {
+		reason = reason[:100] + "…"
+	}
+	m.list.Title = fmt.Sprintf("Loading %s... (attempt %d/%d)\nLast error: %s", msg.What, msg.Attempt, maxLoadAttempts, reason)
+	m.loadingNote = fmt.Sprintf("Attempt %d/%d, last error: %s", msg.Attempt, maxLoadAttempts, reason)
+	next := msg.Next
+	return m, tea.Tick(msg.Delay, func(time.Time) tea.Msg {
+		return next()
+	})
+}
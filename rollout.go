@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// ROLLING RESTART AND REPLACE
+// =============================================================================
+
+// How often rollout progress is polled
+const rolloutPollInterval = 3 * time.Second
+
+// Rolling actions offered on managed instance groups
+var rollingActions = []string{"restart", "replace"}
+
+// rollout tracks a rolling action in progress
+type rollout struct {
+	Group  ManagedGroup
+	Action string
+}
+
+// Title returns the heading of the rollout progress view
+func (r rollout) Title() string {
+	return fmt.Sprintf("Rolling %s of %s", r.Action, r.Group.Name)
+}
+
+// ManagedInstance is one member of a MIG with its pending action
+type ManagedInstance struct {
+	Instance       string `json:"instance"`
+	InstanceStatus string `json:"instanceStatus"`
+	CurrentAction  string `json:"currentAction"`
+}
+
+// Name returns the short instance name from its URL
+func (mi ManagedInstance) Name() string {
+	parts := strings.Split(mi.Instance, "/")
+	return parts[len(parts)-1]
+}
+
+// RolloutStartedMsg indicates a rolling action was accepted
+type RolloutStartedMsg struct {
+	Rollout rollout
+	Err     error
+}
+
+// RolloutProgressMsg carries a snapshot of the group during a rollout
+type RolloutProgressMsg struct {
+	Group     string
+	Instances []ManagedInstance
+	Stable    bool
+	Err       error
+}
+
+// StartRollingAction triggers a rolling restart or replace of all instances
+func (gcp *GCPService) StartRollingAction(project string, r rollout) tea.Cmd {
+	return func() tea.Msg {
+		args := append([]string{"compute", "instance-groups", "managed", "rolling-action", r.Action, r.Group.Name},
+			r.Group.gcloudArgs(project)...)
+		if _, err := gcp.runGcloud(args...); err != nil {
+			return RolloutStartedMsg{Rollout: r, Err: fmt.Errorf("failed to start rolling %s: %w", r.Action, err)}
+		}
+		return RolloutStartedMsg{Rollout: r}
+	}
+}
+
+// PollRollout lists the group's instances and whether the group has settled
+func (gcp *GCPService) PollRollout(project string, group ManagedGroup) tea.Cmd {
+	return func() tea.Msg {
+		args := append([]string{"compute", "instance-groups", "managed", "list-instances", group.Name,
+			"--format", "json(instance,instanceStatus,currentAction)"}, group.gcloudArgs(project)...)
+		output, err := gcp.runGcloud(args...)
+		if err != nil {
+			return RolloutProgressMsg{Group: group.Name, Err: fmt.Errorf("failed to list instances of %s: %w", group.Name, err)}
+		}
+
+		var instances []ManagedInstance
+		if err := json.Unmarshal(output, &instances); err != nil {
+			return RolloutProgressMsg{Group: group.Name, Err: fmt.Errorf("failed to parse instances: %w", err)}
+		}
+
+		args = append([]string{"compute", "instance-groups", "managed", "describe", group.Name,
+			"--format", "value(status.isStable)"}, group.gcloudArgs(project)...)
+		output, err = gcp.runGcloud(args...)
+		if err != nil {
+			return RolloutProgressMsg{Group: group.Name, Err: fmt.Errorf("failed to describe %s: %w", group.Name, err)}
+		}
+
+		stable := strings.EqualFold(strings.TrimSpace(string(output)), "true")
+		return RolloutProgressMsg{Group: group.Name, Instances: instances, Stable: stable}
+	}
+}
+
+// startRollout asks which rolling action to run on the selected MIG
+func (m model) startRollout(node *TreeNode) (tea.Model, tea.Cmd) {
+	group, _ := managedGroupOf(node)
+	if m.rollout != nil {
+		m.statusMsg = fmt.Sprintf("%s is still in progress", m.rollout.Title())
+		return m, nil
+	}
+
+	return m.askPick(fmt.Sprintf("Rolling action on %s", group.Name), rollingActions, func(m model, action string) (tea.Model, tea.Cmd) {
+		r := rollout{Group: group, Action: action}
+		prompt := fmt.Sprintf("Rolling %s all %d instances of %s?", action, len(node.Children), group.Name)
+		return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+			m.statusMsg = fmt.Sprintf("Starting rolling %s of %s...", action, group.Name)
+			return m, m.gcpService.StartRollingAction(m.selectedProject, r)
+		})
+	})
+}
+
+// handleRolloutStarted opens the progress view and begins polling
+func (m model) handleRolloutStarted(msg RolloutStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	r := msg.Rollout
+	m.rollout = &r
+	m.statusMsg = r.Title() + " started"
+	next, _ := m.openViewer(r.Title(), "Waiting for the first status update...")
+	return next, m.gcpService.PollRollout(m.selectedProject, r.Group)
+}
+
+// handleRolloutProgress refreshes the progress view until the group is stable
+func (m model) handleRolloutProgress(msg RolloutProgressMsg) (tea.Model, tea.Cmd) {
+	r := m.rollout
+	if r == nil || r.Group.Name != msg.Group {
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.rollout = nil
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	done := 0
+	for _, instance := range msg.Instances {
+		if instance.CurrentAction == "NONE" {
+			done++
+		}
+	}
+	summary := fmt.Sprintf("%s: %d/%d instances settled", r.Title(), done, len(msg.Instances))
+	if m.viewer != nil && m.viewer.Title == r.Title() {
+		viewer := *m.viewer
+		viewer.Viewport.SetContent(m.renderRolloutProgress(summary, msg.Instances))
+		m.viewer = &viewer
+	}
+
+	if msg.Stable {
+		m.rollout = nil
+		m.statusMsg = r.Title() + " finished"
+		return m, withRetry("VMs", m.gcpService.LoadVMs(m.selectedProject))
+	}
+
+	m.statusMsg = summary
+	gcp, project, group := m.gcpService, m.selectedProject, r.Group
+	return m, tea.Tick(rolloutPollInterval, func(time.Time) tea.Msg {
+		return gcp.PollRollout(project, group)()
+	})
+}
+
+// renderRolloutProgress lists each instance with its status and pending action
+func (m model) renderRolloutProgress(summary string, instances []ManagedInstance) string {
+	lines := []string{"  " + summary, ""}
+	for _, instance := range instances {
+		action := m.styles.Running.Render("done")
+		if instance.CurrentAction != "NONE" {
+			action = m.styles.Provisioning.Render(strings.ToLower(instance.CurrentAction))
+		}
+		status := VMStatus(instance.InstanceStatus)
+		lines = append(lines, fmt.Sprintf("  %s %-40s %s",
+			status.GetStyle(m.styles).Render(fmt.Sprintf("%-12s", instance.InstanceStatus)),
+			instance.Name(),
+			action))
+	}
+	lines = append(lines, "", m.styles.Label.Render("  Closing this view keeps tracking progress in the status line"))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SCREENSHOTS
+// =============================================================================
+
+// ScreenshotSavedMsg indicates a VM screenshot was captured
+type ScreenshotSavedMsg struct {
+	VMName string
+	Path   string
+	Err    error
+}
+
+// CaptureScreenshot fetches the VM's display via the getScreenshot API and saves it as PNG
+func (gcp *GCPService) CaptureScreenshot(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		data, err := gcp.callAPI("GET", computeURL(project, vm, "/screenshot"), nil)
+		if err != nil {
+			return ScreenshotSavedMsg{VMName: vm.Name, Err: fmt.Errorf("failed to capture screenshot: %w", err)}
+		}
+
+		var screenshot struct {
+			Contents string `json:"contents"`
+		}
+		if err := json.Unmarshal(data, &screenshot); err != nil {
+			return ScreenshotSavedMsg{VMName: vm.Name, Err: fmt.Errorf("failed to parse screenshot: %w", err)}
+		}
+		png, err := base64.StdEncoding.DecodeString(screenshot.Contents)
+		if err != nil {
+			return ScreenshotSavedMsg{VMName: vm.Name, Err: fmt.Errorf("failed to decode screenshot: %w", err)}
+		}
+
+		path, err := filepath.Abs(fmt.Sprintf("%s-%s.png", vm.Name, time.Now().Format("20060102-150405")))
+		if err == nil {
+			err = os.WriteFile(path, png, 0o644)
+		}
+		if err != nil {
+			return ScreenshotSavedMsg{VMName: vm.Name, Err: fmt.Errorf("failed to save screenshot: %w", err)}
+		}
+		return ScreenshotSavedMsg{VMName: vm.Name, Path: path}
+	}
+}
+
+// captureScreenshot starts a screenshot of the selected VM
+func (m model) captureScreenshot(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Capturing screenshot of %s...", node.VM.Name)
+	return m, m.gcpService.CaptureScreenshot(m.selectedProject, *node.VM)
+}
+
+// handleScreenshotSaved reports the saved file and offers to open it
+func (m model) handleScreenshotSaved(msg ScreenshotSavedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	m.statusMsg = fmt.Sprintf("Screenshot of %s saved to %s", msg.VMName, msg.Path)
+	return m.askConfirm("Open the screenshot?", func(m model) (tea.Model, tea.Cmd) {
+		if err := openBrowser(msg.Path); err != nil {
+			m.statusMsg = fmt.Sprintf("Failed to open %s: %v", msg.Path, err)
+		}
+		return m, nil
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SERIAL CONSOLE
+// =============================================================================
+
+// SerialConsoleArgs returns the gcloud command line that attaches to the VM's serial port
+func (gcp *GCPService) SerialConsoleArgs(project string, vm VM) []string {
+	return []string{
+		"gcloud", "compute", "connect-to-serial-port", vm.Name,
+		"--project", project,
+		"--zone", vm.ZoneName(),
+	}
+}
+
+// connectToSerialConsole hands the terminal to an interactive serial console session
+func (m model) connectToSerialConsole(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := node.VM
+	return m.launchAndQuit(Launch{
+		// The escape sequence is easy to forget once the console takes over
+		Title: fmt.Sprintf("serial console of %s (type ~. to disconnect)", vm.Name),
+		Args:  m.gcpService.SerialConsoleArgs(m.selectedProject, *vm),
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import "strings"
+
+// =============================================================================
+// SERVICE ACCOUNTS AND ACCESS SCOPES
+// =============================================================================
+
+// ServiceAccount is the identity a VM runs as, limited by its access scopes
+type ServiceAccount struct {
+	Email  string   `json:"email"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Prefix of OAuth scope URLs, dropped for display
+const scopePrefix = "https://www.googleapis.com/auth/"
+
+// Scopes that leave access up to the service account's IAM roles, or grant
+// write access to all of a service
+var broadScopes = map[string]bool{
+	"cloud-platform":          true,
+	"compute":                 true,
+	"devstorage.full_control": true,
+}
+
+// ServiceAccount returns the VM's attached service account, if any
+func (vm VM) ServiceAccount() (ServiceAccount, bool) {
+	if len(vm.ServiceAccounts) == 0 {
+		return ServiceAccount{}, false
+	}
+	return vm.ServiceAccounts[0], true
+}
+
+// IsDefaultCompute reports whether this is the project's default Compute Engine
+// service account, which has the Editor role unless that was removed
+func (sa ServiceAccount) IsDefaultCompute() bool {
+	return strings.HasSuffix(sa.Email, "-compute@developer.gserviceaccount.com")
+}
+
+// shortScope turns a scope URL into its name, e.g. "cloud-platform"
+func shortScope(scope string) string {
+	return strings.TrimPrefix(scope, scopePrefix)
+}
+
+// renderServiceAccount adds the service account and its scopes to the details pane,
+// warning about broad scopes on the default service account
+func (m model) renderServiceAccount(vm VM, field func(label, value string)) {
+	sa, ok := vm.ServiceAccount()
+	if !ok {
+		field("Service account", m.styles.Label.Render("none"))
+		return
+	}
+	email := sa.Email
+	if sa.IsDefaultCompute() {
+		email += m.styles.Label.Render("  (default)")
+	}
+	field("Service account", email)
+
+	var scopes []string
+	broad := false
+	for _, scope := range sa.Scopes {
+		name := shortScope(scope)
+		if broadScopes[name] {
+			broad = true
+			name = m.styles.Finding.Render(name)
+		}
+		scopes = append(scopes, name)
+	}
+	if len(scopes) == 0 {
+		scopes = append(scopes, m.styles.Label.Render("none"))
+	}
+	field("Scopes", strings.Join(scopes, ", "))
+
+	switch {
+	case broad && sa.IsDefaultCompute():
+		field("Warning", m.styles.Finding.Render("broad scopes on the default service account, likely Editor on the project"))
+	case broad:
+		field("Warning", m.styles.Prompt.Render("broad scopes; access depends only on the account's IAM roles"))
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SESSIONS
+// =============================================================================
+
+// SessionRecord describes the most recent SSH session opened through werkroom
+type SessionRecord struct {
+	Project     string    `json:"project"`
+	Name        string    `json:"name"`
+	Zone        string    `json:"zone"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// SessionClonedMsg indicates an additional session was opened or has ended
+type SessionClonedMsg struct {
+	VMName string
+	Err    error
+}
+
+// lastSessionPath returns the file recording the last session
+func lastSessionPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last_session.json"), nil
+}
+
+// loadLastSession reads the last session record, returning nil if there is none
+func loadLastSession() *SessionRecord {
+	path, err := lastSessionPath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var record SessionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil
+	}
+	return &record
+}
+
+// saveLastSession records the session about to be opened
+func saveLastSession(project string, vm VM) error {
+	path, err := lastSessionPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(SessionRecord{
+		Project:     project,
+		Name:        vm.Name,
+		Zone:        vm.ZoneName(),
+		ConnectedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// isLastSession reports whether the node is the host of the last session
+func (m model) isLastSession(node *TreeNode) bool {
+	return m.lastSession != nil && node.Type == InstanceNode &&
+		m.lastSession.Project == m.selectedProject &&
+		m.lastSession.Name == node.VM.Name &&
+		m.lastSession.Zone == node.VM.ZoneName()
+}
+
+// cloneSession opens an additional SSH session without leaving the browser.
+// Inside tmux the session gets its own window; otherwise it runs as a child
+// process and the TUI resumes once it exits.
+func (m model) cloneSession(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	args, env := withLatencyTracking(m.gcpService.SSHArgs(m.selectedProject, vm), m.selectedProject, vm)
+
+	if err := saveLastSession(m.selectedProject, vm); err == nil {
+		m.lastSession = loadLastSession()
+	}
+
+	if os.Getenv("TMUX") != "" {
+		m.statusMsg = fmt.Sprintf("Opening tmux window for %s...", vm.Name)
+		return m, func() tea.Msg {
+			tmuxArgs := []string{"new-window", "-n", vm.Name}
+			for _, kv := range env {
+				tmuxArgs = append(tmuxArgs, "-e", kv)
+			}
+			tmuxArgs = append(tmuxArgs, args...)
+			started := time.Now()
+			output, err := exec.Command("tmux", tmuxArgs...).CombinedOutput()
+			auditCommand("session", vm.Name, args, started, err)
+			if err != nil {
+				return SessionClonedMsg{VMName: vm.Name, Err: fmt.Errorf("tmux new-window failed: %w: %s", err, output)}
+			}
+			return SessionClonedMsg{VMName: vm.Name}
+		}
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	started := time.Now()
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		auditCommand("session", vm.Name, args, started, err)
+		return SessionClonedMsg{VMName: vm.Name, Err: err}
+	})
+}
+
+// handleSessionCloned reports the outcome of an additional session
+func (m model) handleSessionCloned(msg SessionClonedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = fmt.Sprintf("Session to %s failed: %v", msg.VMName, msg.Err)
+	} else if os.Getenv("TMUX") != "" {
+		m.statusMsg = fmt.Sprintf("Opened tmux window for %s", msg.VMName)
+	} else {
+		m.statusMsg = fmt.Sprintf("Session to %s closed", msg.VMName)
+	}
+	m.latency = loadLatencyHistory()
+	m.updateVMList()
+	return m, nil
+}
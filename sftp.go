@@ -0,0 +1,513 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pkg/sftp"
+)
+
+// =============================================================================
+// SFTP BROWSER
+// =============================================================================
+
+// Lines reserved around the file panes for the title, status and help
+const sftpChrome = 7
+
+// fileEntry is a row in one of the browser's panes
+type fileEntry struct {
+	Name    string
+	Size    int64
+	IsDir   bool
+	ModTime time.Time
+}
+
+// filePane is a directory listing with a cursor
+type filePane struct {
+	Dir     string
+	Entries []fileEntry
+	Cursor  int
+}
+
+// selected returns the entry under the cursor
+func (p filePane) selected() (fileEntry, bool) {
+	if p.Cursor < 0 || p.Cursor >= len(p.Entries) {
+		return fileEntry{}, false
+	}
+	return p.Entries[p.Cursor], true
+}
+
+// sftpBrowser is a two-pane file manager: local on the left, the VM on the right
+type sftpBrowser struct {
+	Node         *TreeNode
+	Local        filePane
+	Remote       filePane
+	RemoteActive bool
+	// Operation in progress, shown instead of the status
+	Busy   string
+	Status string
+
+	client *sftp.Client
+	ssh    *exec.Cmd
+}
+
+// SFTPConnectedMsg reports the SFTP session for a browser
+type SFTPConnectedMsg struct {
+	Node   *TreeNode
+	Client *sftp.Client
+	SSH    *exec.Cmd
+	Home   string
+	Err    error
+}
+
+// SFTPListedMsg carries a fresh listing for one pane
+type SFTPListedMsg struct {
+	Remote  bool
+	Dir     string
+	Entries []fileEntry
+	Err     error
+}
+
+// SFTPDoneMsg reports a finished transfer or delete
+type SFTPDoneMsg struct {
+	What   string
+	Remote bool // Which pane changed and needs relisting
+	Err    error
+}
+
+// startSFTP connects to the VM's SFTP subsystem through gcloud's ssh settings
+func (m model) startSFTP(node *TreeNode) (tea.Model, tea.Cmd) {
+	gcp, project, vm := m.gcpService, m.selectedProject, *node.VM
+	m.statusMsg = fmt.Sprintf("Opening SFTP to %s...", vm.Name)
+	return m, func() tea.Msg {
+		destination, options, err := gcp.resolveSSH(project, vm)
+		if err != nil {
+			return SFTPConnectedMsg{Node: node, Err: err}
+		}
+		args := []string{}
+		for _, option := range options {
+			args = append(args, "-o", option)
+		}
+		args = append(args, "-s", destination, "sftp")
+
+		cmd := exec.Command("ssh", args...)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return SFTPConnectedMsg{Node: node, Err: err}
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return SFTPConnectedMsg{Node: node, Err: err}
+		}
+		started := time.Now()
+		err = cmd.Start()
+		auditCommand("sftp", vm.Name, append([]string{"ssh"}, args...), started, err)
+		if err != nil {
+			return SFTPConnectedMsg{Node: node, Err: fmt.Errorf("failed to start ssh: %w", err)}
+		}
+
+		client, err := sftp.NewClientPipe(stdout, stdin)
+		if err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return SFTPConnectedMsg{Node: node, Err: fmt.Errorf("failed to start SFTP on %s: %w", vm.Name, err)}
+		}
+		home, err := client.RealPath(".")
+		if err != nil {
+			home = "/"
+		}
+		return SFTPConnectedMsg{Node: node, Client: client, SSH: cmd, Home: home}
+	}
+}
+
+// handleSFTPConnected opens the browser on the local working directory and remote home
+func (m model) handleSFTPConnected(msg SFTPConnectedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	local, err := os.Getwd()
+	if err != nil {
+		local = "/"
+	}
+	m.statusMsg = ""
+	m.sftp = &sftpBrowser{
+		Node:         msg.Node,
+		Local:        filePane{Dir: local},
+		Remote:       filePane{Dir: msg.Home},
+		RemoteActive: true,
+		Busy:         "Listing...",
+		client:       msg.Client,
+		ssh:          msg.SSH,
+	}
+	return m, tea.Batch(listLocalDir(local), listRemoteDir(msg.Client, msg.Home))
+}
+
+// listLocalDir lists a local directory
+func listLocalDir(dir string) tea.Cmd {
+	return func() tea.Msg {
+		dirEntries, err := os.ReadDir(dir)
+		if err != nil {
+			return SFTPListedMsg{Dir: dir, Err: err}
+		}
+		entries := make([]fileEntry, 0, len(dirEntries))
+		for _, entry := range dirEntries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			entries = append(entries, fileEntryOf(info))
+		}
+		return SFTPListedMsg{Dir: dir, Entries: sortedEntries(entries, dir != filepath.Dir(dir))}
+	}
+}
+
+// listRemoteDir lists a directory on the VM
+func listRemoteDir(client *sftp.Client, dir string) tea.Cmd {
+	return func() tea.Msg {
+		infos, err := client.ReadDir(dir)
+		if err != nil {
+			return SFTPListedMsg{Remote: true, Dir: dir, Err: err}
+		}
+		entries := make([]fileEntry, len(infos))
+		for i, info := range infos {
+			entries[i] = fileEntryOf(info)
+		}
+		return SFTPListedMsg{Remote: true, Dir: dir, Entries: sortedEntries(entries, dir != "/")}
+	}
+}
+
+// fileEntryOf converts file info into a pane row
+func fileEntryOf(info fs.FileInfo) fileEntry {
+	return fileEntry{Name: info.Name(), Size: info.Size(), IsDir: info.IsDir(), ModTime: info.ModTime()}
+}
+
+// sortedEntries puts directories first, then files, each by name, under a ".." row
+func sortedEntries(entries []fileEntry, withParent bool) []fileEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	if withParent {
+		entries = append([]fileEntry{{Name: "..", IsDir: true}}, entries...)
+	}
+	return entries
+}
+
+// handleSFTPListed shows a listing in its pane
+func (m model) handleSFTPListed(msg SFTPListedMsg) (tea.Model, tea.Cmd) {
+	if m.sftp == nil {
+		return m, nil
+	}
+	browser := *m.sftp
+	browser.Busy = ""
+	if msg.Err != nil {
+		browser.Status = fmt.Sprintf("Failed to list %s: %v", msg.Dir, msg.Err)
+		m.sftp = &browser
+		return m, nil
+	}
+	pane := &browser.Local
+	if msg.Remote {
+		pane = &browser.Remote
+	}
+	if pane.Dir != msg.Dir {
+		pane.Cursor = 0
+	}
+	pane.Dir, pane.Entries = msg.Dir, msg.Entries
+	pane.Cursor = min(pane.Cursor, max(len(pane.Entries)-1, 0))
+	m.sftp = &browser
+	return m, nil
+}
+
+// handleSFTPDone reports a finished operation and relists the pane it changed
+func (m model) handleSFTPDone(msg SFTPDoneMsg) (tea.Model, tea.Cmd) {
+	if m.sftp == nil {
+		return m, nil
+	}
+	browser := *m.sftp
+	browser.Busy = ""
+	browser.Status = msg.What
+	if msg.Err != nil {
+		browser.Status = fmt.Sprintf("%s failed: %v", msg.What, msg.Err)
+	}
+	m.sftp = &browser
+	if msg.Remote {
+		return m, listRemoteDir(browser.client, browser.Remote.Dir)
+	}
+	return m, listLocalDir(browser.Local.Dir)
+}
+
+// handleSFTPKey navigates the panes and starts transfers
+func (m model) handleSFTPKey(keypress string) (tea.Model, tea.Cmd) {
+	browser := *m.sftp
+	pane := &browser.Local
+	if browser.RemoteActive {
+		pane = &browser.Remote
+	}
+
+	switch keypress {
+	case "esc", "q":
+		return m.closeSFTP()
+	case "ctrl+c":
+		m, _ = m.closeSFTP()
+		m.quitting = true
+		return m, tea.Quit
+	case "tab", "left", "right":
+		browser.RemoteActive = !browser.RemoteActive
+	case "up", "k":
+		if pane.Cursor > 0 {
+			pane.Cursor--
+		}
+	case "down", "j":
+		if pane.Cursor < len(pane.Entries)-1 {
+			pane.Cursor++
+		}
+	case "r":
+		m.sftp = &browser
+		return m, browser.relist(browser.RemoteActive)
+	case "backspace", "-":
+		return m.enterDir(browser, "..")
+	case "enter":
+		if entry, ok := pane.selected(); ok && entry.IsDir {
+			return m.enterDir(browser, entry.Name)
+		}
+	case "d":
+		if entry, ok := browser.Remote.selected(); ok && browser.RemoteActive && !entry.IsDir && browser.Busy == "" {
+			browser.Busy = "Downloading " + entry.Name + "..."
+			m.sftp = &browser
+			return m, browser.download(entry.Name)
+		}
+		browser.Status = "Select a file in the remote pane to download"
+	case "u":
+		if m.config.ReadOnly {
+			browser.Status = "Uploads are disabled in read-only mode"
+			break
+		}
+		if entry, ok := browser.Local.selected(); ok && !browser.RemoteActive && !entry.IsDir && browser.Busy == "" {
+			browser.Busy = "Uploading " + entry.Name + "..."
+			m.sftp = &browser
+			return m, browser.upload(entry.Name)
+		}
+		browser.Status = "Select a file in the local pane to upload"
+	case "x":
+		entry, ok := browser.Remote.selected()
+		if !browser.RemoteActive || !ok || entry.Name == ".." {
+			browser.Status = "Select a file or empty directory in the remote pane to delete"
+			break
+		}
+		m.sftp = &browser
+		return m.confirmRemoteDelete(entry)
+	}
+	m.sftp = &browser
+	return m, nil
+}
+
+// enterDir lists a subdirectory or the parent of the active pane; the pane
+// only moves there once the listing succeeds
+func (m model) enterDir(browser sftpBrowser, name string) (tea.Model, tea.Cmd) {
+	browser.Busy = "Listing..."
+	m.sftp = &browser
+	if browser.RemoteActive {
+		return m, listRemoteDir(browser.client, path.Clean(path.Join(browser.Remote.Dir, name)))
+	}
+	return m, listLocalDir(filepath.Clean(filepath.Join(browser.Local.Dir, name)))
+}
+
+// relist reloads one pane
+func (b sftpBrowser) relist(remote bool) tea.Cmd {
+	if remote {
+		return listRemoteDir(b.client, b.Remote.Dir)
+	}
+	return listLocalDir(b.Local.Dir)
+}
+
+// download copies a remote file into the local directory, never overwriting
+func (b sftpBrowser) download(name string) tea.Cmd {
+	client, from, to := b.client, path.Join(b.Remote.Dir, name), filepath.Join(b.Local.Dir, name)
+	return func() tea.Msg {
+		src, err := client.Open(from)
+		if err != nil {
+			return SFTPDoneMsg{What: "Download of " + name, Err: err}
+		}
+		defer src.Close()
+		dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return SFTPDoneMsg{What: "Download of " + name, Err: err}
+		}
+		n, err := io.Copy(dst, src)
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(to)
+			return SFTPDoneMsg{What: "Download of " + name, Err: err}
+		}
+		return SFTPDoneMsg{What: fmt.Sprintf("Downloaded %s (%s)", name, formatBytes(n))}
+	}
+}
+
+// upload copies a local file into the remote directory, never overwriting
+func (b sftpBrowser) upload(name string) tea.Cmd {
+	client, from, to, target := b.client, filepath.Join(b.Local.Dir, name), path.Join(b.Remote.Dir, name), b.Node.Name
+	return func() tea.Msg {
+		started := time.Now()
+		src, err := os.Open(from)
+		if err != nil {
+			return SFTPDoneMsg{What: "Upload of " + name, Remote: true, Err: err}
+		}
+		defer src.Close()
+		dst, err := client.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+		if err != nil {
+			return SFTPDoneMsg{What: "Upload of " + name, Remote: true, Err: err}
+		}
+		n, err := io.Copy(dst, src)
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		auditCommand("sftp-upload", target, []string{from, to}, started, err)
+		if err != nil {
+			return SFTPDoneMsg{What: "Upload of " + name, Remote: true, Err: err}
+		}
+		return SFTPDoneMsg{What: fmt.Sprintf("Uploaded %s (%s)", name, formatBytes(n)), Remote: true}
+	}
+}
+
+// confirmRemoteDelete asks before deleting a remote file or empty directory
+func (m model) confirmRemoteDelete(entry fileEntry) (tea.Model, tea.Cmd) {
+	browser := *m.sftp
+	if m.config.ReadOnly {
+		browser.Status = "Deleting is disabled in read-only mode"
+		m.sftp = &browser
+		return m, nil
+	}
+	production := m.isProduction(browser.Node, false)
+	if production && m.config.Production.DisableDestructive {
+		browser.Status = "Deleting files is disabled for production resources"
+		m.sftp = &browser
+		return m, nil
+	}
+
+	target := path.Join(browser.Remote.Dir, entry.Name)
+	prompt := fmt.Sprintf("Delete %s on %s?", target, browser.Node.Name)
+	if production {
+		prompt = fmt.Sprintf("%s is production. Really delete %s?", browser.Node.Name, target)
+	}
+	client, vmName := browser.client, browser.Node.Name
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		if m.sftp == nil {
+			return m, nil
+		}
+		pending := *m.sftp
+		pending.Busy = "Deleting " + entry.Name + "..."
+		m.sftp = &pending
+		return m, func() tea.Msg {
+			started := time.Now()
+			var err error
+			if entry.IsDir {
+				err = client.RemoveDirectory(target)
+			} else {
+				err = client.Remove(target)
+			}
+			auditCommand("sftp-delete", vmName, []string{target}, started, err)
+			return SFTPDoneMsg{What: "Deleted " + entry.Name, Remote: true, Err: err}
+		}
+	})
+}
+
+// closeSFTP ends the session and returns to the list
+func (m model) closeSFTP() (model, tea.Cmd) {
+	client, cmd := m.sftp.client, m.sftp.ssh
+	m.sftp = nil
+	return m, func() tea.Msg {
+		client.Close()
+		cmd.Wait()
+		return nil
+	}
+}
+
+// formatBytes renders a size with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for size := n / unit; size >= unit; size /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// renderSFTP draws both panes side by side
+func (m model) renderSFTP() string {
+	browser := m.sftp
+	width := m.width
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	paneWidth := width/2 - 2
+	height := max(m.height-sftpChrome, MinHeight)
+
+	left := m.renderFilePane("Local: "+browser.Local.Dir, browser.Local, !browser.RemoteActive, paneWidth, height)
+	right := m.renderFilePane(browser.Node.Name+": "+browser.Remote.Dir, browser.Remote, browser.RemoteActive, paneWidth, height)
+
+	status := browser.Status
+	if browser.Busy != "" {
+		status = browser.Busy
+	}
+	return "\n  " + m.styles.Prompt.Render("SFTP "+browser.Node.Name) + "\n" +
+		lipgloss.JoinHorizontal(lipgloss.Top, left, right) + "\n" +
+		"  " + m.styles.StatusLine.Render(status) + "\n" +
+		m.styles.Label.Render("  Tab to switch pane, Enter to open, Backspace for parent, 'd' download, 'u' upload, 'x' delete, 'r' refresh, Esc to close")
+}
+
+// renderFilePane draws one listing, scrolled to keep the cursor visible
+func (m model) renderFilePane(title string, pane filePane, active bool, width, height int) string {
+	if len(title) > width {
+		title = "…" + title[len(title)-width+1:]
+	}
+	lines := []string{m.styles.Label.Render(title)}
+	if active {
+		lines[0] = m.styles.Prompt.Render(title)
+	}
+
+	rows := height - 1
+	start := 0
+	if pane.Cursor >= rows {
+		start = pane.Cursor - rows + 1
+	}
+	for i := start; i < len(pane.Entries) && i < start+rows; i++ {
+		entry := pane.Entries[i]
+		name := entry.Name
+		size := formatBytes(entry.Size)
+		if entry.IsDir {
+			name += "/"
+			size = ""
+		}
+		nameWidth := max(width-12, 8)
+		if len(name) > nameWidth {
+			name = name[:nameWidth-1] + "…"
+		}
+		line := fmt.Sprintf("%-*s %10s", nameWidth, name, size)
+		if i == pane.Cursor && active {
+			line = m.styles.SelectedItem.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	return m.styles.Details.Width(width).Render(strings.Join(lines, "\n"))
+}
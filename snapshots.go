@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// DISKS AND SNAPSHOTS
+// =============================================================================
+
+const (
+	// Default for snapshots.name_template
+	defaultSnapshotTemplate = "{{.Disk}}-{{.Date}}"
+	// How often snapshot status is polled while they are created
+	snapshotPollInterval = 5 * time.Second
+	// When to stop polling snapshots that never become READY
+	snapshotTimeout = time.Hour
+	// Picker entry snapshotting every persistent disk
+	allDisksOption = "All disks"
+)
+
+// SnapshotConfig tunes disk snapshots
+type SnapshotConfig struct {
+	// Go template for snapshot names with .VM, .Disk and .Date (YYYYMMDD-HHMMSS)
+	NameTemplate string `yaml:"name_template"`
+}
+
+// snapshotName is what snapshot name templates are executed with
+type snapshotName struct {
+	VM   string
+	Disk string
+	Date string
+}
+
+// Characters Compute Engine resource names can't contain
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// resourceName turns text into a valid resource name: lowercase letters, digits
+// and dashes, starting with a letter, at most 63 characters
+func resourceName(text string) (string, error) {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(text)), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	name = strings.Trim(name, "-")
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return "", fmt.Errorf("name %q must start with a letter", name)
+	}
+	return name, nil
+}
+
+// Name returns the disk's name, taken from its source URL
+func (d Disk) Name() string {
+	return path.Base(d.Source)
+}
+
+// snapshottable reports whether the disk can be snapshotted with the VM's zone.
+// Local SSDs can't be, and regional disks are left to the console.
+func (d Disk) snapshottable() bool {
+	return d.Source != "" && d.Type != "SCRATCH" && !strings.Contains(d.Source, "/regions/")
+}
+
+// diskLines returns the details pane lines of a VM's disks
+func (m model) diskLines(vm VM) []string {
+	var lines []string
+	for _, disk := range vm.Disks {
+		name := disk.Name()
+		if name == "" || name == "." {
+			name = disk.DeviceName
+		}
+		var notes []string
+		if disk.DiskSizeGB != "" {
+			notes = append(notes, disk.DiskSizeGB+" GB")
+		}
+		if disk.Boot {
+			notes = append(notes, "boot")
+		}
+		if disk.Type == "SCRATCH" {
+			notes = append(notes, "local SSD")
+		}
+		if disk.Mode == "READ_ONLY" {
+			notes = append(notes, "read-only")
+		}
+		lines = append(lines, "  "+name+m.styles.Label.Render("  "+strings.Join(notes, ", ")))
+	}
+	return lines
+}
+
+// canSnapshot reports whether the node is an instance with a disk to snapshot
+func canSnapshot(m model, node *TreeNode) bool {
+	if !isInstance(m, node) || m.gcpService.native {
+		return false
+	}
+	for _, disk := range node.VM.Disks {
+		if disk.snapshottable() {
+			return true
+		}
+	}
+	return false
+}
+
+// startSnapshot asks which disks to snapshot, then for the snapshot names
+func (m model) startSnapshot(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	var disks []Disk
+	for _, disk := range vm.Disks {
+		if disk.snapshottable() {
+			disks = append(disks, disk)
+		}
+	}
+	if len(disks) == 1 {
+		return m.askSnapshotNames(vm, disks)
+	}
+
+	options := []string{allDisksOption}
+	for _, disk := range disks {
+		options = append(options, disk.Name())
+	}
+	return m.askPick(fmt.Sprintf("Snapshot which disk of %s?", vm.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		if option == allDisksOption {
+			return m.askSnapshotNames(vm, disks)
+		}
+		for _, disk := range disks {
+			if disk.Name() == option {
+				return m.askSnapshotNames(vm, []Disk{disk})
+			}
+		}
+		return m, nil
+	})
+}
+
+// askSnapshotNames asks for the name template, prefilled from the config
+func (m model) askSnapshotNames(vm VM, disks []Disk) (tea.Model, tea.Cmd) {
+	prompt := fmt.Sprintf("Snapshot name for %s (template with .VM, .Disk, .Date)", disks[0].Name())
+	if len(disks) > 1 {
+		prompt = fmt.Sprintf("Snapshot names for %d disks of %s (template with .VM, .Disk, .Date)", len(disks), vm.Name)
+	}
+	text := m.config.Snapshots.NameTemplate
+	if text == "" {
+		text = defaultSnapshotTemplate
+	}
+	return m.askInput(prompt, text, func(m model, text string) (tea.Model, tea.Cmd) {
+		names, err := snapshotNames(text, vm, disks, time.Now())
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		m.statusMsg = fmt.Sprintf("Creating %d snapshot(s) of %s...", len(disks), vm.Name)
+		return m, m.gcpService.CreateSnapshots(m.selectedProject, vm, disks, names)
+	})
+}
+
+// snapshotNames renders the template for each disk into a valid, unique snapshot name
+func snapshotNames(text string, vm VM, disks []Disk, now time.Time) ([]string, error) {
+	tmpl, err := template.New("snapshot").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot name template: %w", err)
+	}
+	seen := make(map[string]bool, len(disks))
+	names := make([]string, len(disks))
+	for i, disk := range disks {
+		var out bytes.Buffer
+		data := snapshotName{VM: vm.Name, Disk: disk.Name(), Date: now.UTC().Format("20060102-150405")}
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("snapshot name template failed: %w", err)
+		}
+		name, err := resourceName(out.String())
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("snapshot name %q is used for more than one disk; include {{.Disk}}", name)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names, nil
+}
+
+// snapshotProgress tracks one batch of snapshots until they are READY
+type snapshotProgress struct {
+	ID      int
+	Project string
+	VM      string
+	// Snapshot names with their last known status
+	Status   map[string]string
+	Order    []string
+	Deadline time.Time
+}
+
+// Summary describes the progress for the status line
+func (p snapshotProgress) Summary() string {
+	ready := 0
+	var pending []string
+	for _, name := range p.Order {
+		switch status := p.Status[name]; status {
+		case "READY":
+			ready++
+		default:
+			pending = append(pending, fmt.Sprintf("%s %s", name, strings.ToLower(status)))
+		}
+	}
+	summary := fmt.Sprintf("Snapshots of %s: %d/%d ready", p.VM, ready, len(p.Order))
+	if len(pending) > 0 {
+		summary += " (" + strings.Join(pending, ", ") + ")"
+	}
+	return summary
+}
+
+// done reports whether every snapshot is READY or FAILED
+func (p snapshotProgress) done() bool {
+	for _, status := range p.Status {
+		if status != "READY" && status != "FAILED" {
+			return false
+		}
+	}
+	return true
+}
+
+// SnapshotsStartedMsg reports that snapshot creation was requested
+type SnapshotsStartedMsg struct {
+	Project string
+	VM      VM
+	Names   []string
+	Err     error
+}
+
+// SnapshotStatusMsg carries the status of one batch of snapshots
+type SnapshotStatusMsg struct {
+	ID     int
+	Status map[string]string
+	Err    error
+}
+
+// SnapshotPollMsg asks for the next status poll of a batch
+type SnapshotPollMsg struct {
+	ID int
+}
+
+// CreateSnapshots starts snapshots of the disks without waiting for them
+func (gcp *GCPService) CreateSnapshots(project string, vm VM, disks []Disk, names []string) tea.Cmd {
+	return func() tea.Msg {
+		args := []string{"compute", "disks", "snapshot"}
+		for _, disk := range disks {
+			args = append(args, disk.Name())
+		}
+		args = append(args,
+			"--project", project,
+			"--zone", vm.ZoneName(),
+			"--snapshot-names", strings.Join(names, ","),
+			"--async")
+		if _, err := gcp.runGcloud(args...); err != nil {
+			return SnapshotsStartedMsg{Err: fmt.Errorf("failed to create snapshots of %s: %w", vm.Name, err)}
+		}
+		return SnapshotsStartedMsg{Project: project, VM: vm, Names: names}
+	}
+}
+
+// SnapshotStatus looks up the status of a batch's snapshots
+func (gcp *GCPService) SnapshotStatus(p snapshotProgress) tea.Cmd {
+	id, project, names := p.ID, p.Project, p.Order
+	return func() tea.Msg {
+		filters := make([]string, len(names))
+		for i, name := range names {
+			filters[i] = fmt.Sprintf("name=%s", name)
+		}
+		output, err := gcp.runGcloud("compute", "snapshots", "list",
+			"--project", project,
+			"--filter", strings.Join(filters, " OR "),
+			"--format", "json(name,status)")
+		if err != nil {
+			return SnapshotStatusMsg{ID: id, Err: fmt.Errorf("failed to check snapshots: %w", err)}
+		}
+		var snapshots []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(output, &snapshots); err != nil {
+			return SnapshotStatusMsg{ID: id, Err: fmt.Errorf("failed to parse snapshots: %w", err)}
+		}
+		status := make(map[string]string, len(snapshots))
+		for _, snapshot := range snapshots {
+			status[snapshot.Name] = snapshot.Status
+		}
+		return SnapshotStatusMsg{ID: id, Status: status}
+	}
+}
+
+// pollSnapshots schedules the next status check of a batch
+func pollSnapshots(id int) tea.Cmd {
+	return tea.Tick(snapshotPollInterval, func(time.Time) tea.Msg { return SnapshotPollMsg{ID: id} })
+}
+
+// snapshotIndex returns the position of a tracked batch, or -1
+func (m model) snapshotIndex(id int) int {
+	for i, p := range m.snapshots {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleSnapshotsStarted begins tracking the new snapshots
+func (m model) handleSnapshotsStarted(msg SnapshotsStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	m.snapshotSeq++
+	progress := snapshotProgress{
+		ID: m.snapshotSeq, Project: msg.Project, VM: msg.VM.Name,
+		Status: make(map[string]string), Order: msg.Names,
+		Deadline: time.Now().Add(snapshotTimeout),
+	}
+	for _, name := range msg.Names {
+		progress.Status[name] = "CREATING"
+	}
+	m.snapshots = append(slices.Clip(m.snapshots), progress)
+	m.statusMsg = progress.Summary()
+	return m, pollSnapshots(progress.ID)
+}
+
+// handleSnapshotPoll checks on a tracked batch, giving up after its deadline
+func (m model) handleSnapshotPoll(msg SnapshotPollMsg) (tea.Model, tea.Cmd) {
+	i := m.snapshotIndex(msg.ID)
+	if i < 0 {
+		return m, nil
+	}
+	progress := m.snapshots[i]
+	if time.Now().After(progress.Deadline) {
+		m.snapshots = slices.Delete(slices.Clone(m.snapshots), i, i+1)
+		m.statusMsg = fmt.Sprintf("Stopped checking after %s: %s", snapshotTimeout, progress.Summary())
+		return m, nil
+	}
+	return m, m.gcpService.SnapshotStatus(progress)
+}
+
+// handleSnapshotStatus updates a batch's progress and keeps polling until all are done
+func (m model) handleSnapshotStatus(msg SnapshotStatusMsg) (tea.Model, tea.Cmd) {
+	i := m.snapshotIndex(msg.ID)
+	if i < 0 {
+		return m, nil
+	}
+	if msg.Err != nil {
+		// Transient; the next poll may well succeed
+		debugf("snapshot status: %v", msg.Err)
+		return m, pollSnapshots(msg.ID)
+	}
+	// Copy on write: the progress is shared with earlier model values
+	progress := m.snapshots[i]
+	progress.Status = make(map[string]string, len(progress.Order))
+	for _, name := range progress.Order {
+		progress.Status[name] = m.snapshots[i].Status[name]
+		if status, ok := msg.Status[name]; ok {
+			progress.Status[name] = status
+		}
+	}
+	m.snapshots = slices.Clone(m.snapshots)
+	m.statusMsg = progress.Summary()
+	if !progress.done() {
+		m.snapshots[i] = progress
+		return m, pollSnapshots(msg.ID)
+	}
+	m.snapshots = slices.Delete(m.snapshots, i, i+1)
+	return m, m.config.Notifications.notify("werkroom", progress.Summary())
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SOURCE TABS
+// =============================================================================
+
+// currentSource returns the name of the source on screen, "gcp" for projects
+func (m model) currentSource() string {
+	if m.config.Source == "" {
+		return "gcp"
+	}
+	return m.config.Source
+}
+
+// hasSourceTabs reports whether several sources can be switched between
+func (m model) hasSourceTabs() bool {
+	return len(m.config.Sources) > 1
+}
+
+// switchSource moves step tabs along the configured sources and loads the new one
+func (m model) switchSource(step int) (tea.Model, tea.Cmd) {
+	sources := m.config.Sources
+	if len(sources) < 2 {
+		return m, nil
+	}
+	i := slices.Index(sources, m.currentSource())
+	next := sources[((i+step)%len(sources)+len(sources))%len(sources)]
+	debugf("switching source from %s to %s", m.currentSource(), next)
+	m.saveTab()
+
+	if !m.onSource() && m.state == StateSelectingProject {
+		// Coming back should land on the project list, not the last project
+		m.selectedProject = ""
+	}
+	m.err, m.retry = nil, nil
+	m.filtering = false
+	m.filterText = ""
+	m.treeManager.setNodes(nil)
+	// Otherwise the instance toggles would rebuild the old project's VMs under the new source
+	m.treeManager.vms = nil
+	m.resetSelection()
+
+	if next == "gcp" {
+		m.config.Source = ""
+		m.resourceKind = KindInstances
+		if m.selectedProject != "" {
+			return m.loadResources()
+		}
+		if len(m.projects) > 0 {
+			return m.goBackToProjectSelection()
+		}
+		// Started on another source, so credentials were never checked
+		m.state = StateCheckingAuth
+		spin := m.startSpinner()
+		return m, tea.Batch(m.gcpService.CheckAuth(), spin)
+	}
+
+	rt, ok := sourceType(next)
+	if !ok {
+		m.statusMsg = fmt.Sprintf("Unknown source %q", next)
+		return m, nil
+	}
+	m.config.Source = next
+	m.resourceKind = rt.Kind
+	return m.loadResources()
+}
+
+// renderSourceTabs draws the tab bar of configured sources
+func (m model) renderSourceTabs() string {
+	tabs := make([]string, len(m.config.Sources))
+	for i, source := range m.config.Sources {
+		if source == m.currentSource() {
+			tabs[i] = m.styles.Prompt.Render("[" + source + "]")
+		} else {
+			tabs[i] = m.styles.Label.Render(" " + source + " ")
+		}
+	}
+	return "  " + strings.Join(tabs, " ") + "  " + m.styles.Help.Render("Tab/Shift+Tab to switch") + "\n"
+}
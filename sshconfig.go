@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SSH CONFIG
+// =============================================================================
+
+// SSHConfigSource locates ssh_config and decides how its hosts are grouped
+type SSHConfigSource struct {
+	// Default is ~/.ssh/config
+	Path string `yaml:"path"`
+	// "comment" (default) groups by marker comments, "prefix" by the alias before Separator
+	GroupBy string `yaml:"group_by"`
+	// Comment starting a group, default "group:" as in "# group: databases"
+	Marker string `yaml:"marker"`
+	// Splits the group prefix off an alias, default "-" (db-1 is in group db)
+	Separator string `yaml:"separator"`
+}
+
+// ssh refuses to follow Includes deeper than this
+const maxSSHConfigDepth = 16
+
+// sshHost is one alias from a Host line with the options set in its block
+type sshHost struct {
+	Alias   string
+	Group   string
+	Options []ResourceField
+}
+
+// Options shown in the details pane, in ssh_config's canonical spelling
+var sshHostOptions = []string{"HostName", "User", "Port", "ProxyJump", "IdentityFile"}
+
+// LoadSSHConfigHosts lists the concrete Host aliases of ssh_config and its Includes
+func LoadSSHConfigHosts(config Config) tea.Cmd {
+	return func() tea.Msg {
+		source := config.SSHConfig
+		path := source.Path
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return ErrorMsg{Err: err}
+			}
+			path = filepath.Join(home, ".ssh", "config")
+		}
+		path = expandHome(path)
+
+		hosts, err := parseSSHConfig(path, source.marker(), 0)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		var resources []Resource
+		seen := make(map[string]bool)
+		for _, host := range hosts {
+			// ssh uses the first block matching an alias; later ones only add options
+			if seen[host.Alias] {
+				continue
+			}
+			seen[host.Alias] = true
+			resource := Resource{Kind: KindSSHConfig, Name: host.Alias, Fields: host.Options}
+			if source.GroupBy == "prefix" {
+				separator := source.Separator
+				if separator == "" {
+					separator = "-"
+				}
+				if prefix, _, ok := strings.Cut(host.Alias, separator); ok {
+					resource.Group = prefix
+				}
+			} else {
+				resource.Group = host.Group
+			}
+			resources = append(resources, resource)
+		}
+		debugf("listed %d hosts from %s", len(resources), path)
+		return ResourcesLoadedMsg{Kind: KindSSHConfig, Resources: resources}
+	}
+}
+
+// marker returns the comment prefix that starts a group
+func (s SSHConfigSource) marker() string {
+	if s.Marker == "" {
+		return "group:"
+	}
+	return s.Marker
+}
+
+// parseSSHConfig reads Host blocks from an ssh_config file, following Include
+// directives in place. Group comments apply until the next one in the same file.
+func parseSSHConfig(path, marker string, depth int) ([]sshHost, error) {
+	if depth > maxSSHConfigDepth {
+		return nil, fmt.Errorf("%s: Include nested too deeply", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if depth > 0 && os.IsNotExist(err) {
+			// ssh ignores missing Includes
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var (
+		hosts   []sshHost
+		current []int // Indexes in hosts of the aliases of the open Host block
+		group   string
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if comment, ok := strings.CutPrefix(line, "#"); ok {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(comment), marker); ok {
+				group = strings.TrimSpace(name)
+			}
+			continue
+		}
+		keyword, value := splitSSHConfigLine(line)
+		if keyword == "" {
+			continue
+		}
+
+		switch strings.ToLower(keyword) {
+		case "host":
+			current = nil
+			for _, alias := range strings.Fields(value) {
+				if strings.ContainsAny(alias, "*?!") {
+					// Patterns configure other hosts; they aren't hosts themselves
+					continue
+				}
+				current = append(current, len(hosts))
+				hosts = append(hosts, sshHost{Alias: alias, Group: group})
+			}
+		case "match":
+			current = nil
+		case "include":
+			for _, pattern := range strings.Fields(value) {
+				included, err := includeSSHConfig(path, pattern, marker, depth)
+				if err != nil {
+					return nil, err
+				}
+				hosts = append(hosts, included...)
+			}
+		default:
+			for _, option := range sshHostOptions {
+				if !strings.EqualFold(keyword, option) {
+					continue
+				}
+				for _, i := range current {
+					if hosts[i].option(option) == "" {
+						hosts[i].Options = append(hosts[i].Options, ResourceField{Label: option, Value: value})
+					}
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hosts, nil
+}
+
+// includeSSHConfig parses the files an Include pattern names; relative
+// patterns are resolved against ~/.ssh like ssh does for user configs
+func includeSSHConfig(from, pattern, marker string, depth int) ([]sshHost, error) {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		pattern = filepath.Join(home, ".ssh", pattern)
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad Include %q: %w", from, pattern, err)
+	}
+
+	var hosts []sshHost
+	for _, path := range paths {
+		included, err := parseSSHConfig(path, marker, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, included...)
+	}
+	return hosts, nil
+}
+
+// splitSSHConfigLine splits "Keyword value" or "Keyword=value", unquoting the value
+func splitSSHConfigLine(line string) (string, string) {
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return line, ""
+	}
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return line[:end], strings.Trim(value, `"`)
+}
+
+// option returns a host option already set by an earlier line
+func (h sshHost) option(name string) string {
+	for _, field := range h.Options {
+		if field.Label == name {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// connectToSSHConfigHost runs ssh with the alias so ssh_config applies in full
+func (m model) connectToSSHConfigHost(node *TreeNode) (tea.Model, tea.Cmd) {
+	args, err := sshArgs("", node.Name)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
+
+// sshArgs builds an ssh command line for user@host after the given options,
+// refusing a host or user that ssh would read as an option
+func sshArgs(user, host string, options ...string) ([]string, error) {
+	if host == "" || strings.HasPrefix(host, "-") || strings.ContainsAny(host, " \t@") {
+		return nil, fmt.Errorf("not connecting to invalid host %q", host)
+	}
+	if strings.HasPrefix(user, "-") || strings.ContainsAny(user, " \t@") {
+		return nil, fmt.Errorf("not connecting as invalid user %q", user)
+	}
+	target := host
+	if user != "" {
+		target = user + "@" + host
+	}
+	args := append([]string{"ssh"}, options...)
+	return append(args, "--", target), nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SSHFS MOUNTS
+// =============================================================================
+
+// SSHFSConfig tunes sshfs mounts
+type SSHFSConfig struct {
+	// Mounts go to <mount_dir>/<vm>, default is mnt in the werkroom cache directory
+	MountDir string `yaml:"mount_dir"`
+}
+
+// SSHFSResolvedMsg carries the ssh options gcloud would use for a VM
+type SSHFSResolvedMsg struct {
+	VM          VM
+	Destination string
+	Options     []string
+	RemotePath  string
+	Err         error
+}
+
+// ssh flags whose value is the next argument, and the ssh_config option each sets
+var sshValueFlags = map[string]string{
+	"-i": "IdentityFile",
+	"-p": "Port",
+	"-l": "User",
+	"-J": "ProxyJump",
+	"-F": "",
+	"-o": "",
+}
+
+// startMount asks for the remote directory to mount
+func (m model) startMount(node *TreeNode) (tea.Model, tea.Cmd) {
+	if runtime.GOOS == "windows" {
+		m.statusMsg = "sshfs mounts are not supported on Windows"
+		return m, nil
+	}
+	if _, err := exec.LookPath("sshfs"); err != nil {
+		m.statusMsg = "sshfs not found in PATH"
+		return m, nil
+	}
+	vm := *node.VM
+	return m.askInput(fmt.Sprintf("Remote directory on %s to mount (empty for home)", vm.Name), "", func(m model, path string) (tea.Model, tea.Cmd) {
+		m.statusMsg = fmt.Sprintf("Resolving SSH connection to %s...", vm.Name)
+		return m, m.gcpService.ResolveSSHFS(m.selectedProject, vm, strings.TrimSpace(path))
+	})
+}
+
+// ResolveSSHFS resolves the connection sshfs should use for a VM
+func (gcp *GCPService) ResolveSSHFS(project string, vm VM, remotePath string) tea.Cmd {
+	return func() tea.Msg {
+		destination, options, err := gcp.resolveSSH(project, vm)
+		if err != nil {
+			return SSHFSResolvedMsg{VM: vm, Err: err}
+		}
+		return SSHFSResolvedMsg{VM: vm, Destination: destination, Options: options, RemotePath: remotePath}
+	}
+}
+
+// resolveSSH asks gcloud for the ssh command it would run, so other ssh-based
+// tools can reuse its keys, user, host key alias and any IAP proxy
+func (gcp *GCPService) resolveSSH(project string, vm VM) (string, []string, error) {
+	args := append(gcp.SSHArgs(project, vm)[1:], "--dry-run")
+	output, err := gcp.runGcloud(args...)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve SSH connection: %w", err)
+	}
+	return parseSSHCommand(strings.TrimSpace(string(output)))
+}
+
+// parseSSHCommand turns an ssh command line into its destination and ssh_config options
+func parseSSHCommand(command string) (string, []string, error) {
+	words := splitCommandLine(command)
+	if len(words) < 2 {
+		return "", nil, fmt.Errorf("unexpected ssh command from gcloud: %q", command)
+	}
+	var options []string
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		option, takesValue := sshValueFlags[word]
+		switch {
+		case takesValue && i+1 < len(words):
+			i++
+			if word == "-o" {
+				// ssh takes "-o Key Value" too, but sshfs only Key=Value
+				value := words[i]
+				if !strings.Contains(value, "=") {
+					value = strings.Replace(value, " ", "=", 1)
+				}
+				options = append(options, value)
+			} else if option != "" {
+				options = append(options, option+"="+words[i])
+			}
+		case strings.HasPrefix(word, "-o") && len(word) > 2:
+			// Joined form, as in --ssh-flag=-oProxyJump=host
+			options = append(options, word[2:])
+		case strings.HasPrefix(word, "-"):
+			// Flags like -t only matter for interactive sessions
+		default:
+			return word, options, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no destination in ssh command from gcloud: %q", command)
+}
+
+// splitCommandLine splits a command line into words, honouring quotes and backslashes
+func splitCommandLine(line string) []string {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+// mountDir returns where a VM's filesystem is mounted
+func (c SSHFSConfig) mountDir(vm VM) (string, error) {
+	dir := expandHome(c.MountDir)
+	if dir == "" {
+		cache, err := cacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cache, "mnt")
+	}
+	return filepath.Join(dir, vm.Name), nil
+}
+
+// unmountArgs returns the command that cleanly unmounts a FUSE mount
+func unmountArgs(mountpoint string) []string {
+	if runtime.GOOS != "linux" {
+		return []string{"umount", mountpoint}
+	}
+	if _, err := exec.LookPath("fusermount3"); err == nil {
+		return []string{"fusermount3", "-u", mountpoint}
+	}
+	return []string{"fusermount", "-u", mountpoint}
+}
+
+// handleSSHFSResolved starts sshfs in the foreground so it is tracked like a tunnel
+func (m model) handleSSHFSResolved(msg SSHFSResolvedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	mountpoint, err := m.config.SSHFS.mountDir(msg.VM)
+	if err == nil {
+		err = os.MkdirAll(mountpoint, 0o755)
+	}
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("Failed to create mount point: %v", err)
+		return m, nil
+	}
+	if entries, _ := os.ReadDir(mountpoint); len(entries) > 0 {
+		m.statusMsg = fmt.Sprintf("%s is not empty; is it still mounted?", mountpoint)
+		return m, nil
+	}
+
+	args := []string{"sshfs", "-f", "-o", "reconnect"}
+	for _, option := range msg.Options {
+		args = append(args, "-o", option)
+	}
+	args = append(args, msg.Destination+":"+msg.RemotePath, mountpoint)
+
+	tunnel, wait, err := m.tunnelManager.Start("sshfs", msg.VM.Name, mountpoint, args)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	// Killing sshfs would leave a dead mount behind
+	tunnel.stopArgs = unmountArgs(mountpoint)
+	m.statusMsg = fmt.Sprintf("Mounting %s at %s (T to unmount)", msg.VM.Name, mountpoint)
+	return m, wait
+}
@@ -0,0 +1,54 @@
+package main
+
+import "fmt"
+
+// =============================================================================
+// SSH USER MAPPING
+// =============================================================================
+
+// SSHUserRule sets the SSH user and extra gcloud flags for matching VMs; the first match wins
+type SSHUserRule struct {
+	// Project ID glob or /regexp/; empty matches any
+	Project string `yaml:"project"`
+	// Instance name glob or /regexp/; empty matches any
+	Host string `yaml:"host"`
+	// Remote user name, as in gcloud compute ssh user@vm
+	User string `yaml:"user"`
+	// Extra gcloud compute ssh flags, e.g. --ssh-flag=-A or --internal-ip
+	Flags []string `yaml:"flags"`
+}
+
+// validate checks the rule when the config is loaded
+func (r SSHUserRule) validate() error {
+	if r.User == "" && len(r.Flags) == 0 {
+		return fmt.Errorf("needs a user or flags")
+	}
+	for _, pattern := range []string{r.Project, r.Host} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compilePatterns([]string{pattern}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patternMatches reports whether an optional config pattern matches the value
+func patternMatches(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	patterns, err := compilePatterns([]string{pattern})
+	return err == nil && patterns.Match(value)
+}
+
+// sshUserRule finds the rule for a VM
+func (gcp *GCPService) sshUserRule(project string, vm VM) (SSHUserRule, bool) {
+	for _, rule := range gcp.sshUsers {
+		if patternMatches(rule.Project, project) && patternMatches(rule.Host, vm.Name) {
+			return rule, true
+		}
+	}
+	return SSHUserRule{}, false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// SSHUTTLE
+// =============================================================================
+
+// SSHuttleConfig sets the networks sshuttle routes through a VM
+type SSHuttleConfig struct {
+	// CIDRs offered when starting sshuttle, default 10.0.0.0/8
+	CIDRs []string `yaml:"cidrs"`
+}
+
+// SSHuttleResolvedMsg carries the ssh options gcloud would use, for sshuttle
+type SSHuttleResolvedMsg struct {
+	VM          VM
+	Destination string
+	Options     []string
+	CIDRs       []string
+	Err         error
+}
+
+// startSSHuttle asks which networks to route through the VM
+func (m model) startSSHuttle(node *TreeNode) (tea.Model, tea.Cmd) {
+	if runtime.GOOS == "windows" {
+		m.statusMsg = "sshuttle is not supported on Windows"
+		return m, nil
+	}
+	if _, err := exec.LookPath("sshuttle"); err != nil {
+		m.statusMsg = "sshuttle not found in PATH"
+		return m, nil
+	}
+	// sshuttle runs its firewall helper with sudo, whose password prompt
+	// would land in the middle of the TUI
+	if err := exec.Command("sudo", "-n", "true").Run(); err != nil {
+		m.statusMsg = "sshuttle needs sudo; run `sudo -v` first, then try again"
+		return m, nil
+	}
+
+	cidrs := m.config.SSHuttle.CIDRs
+	if len(cidrs) == 0 {
+		cidrs = []string{"10.0.0.0/8"}
+	}
+	vm := *node.VM
+	prompt := fmt.Sprintf("Networks to route through %s (space-separated CIDRs)", vm.Name)
+	return m.askInput(prompt, strings.Join(cidrs, " "), func(m model, value string) (tea.Model, tea.Cmd) {
+		cidrs := strings.Fields(value)
+		if len(cidrs) == 0 {
+			m.statusMsg = "No networks given"
+			return m, nil
+		}
+		for _, cidr := range cidrs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				m.statusMsg = fmt.Sprintf("Invalid network %q: expected a CIDR like 10.0.0.0/8", cidr)
+				return m, nil
+			}
+		}
+		m.statusMsg = fmt.Sprintf("Resolving SSH connection to %s...", vm.Name)
+		gcp, project := m.gcpService, m.selectedProject
+		return m, func() tea.Msg {
+			destination, options, err := gcp.resolveSSH(project, vm)
+			return SSHuttleResolvedMsg{VM: vm, Destination: destination, Options: options, CIDRs: cidrs, Err: err}
+		}
+	})
+}
+
+// handleSSHuttleResolved starts sshuttle, tracked like a tunnel
+func (m model) handleSSHuttleResolved(msg SSHuttleResolvedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	// sshuttle splits --ssh-cmd like a shell, and IAP's ProxyCommand has spaces
+	sshCmd := []string{"ssh"}
+	for _, option := range msg.Options {
+		sshCmd = append(sshCmd, "-o", shellQuote(option))
+	}
+	args := append([]string{"sshuttle", "--ssh-cmd", strings.Join(sshCmd, " "), "-r", msg.Destination}, msg.CIDRs...)
+
+	tunnel, wait, err := m.tunnelManager.Start("sshuttle", msg.VM.Name, strings.Join(msg.CIDRs, " "), args)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	// Killed outright, sshuttle would leave its firewall rules behind
+	tunnel.interrupt = true
+	m.statusMsg = fmt.Sprintf("Routing %s through %s (T to stop)", strings.Join(msg.CIDRs, ", "), msg.VM.Name)
+	return m, wait
+}
+
+// shellQuote quotes a word for POSIX shell-style splitting if it needs it
+func shellQuote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\n'\"\\$`*?[]#~;&|<>(){}") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
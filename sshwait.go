@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// START AND WAIT FOR SSH
+// =============================================================================
+
+const (
+	// Limit for one readiness probe
+	sshProbeTimeout = 15 * time.Second
+	// Pause between probes while sshd is coming up
+	sshProbeInterval = 3 * time.Second
+	// Give up waiting for a freshly started instance's sshd after this long
+	sshStartTimeout = 3 * time.Minute
+	// Default for ssh_probe_timeout, when probing before every connection
+	sshProbeWaitTimeout = 30 * time.Second
+)
+
+// sshWait tracks an instance whose sshd is being probed before connecting
+type sshWait struct {
+	VM       VM
+	Attempt  int
+	Timeout  time.Duration
+	Deadline time.Time
+}
+
+// SSHProbedMsg reports one readiness probe
+type SSHProbedMsg struct {
+	VM  VM
+	Err error
+}
+
+// SSHProbeTickMsg asks for the next probe
+type SSHProbeTickMsg struct{}
+
+// offerStartAndConnect asks to start a stopped or suspended VM and connect once sshd answers
+func (m model) offerStartAndConnect(vm VM) (tea.Model, tea.Cmd) {
+	status := VMStatus(vm.Status)
+	if m.config.ReadOnly {
+		m.statusMsg = fmt.Sprintf("%s is %s and starting instances is disabled in read-only mode", vm.Name, status)
+		return m, nil
+	}
+	verb := "Start"
+	if status == StatusSuspended {
+		verb = "Resume"
+	}
+	prompt := fmt.Sprintf("%s is %s. %s it and connect once SSH is up?", vm.Name, status, verb)
+	return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+		m = m.watchUntilRunning(vm)
+		m.connectWhenRunning = markKey(vm)
+		if status == StatusSuspended {
+			m.statusMsg = fmt.Sprintf("Resuming %s...", vm.Name)
+			return m, m.gcpService.SetSuspended(m.selectedProject, vm, false)
+		}
+		m.statusMsg = fmt.Sprintf("Starting %s...", vm.Name)
+		return m, m.gcpService.StartVM(m.selectedProject, vm)
+	})
+}
+
+// waitForSSH probes the VM's sshd until it answers or the timeout passes, then connects
+func (m model) waitForSSH(vm VM, timeout time.Duration) (model, tea.Cmd) {
+	m.sshWait = &sshWait{VM: vm, Attempt: 1, Timeout: timeout, Deadline: time.Now().Add(timeout)}
+	m.statusMsg = fmt.Sprintf("Waiting for sshd on %s…", vm.Name)
+	return m, m.gcpService.ProbeSSH(m.selectedProject, vm)
+}
+
+// probeTimeout is how long to wait for sshd before an ordinary connection
+func (c Config) probeTimeout() time.Duration {
+	if c.SSHProbeTimeout > 0 {
+		return c.SSHProbeTimeout
+	}
+	return sshProbeWaitTimeout
+}
+
+// handleSSHProbed connects once sshd answers, or schedules another probe
+func (m model) handleSSHProbed(msg SSHProbedMsg) (tea.Model, tea.Cmd) {
+	if m.sshWait == nil || markKey(m.sshWait.VM) != markKey(msg.VM) {
+		// Cancelled, or the user moved on
+		return m, nil
+	}
+	wait := *m.sshWait
+	if msg.Err == nil {
+		m.sshWait = nil
+		m.sshReady = markKey(wait.VM)
+		m.statusMsg = ""
+		vm := wait.VM
+		return m.connectToVM(&vm)
+	}
+	debugf("ssh probe %d for %s: %v", wait.Attempt, msg.VM.Name, msg.Err)
+	if time.Now().After(wait.Deadline) {
+		m.sshWait = nil
+		// The next Enter connects without probing
+		m.sshReady = markKey(wait.VM)
+		m.statusMsg = fmt.Sprintf("sshd on %s didn't answer within %s (%v); press Enter to try anyway", msg.VM.Name, wait.Timeout, msg.Err)
+		return m, nil
+	}
+
+	wait.Attempt++
+	m.sshWait = &wait
+	m.statusMsg = fmt.Sprintf("Waiting for sshd on %s… (attempt %d)", msg.VM.Name, wait.Attempt)
+	return m, tea.Tick(sshProbeInterval, func(time.Time) tea.Msg {
+		return SSHProbeTickMsg{}
+	})
+}
+
+// handleSSHProbeTick runs the next probe of the instance being waited for
+func (m model) handleSSHProbeTick() (tea.Model, tea.Cmd) {
+	if m.sshWait == nil {
+		return m, nil
+	}
+	return m, m.gcpService.ProbeSSH(m.selectedProject, m.sshWait.VM)
+}
+
+// ProbeSSH checks once whether the VM's sshd answers
+func (gcp *GCPService) ProbeSSH(project string, vm VM) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(gcp.baseContext(), sshProbeTimeout)
+		defer cancel()
+		return SSHProbedMsg{VM: vm, Err: gcp.probeSSH(ctx, project, vm)}
+	}
+}
+
+// probeSSH reads the SSH banner the way the session will reach the VM:
+// directly on its external (or, with --internal-ip, internal) address, or
+// through an IAP tunnel when iap is set or the VM has no external address
+func (gcp *GCPService) probeSSH(ctx context.Context, project string, vm VM) error {
+	if _, ok := gcp.bastionFor(project, vm); ok {
+		// Only the jump host could tell; leave it to ssh
+		return nil
+	}
+	rule, _ := gcp.sshUserRule(project, vm)
+	address := vm.ExternalIP()
+	if (address == "" && gcp.native) || slices.Contains(slices.Concat(gcp.sshFlags, rule.Flags), "--internal-ip") {
+		address = vm.InternalIP()
+	}
+	if gcp.forceIAP && !gcp.native {
+		// SSHArgs tunnels through IAP, so port 22 may well be closed to the outside
+		address = ""
+	}
+	if address != "" {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, "22"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		return readSSHBanner(conn)
+	}
+
+	cmd := exec.CommandContext(ctx, "gcloud", "compute", "start-iap-tunnel", vm.Name, "22",
+		"--listen-on-stdin", "--project", project, "--zone", vm.ZoneName())
+	// Held open: the tunnel closes once its stdin does
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	defer stdin.Close()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start IAP tunnel: %w", err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	return readSSHBanner(stdout)
+}
+
+// readSSHBanner waits for the server's identification line; servers may send
+// other lines before it
+func readSSHBanner(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for range 20 {
+		line, err := reader.ReadString('\n')
+		if strings.HasPrefix(line, "SSH-") {
+			return nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("connection closed before the SSH banner")
+			}
+			return err
+		}
+	}
+	return errors.New("no SSH banner")
+}
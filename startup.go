@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// STARTUP SCRIPT PREVIEW
+// =============================================================================
+
+// StartupScriptLoadedMsg carries the startup script of a VM
+type StartupScriptLoadedMsg struct {
+	VMName string
+	Source string
+	Script string
+	Err    error
+}
+
+var (
+	shellKeywordPattern  = regexp.MustCompile(`\b(if|then|else|elif|fi|for|while|until|do|done|case|esac|function|in|return|export|local|set)\b`)
+	shellVariablePattern = regexp.MustCompile(`\$\{?[A-Za-z_][A-Za-z0-9_]*\}?`)
+	shellStringPattern   = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+)
+
+// hasStartupScript reports whether the VM defines a startup script
+func (vm VM) hasStartupScript() bool {
+	return vm.GetMetadata("startup-script") != "" || vm.GetMetadata("startup-script-url") != ""
+}
+
+// LoadStartupScript returns the inline startup script or fetches the referenced one
+func (gcp *GCPService) LoadStartupScript(vm VM) tea.Cmd {
+	return func() tea.Msg {
+		if script := vm.GetMetadata("startup-script"); script != "" {
+			return StartupScriptLoadedMsg{VMName: vm.Name, Source: "metadata", Script: script}
+		}
+
+		url := vm.GetMetadata("startup-script-url")
+		output, err := gcp.runGcloud("storage", "cat", url)
+		if err != nil {
+			return StartupScriptLoadedMsg{VMName: vm.Name, Err: fmt.Errorf("failed to fetch %s: %w", url, err)}
+		}
+		return StartupScriptLoadedMsg{VMName: vm.Name, Source: url, Script: string(output)}
+	}
+}
+
+// highlightShell applies lightweight shell syntax highlighting line by line
+func (m model) highlightShell(script string) string {
+	lines := strings.Split(script, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			lines[i] = m.styles.Label.Render(line)
+			continue
+		}
+
+		// Strings first so keywords inside them stay plain
+		var parts []string
+		last := 0
+		for _, loc := range shellStringPattern.FindAllStringIndex(line, -1) {
+			parts = append(parts, m.highlightShellCode(line[last:loc[0]]))
+			parts = append(parts, m.styles.Provisioning.Render(line[loc[0]:loc[1]]))
+			last = loc[1]
+		}
+		parts = append(parts, m.highlightShellCode(line[last:]))
+		lines[i] = strings.Join(parts, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightShellCode colors keywords and variables in unquoted shell code
+func (m model) highlightShellCode(code string) string {
+	code = shellVariablePattern.ReplaceAllStringFunc(code, func(v string) string {
+		return m.styles.Expanded.Render(v)
+	})
+	return shellKeywordPattern.ReplaceAllStringFunc(code, func(k string) string {
+		return m.styles.Group.Bold(true).Render(k)
+	})
+}
+
+// showStartupScript loads the startup script of the selected VM
+func (m model) showStartupScript(node *TreeNode) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Loading startup script of %s...", node.VM.Name)
+	return m, m.gcpService.LoadStartupScript(*node.VM)
+}
+
+// handleStartupScriptLoaded opens the script in the viewer
+func (m model) handleStartupScriptLoaded(msg StartupScriptLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+
+	m.statusMsg = ""
+	title := fmt.Sprintf("Startup script of %s (%s)", msg.VMName, msg.Source)
+	return m.openViewer(title, m.highlightShell(msg.Script))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// STDIN PICKER
+// =============================================================================
+
+// JSON keys that shape an item rather than being shown as details
+var stdinItemKeys = map[string]bool{"name": true, "group": true, "address": true, "host": true, "user": true, "port": true}
+
+// readStdinItems reads stdin once; retries must not find it drained
+var readStdinItems = sync.OnceValues(func() ([]Resource, error) {
+	var items []Resource
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		items = append(items, parseStdinItem(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return items, nil
+})
+
+// parseStdinItem turns a line into an item; JSON objects may set name, group,
+// address (or host), user and port, and their other keys become details
+func parseStdinItem(line string) Resource {
+	item := Resource{Kind: KindStdin, Name: line, Fields: []ResourceField{{Label: "Input", Value: line}}}
+
+	var object map[string]any
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") || json.Unmarshal([]byte(line), &object) != nil {
+		return item
+	}
+	value := func(key string) string {
+		if v, ok := object[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	if name := value("name"); name != "" {
+		item.Name = name
+	}
+	item.Group = value("group")
+	address := value("address")
+	if address == "" {
+		address = value("host")
+	}
+	for _, field := range []ResourceField{{"Address", address}, {"User", value("user")}, {"Port", value("port")}} {
+		if field.Value != "" {
+			item.Fields = append(item.Fields, field)
+		}
+	}
+
+	var extra []string
+	for key := range object {
+		if !stdinItemKeys[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		item.Fields = append(item.Fields, ResourceField{Label: key, Value: value(key)})
+	}
+	return item
+}
+
+// LoadStdinItems lists the lines piped into werkroom
+func LoadStdinItems(config Config) tea.Cmd {
+	return func() tea.Msg {
+		items, err := readStdinItems()
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return ResourcesLoadedMsg{Kind: KindStdin, Resources: items}
+	}
+}
+
+// pickStdinItem exits and prints the chosen input line to stdout
+func (m model) pickStdinItem(node *TreeNode) (tea.Model, tea.Cmd) {
+	return m.launchAndQuit(Launch{Title: node.Name, Output: node.Resource.Field("Input"), Pick: true})
+}
+
+// connectToStdinItem opens SSH to the item's address, or its name when it has none
+func (m model) connectToStdinItem(node *TreeNode) (tea.Model, tea.Cmd) {
+	resource := node.Resource
+	host := resource.Field("Address")
+	if host == "" {
+		host = node.Name
+	}
+	var options []string
+	if port := resource.Field("Port"); port != "" {
+		options = append(options, "-p", port)
+	}
+	args, err := sshArgs(resource.Field("User"), host, options...)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
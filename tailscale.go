@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// TAILSCALE
+// =============================================================================
+
+// TailscaleConfig tunes connections to tailnet devices
+type TailscaleConfig struct {
+	// User for SSH into devices, default is the local user
+	SSHUser string `yaml:"ssh_user"`
+}
+
+// tailscalePeer is the part of a `tailscale status --json` node werkroom uses
+type tailscalePeer struct {
+	HostName     string   `json:"HostName"`
+	DNSName      string   `json:"DNSName"`
+	OS           string   `json:"OS"`
+	UserID       int64    `json:"UserID"`
+	TailscaleIPs []string `json:"TailscaleIPs"`
+	Tags         []string `json:"Tags"`
+	Online       bool     `json:"Online"`
+	// Set when the device runs Tailscale SSH
+	SSHHostKeys []string `json:"sshHostKeys"`
+}
+
+// tailscaleStatus is the output of `tailscale status --json`
+type tailscaleStatus struct {
+	BackendState string                   `json:"BackendState"`
+	Peer         map[string]tailscalePeer `json:"Peer"`
+	User         map[string]struct {
+		LoginName string `json:"LoginName"`
+	} `json:"User"`
+}
+
+// LoadTailscaleDevices lists the tailnet's devices, grouped by tag or, for untagged devices, by owner
+func LoadTailscaleDevices(config Config) tea.Cmd {
+	return func() tea.Msg {
+		output, err := runSourceCommand(config, "tailscale", "status", "--json")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to run tailscale status: %w", err)}
+		}
+		var status tailscaleStatus
+		if err := json.Unmarshal(output, &status); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse tailscale status: %w", err)}
+		}
+		if status.BackendState != "Running" {
+			return ErrorMsg{Err: fmt.Errorf("tailscale is not connected (state %s); run tailscale up", status.BackendState)}
+		}
+
+		var resources []Resource
+		for _, peer := range status.Peer {
+			resource := Resource{
+				Kind:   KindTailscale,
+				Name:   peer.HostName,
+				Status: string(StatusTerminated),
+				Fields: []ResourceField{
+					{Label: "IP", Value: strings.Join(peer.TailscaleIPs, ", ")},
+					{Label: "DNS name", Value: strings.TrimSuffix(peer.DNSName, ".")},
+					{Label: "OS", Value: peer.OS},
+				},
+			}
+			if peer.Online {
+				resource.Status = string(StatusRunning)
+			}
+			if len(peer.SSHHostKeys) > 0 {
+				resource.Fields = append(resource.Fields, ResourceField{Label: "SSH", Value: "Tailscale SSH"})
+			}
+
+			// A device with several tags is listed under each of them
+			groups := append([]string(nil), peer.Tags...)
+			if len(groups) == 0 {
+				owner := status.User[strconv.FormatInt(peer.UserID, 10)].LoginName
+				if owner == "" {
+					owner = "untagged"
+				}
+				groups = []string{owner}
+			}
+			sort.Strings(groups)
+			for _, group := range groups {
+				resource.Group = group
+				resources = append(resources, resource)
+			}
+		}
+		return ResourcesLoadedMsg{Kind: KindTailscale, Resources: resources}
+	}
+}
+
+// connectToTailscaleDevice opens SSH to an online device, through Tailscale SSH when it runs it
+func (m model) connectToTailscaleDevice(node *TreeNode) (tea.Model, tea.Cmd) {
+	resource := node.Resource
+	if VMStatus(resource.Status) != StatusRunning {
+		m.statusMsg = fmt.Sprintf("%s is offline", node.Name)
+		return m, nil
+	}
+	target, _, _ := strings.Cut(resource.Field("IP"), ", ")
+	if user := m.config.Tailscale.SSHUser; user != "" {
+		target = user + "@" + target
+	}
+	if resource.Field("SSH") == "Tailscale SSH" {
+		return m.launchAndQuit(Launch{Title: node.Name, Args: []string{"tailscale", "ssh", target}})
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: []string{"ssh", target}})
+}
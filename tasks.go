@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// TASKS
+// =============================================================================
+
+// How often running operations are polled
+const taskPollInterval = 5 * time.Second
+
+// task is a long-running Compute Engine operation started from werkroom
+type task struct {
+	ID      int
+	Title   string
+	Project string
+	// Operation name, and where it lives: "global", a zone or a region
+	Operation string
+	Scope     string
+	Status    string
+	Progress  int
+	Started   time.Time
+	Finished  time.Time
+	Err       error
+}
+
+// Running reports whether the operation hasn't finished yet
+func (t task) Running() bool {
+	return t.Finished.IsZero()
+}
+
+// TaskStartedMsg reports an operation started with --async
+type TaskStartedMsg struct {
+	Title     string
+	Project   string
+	Operation string
+	Scope     string
+	Err       error
+}
+
+// TaskPollMsg asks for the next status of a task
+type TaskPollMsg struct {
+	ID int
+}
+
+// TaskStatusMsg carries the current state of a task's operation
+type TaskStatusMsg struct {
+	ID       int
+	Status   string
+	Progress int
+	Err      error
+	// The operation finished with errors, as opposed to polling failing
+	Failed bool
+}
+
+// operation is the subset of a Compute Engine operation we use
+type operation struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+	Error    struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// scopeFlag returns the gcloud flag selecting where an operation lives
+func scopeFlag(scope string) []string {
+	switch {
+	case scope == "global":
+		return []string{"--global"}
+	case strings.Count(scope, "-") >= 2:
+		return []string{"--zone", scope}
+	default:
+		return []string{"--region", scope}
+	}
+}
+
+// StartOperation runs a gcloud command with --async and reports the operation it started
+func (gcp *GCPService) StartOperation(title, project, scope string, args ...string) tea.Cmd {
+	return func() tea.Msg {
+		args = append(args, "--project", project, "--async", "--format", "json(name)")
+		output, err := gcp.runGcloud(args...)
+		if err != nil {
+			return TaskStartedMsg{Title: title, Err: fmt.Errorf("%s failed to start: %w", title, err)}
+		}
+		// Depending on the command, gcloud prints one operation or a list
+		var ops []operation
+		if err := json.Unmarshal(output, &ops); err != nil {
+			var op operation
+			if err := json.Unmarshal(output, &op); err != nil {
+				return TaskStartedMsg{Title: title, Err: fmt.Errorf("failed to parse operation: %w", err)}
+			}
+			ops = []operation{op}
+		}
+		if len(ops) == 0 || ops[0].Name == "" {
+			return TaskStartedMsg{Title: title, Err: fmt.Errorf("gcloud returned no operation for %s", title)}
+		}
+		return TaskStartedMsg{Title: title, Project: project, Operation: ops[0].Name, Scope: scope}
+	}
+}
+
+// PollOperation describes a task's operation
+func (gcp *GCPService) PollOperation(t task) tea.Cmd {
+	return func() tea.Msg {
+		args := append([]string{"compute", "operations", "describe", t.Operation, "--project", t.Project}, scopeFlag(t.Scope)...)
+		output, err := gcp.runGcloud(append(args, "--format", "json")...)
+		if err != nil {
+			return TaskStatusMsg{ID: t.ID, Err: err}
+		}
+		var op operation
+		if err := json.Unmarshal(output, &op); err != nil {
+			return TaskStatusMsg{ID: t.ID, Err: fmt.Errorf("failed to parse operation: %w", err)}
+		}
+		msg := TaskStatusMsg{ID: t.ID, Status: op.Status, Progress: op.Progress}
+		if len(op.Error.Errors) > 0 {
+			var messages []string
+			for _, e := range op.Error.Errors {
+				messages = append(messages, e.Message)
+			}
+			msg.Err = fmt.Errorf("%s", strings.Join(messages, "; "))
+			msg.Failed = true
+		}
+		return msg
+	}
+}
+
+// pollTask schedules the next status check of a task
+func pollTask(id int) tea.Cmd {
+	return tea.Tick(taskPollInterval, func(time.Time) tea.Msg { return TaskPollMsg{ID: id} })
+}
+
+// handleTaskStarted adds the operation to the tasks panel
+func (m model) handleTaskStarted(msg TaskStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	id := 1
+	if len(m.tasks) > 0 {
+		id = m.tasks[len(m.tasks)-1].ID + 1
+	}
+	t := task{ID: id, Title: msg.Title, Project: msg.Project, Operation: msg.Operation, Scope: msg.Scope, Status: "PENDING", Started: time.Now()}
+	// Copy on write: the slice is shared with earlier model values
+	m.tasks = append(append([]task(nil), m.tasks...), t)
+	m.statusMsg = fmt.Sprintf("%s started (J for tasks)", msg.Title)
+	return m, pollTask(id)
+}
+
+// taskIndex finds a task by ID
+func (m model) taskIndex(id int) int {
+	for i, t := range m.tasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleTaskPoll checks on a running task
+func (m model) handleTaskPoll(msg TaskPollMsg) (tea.Model, tea.Cmd) {
+	i := m.taskIndex(msg.ID)
+	if i < 0 || !m.tasks[i].Running() {
+		return m, nil
+	}
+	return m, m.gcpService.PollOperation(m.tasks[i])
+}
+
+// handleTaskStatus records a task's progress and reports when it finishes
+func (m model) handleTaskStatus(msg TaskStatusMsg) (tea.Model, tea.Cmd) {
+	i := m.taskIndex(msg.ID)
+	if i < 0 {
+		return m, nil
+	}
+	if msg.Err != nil && !msg.Failed {
+		// Polling hiccup; the operation itself carries on
+		debugf("task %d: %v", msg.ID, msg.Err)
+		return m, pollTask(msg.ID)
+	}
+
+	tasks := append([]task(nil), m.tasks...)
+	t := &tasks[i]
+	t.Status, t.Progress, t.Err = msg.Status, msg.Progress, msg.Err
+	m.tasks = tasks
+	if msg.Status != "DONE" {
+		return m, pollTask(msg.ID)
+	}
+
+	t.Finished = time.Now()
+	result := fmt.Sprintf("%s finished in %s", t.Title, t.Finished.Sub(t.Started).Round(time.Second))
+	if t.Err != nil {
+		result = fmt.Sprintf("%s failed: %v", t.Title, t.Err)
+	}
+	m.statusMsg = result
+	return m, m.config.Notifications.notify("werkroom", result)
+}
+
+// handleTasksPanelKeys handles input while the tasks panel is open
+func (m model) handleTasksPanelKeys(keypress string) (tea.Model, tea.Cmd) {
+	switch keypress {
+	case "up", "k":
+		if m.taskCursor > 0 {
+			m.taskCursor--
+		}
+	case "down", "j":
+		if m.taskCursor < len(m.tasks)-1 {
+			m.taskCursor++
+		}
+	case "x":
+		// Only finished tasks are removed; operations can't be cancelled
+		if m.taskCursor < len(m.tasks) && !m.tasks[m.taskCursor].Running() {
+			tasks := append([]task(nil), m.tasks[:m.taskCursor]...)
+			m.tasks = append(tasks, m.tasks[m.taskCursor+1:]...)
+			if m.taskCursor > 0 {
+				m.taskCursor--
+			}
+		}
+	case "J", "esc":
+		m.showTasks = false
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderTasks renders the tasks panel
+func (m model) renderTasks() string {
+	lines := []string{m.styles.Prompt.Render("Tasks")}
+	if len(m.tasks) == 0 {
+		lines = append(lines, m.styles.Label.Render("No tasks started"))
+	}
+	for i, t := range m.tasks {
+		state := m.styles.Provisioning.Render(strings.ToLower(t.Status))
+		elapsed := time.Since(t.Started)
+		if t.Progress > 0 {
+			state += m.styles.Label.Render(fmt.Sprintf(" %d%%", t.Progress))
+		}
+		if !t.Running() {
+			elapsed = t.Finished.Sub(t.Started)
+			state = m.styles.Running.Render("done")
+			if t.Err != nil {
+				state = m.styles.Stopping.Render("failed: " + t.Err.Error())
+			}
+		}
+
+		cursor := "  "
+		if i == m.taskCursor {
+			cursor = "> "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s  %s  %s", cursor, t.Title,
+			m.styles.Label.Render(elapsed.Round(time.Second).String()), state))
+	}
+	lines = append(lines, m.styles.Label.Render("x to remove finished, J or Esc to close"))
+
+	return m.styles.Details.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// INSTANCE TEMPLATES
+// =============================================================================
+
+// Picker entries for what to create from a template
+const (
+	templateInstanceOption = "Instance"
+	templateGroupOption    = "Managed instance group"
+)
+
+// InstanceTemplate represents an instance template as returned by gcloud
+type InstanceTemplate struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	CreationTimestamp string `json:"creationTimestamp"`
+	// Set for regional templates only
+	Region     string `json:"region"`
+	Properties struct {
+		MachineType string `json:"machineType"`
+		Disks       []struct {
+			Boot             bool `json:"boot"`
+			InitializeParams struct {
+				SourceImage string `json:"sourceImage"`
+				DiskSizeGB  string `json:"diskSizeGb"`
+				DiskType    string `json:"diskType"`
+			} `json:"initializeParams"`
+		} `json:"disks"`
+		NetworkInterfaces []struct {
+			Network       string            `json:"network"`
+			Subnetwork    string            `json:"subnetwork"`
+			AccessConfigs []json.RawMessage `json:"accessConfigs"`
+		} `json:"networkInterfaces"`
+		ServiceAccounts []ServiceAccount `json:"serviceAccounts"`
+		Scheduling      struct {
+			ProvisioningModel string `json:"provisioningModel"`
+		} `json:"scheduling"`
+		Tags struct {
+			Items []string `json:"items"`
+		} `json:"tags"`
+	} `json:"properties"`
+}
+
+// LoadInstanceTemplates loads the project's global and regional instance templates
+func (gcp *GCPService) LoadInstanceTemplates(project string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "instance-templates", "list",
+			"--project", project,
+			"--format", "json(name,description,creationTimestamp,region,properties.machineType,properties.disks,"+
+				"properties.networkInterfaces,properties.serviceAccounts,properties.scheduling,properties.tags)")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list instance templates: %w", err)}
+		}
+
+		var templates []InstanceTemplate
+		if err := json.Unmarshal(output, &templates); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse instance template data: %w", err)}
+		}
+
+		resources := make([]Resource, len(templates))
+		for i, template := range templates {
+			location := "global"
+			if template.Region != "" {
+				location = path.Base(template.Region)
+			}
+			resources[i] = Resource{
+				Kind:     KindInstanceTemplates,
+				Name:     template.Name,
+				Location: location,
+				Group:    location,
+				Fields:   template.fields(),
+			}
+		}
+		return ResourcesLoadedMsg{Kind: KindInstanceTemplates, Resources: resources}
+	}
+}
+
+// fields returns the template's key properties for the details pane
+func (t InstanceTemplate) fields() []ResourceField {
+	props := t.Properties
+	fields := []ResourceField{
+		{Label: "Description", Value: t.Description},
+		{Label: "Machine type", Value: path.Base(props.MachineType)},
+	}
+	for _, disk := range props.Disks {
+		if !disk.Boot {
+			continue
+		}
+		params := disk.InitializeParams
+		if params.SourceImage != "" {
+			fields = append(fields, ResourceField{Label: "Image", Value: path.Base(params.SourceImage)})
+		}
+		if params.DiskSizeGB != "" {
+			size := params.DiskSizeGB + " GB"
+			if params.DiskType != "" {
+				size += " " + path.Base(params.DiskType)
+			}
+			fields = append(fields, ResourceField{Label: "Boot disk", Value: size})
+		}
+	}
+	if extra := len(props.Disks) - 1; extra > 0 {
+		fields = append(fields, ResourceField{Label: "Additional disks", Value: strconv.Itoa(extra)})
+	}
+	for _, nic := range props.NetworkInterfaces {
+		network := path.Base(nic.Network)
+		if nic.Subnetwork != "" {
+			network += "/" + path.Base(nic.Subnetwork)
+		}
+		if len(nic.AccessConfigs) > 0 {
+			network += " (external IP)"
+		}
+		fields = append(fields, ResourceField{Label: "Network", Value: network})
+	}
+	for _, account := range props.ServiceAccounts {
+		fields = append(fields, ResourceField{Label: "Service account", Value: account.Email})
+	}
+	if props.Scheduling.ProvisioningModel == "SPOT" {
+		fields = append(fields, ResourceField{Label: "Provisioning", Value: "Spot"})
+	}
+	if len(props.Tags.Items) > 0 {
+		fields = append(fields, ResourceField{Label: "Network tags", Value: strings.Join(props.Tags.Items, ", ")})
+	}
+	if created, err := time.Parse(time.RFC3339, t.CreationTimestamp); err == nil {
+		fields = append(fields, ResourceField{Label: "Created", Value: fmt.Sprintf("%s (%s ago)",
+			created.Local().Format("2006-01-02 15:04"), formatAge(time.Since(created)))})
+	}
+	return fields
+}
+
+// templateURL returns the reference gcloud needs for a global or regional template
+func templateURL(project string, template *Resource) string {
+	if template.Location == "global" {
+		return template.Name
+	}
+	return fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/instanceTemplates/%s",
+		project, template.Location, template.Name)
+}
+
+// canCreateFromTemplate reports whether the node is a template to create from
+func canCreateFromTemplate(m model, node *TreeNode) bool {
+	return isResourceOf(KindInstanceTemplates)(m, node) && !m.gcpService.native
+}
+
+// createFromTemplate is Enter on a template, going through the action's guard rails
+func (m model) createFromTemplate(node *TreeNode) (tea.Model, tea.Cmd) {
+	if action := m.findAction("l", node); action != nil {
+		return m.runAction(*action, node)
+	}
+	m.statusMsg = "Creating instances is disabled"
+	return m, nil
+}
+
+// TemplateZonesLoadedMsg carries the zones a template can be used in
+type TemplateZonesLoadedMsg struct {
+	Template *Resource
+	Group    bool
+	Zones    []string
+	Err      error
+}
+
+// startCreateFromTemplate asks whether to create an instance or a managed instance group
+func (m model) startCreateFromTemplate(node *TreeNode) (tea.Model, tea.Cmd) {
+	template := node.Resource
+	options := []string{templateInstanceOption, templateGroupOption}
+	return m.askPick(fmt.Sprintf("Create from %s", template.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		m.statusMsg = "Loading zones..."
+		return m, m.gcpService.ListTemplateZones(m.selectedProject, template, option == templateGroupOption)
+	})
+}
+
+// ListTemplateZones lists the zones of the project, limited to the region of a regional template
+func (gcp *GCPService) ListTemplateZones(project string, template *Resource, group bool) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "zones", "list",
+			"--project", project,
+			"--format", "value(name)")
+		if err != nil {
+			return TemplateZonesLoadedMsg{Err: fmt.Errorf("failed to list zones: %w", err)}
+		}
+		var zones []string
+		for _, zone := range strings.Fields(string(output)) {
+			if template.Location == "global" || zoneRegion(zone) == template.Location {
+				zones = append(zones, zone)
+			}
+		}
+		sort.Strings(zones)
+		return TemplateZonesLoadedMsg{Template: template, Group: group, Zones: zones}
+	}
+}
+
+// zoneRegion returns the region of a zone, e.g. europe-west1 for europe-west1-b
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+// handleTemplateZonesLoaded asks where to create the instance or group.
+// Groups may also span a whole region.
+func (m model) handleTemplateZonesLoaded(msg TemplateZonesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.statusMsg = msg.Err.Error()
+		return m, nil
+	}
+	if len(msg.Zones) == 0 {
+		m.statusMsg = fmt.Sprintf("No zones available for %s", msg.Template.Name)
+		return m, nil
+	}
+	m.statusMsg = ""
+
+	const regionalSuffix = " (regional, spread across zones)"
+	var options []string
+	for i, zone := range msg.Zones {
+		if msg.Group && (i == 0 || zoneRegion(msg.Zones[i-1]) != zoneRegion(zone)) {
+			options = append(options, zoneRegion(zone)+regionalSuffix)
+		}
+		options = append(options, zone)
+	}
+	template, group := msg.Template, msg.Group
+	return m.askPick(fmt.Sprintf("Where to create from %s?", template.Name), options, func(m model, option string) (tea.Model, tea.Cmd) {
+		location := strings.TrimSuffix(option, regionalSuffix)
+		if group {
+			return m.askGroupFromTemplate(template, location)
+		}
+		return m.askInstanceFromTemplate(template, location)
+	})
+}
+
+// suggestedName proposes a name derived from the template
+func suggestedName(template *Resource) string {
+	name, err := resourceName(template.Name + "-" + time.Now().Format("0102-1504"))
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// askInstanceFromTemplate asks for the instance name and confirms
+func (m model) askInstanceFromTemplate(template *Resource, zone string) (tea.Model, tea.Cmd) {
+	return m.askInput("Instance name", suggestedName(template), func(m model, text string) (tea.Model, tea.Cmd) {
+		name, err := resourceName(text)
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		prompt := fmt.Sprintf("Create instance %s in %s from %s?", name, zone, template.Name)
+		return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+			return m, m.gcpService.CreateInstanceFromTemplate(m.selectedProject, template, name, zone)
+		})
+	})
+}
+
+// askGroupFromTemplate asks for the group name and size, then confirms
+func (m model) askGroupFromTemplate(template *Resource, location string) (tea.Model, tea.Cmd) {
+	return m.askInput("Group name", suggestedName(template), func(m model, text string) (tea.Model, tea.Cmd) {
+		name, err := resourceName(text)
+		if err != nil {
+			m.statusMsg = err.Error()
+			return m, nil
+		}
+		return m.askInput("Number of instances", "1", func(m model, text string) (tea.Model, tea.Cmd) {
+			size, err := strconv.Atoi(strings.TrimSpace(text))
+			if err != nil || size < 0 {
+				m.statusMsg = fmt.Sprintf("Invalid number of instances %q", text)
+				return m, nil
+			}
+			prompt := fmt.Sprintf("Create group %s with %d instance(s) in %s from %s?", name, size, location, template.Name)
+			return m.askConfirm(prompt, func(m model) (tea.Model, tea.Cmd) {
+				m.statusMsg = fmt.Sprintf("Creating group %s...", name)
+				return m, m.gcpService.CreateGroupFromTemplate(m.selectedProject, template, name, location, size)
+			})
+		})
+	})
+}
+
+// CreateInstanceFromTemplate starts an instance from the template, tracked as a task
+func (gcp *GCPService) CreateInstanceFromTemplate(project string, template *Resource, name, zone string) tea.Cmd {
+	return gcp.StartOperation("Instance "+name, project, zone,
+		"compute", "instances", "create", name,
+		"--zone", zone,
+		"--source-instance-template", templateURL(project, template))
+}
+
+// CreateGroupFromTemplate creates a zonal or regional managed instance group
+func (gcp *GCPService) CreateGroupFromTemplate(project string, template *Resource, name, location string, size int) tea.Cmd {
+	return func() tea.Msg {
+		args := []string{"compute", "instance-groups", "managed", "create", name,
+			"--project", project,
+			"--template", templateURL(project, template),
+			"--size", strconv.Itoa(size)}
+		args = append(args, scopeFlag(location)...)
+		if _, err := gcp.runGcloud(args...); err != nil {
+			return OperationDoneMsg{Err: fmt.Errorf("failed to create group %s: %w", name, err)}
+		}
+		return OperationDoneMsg{Description: fmt.Sprintf("Created group %s in %s", name, location)}
+	}
+}
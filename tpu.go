@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// TPU VMS
+// =============================================================================
+
+// TPUNode represents a Cloud TPU VM as returned by gcloud
+type TPUNode struct {
+	Name             string `json:"name"`
+	State            string `json:"state"`
+	AcceleratorType  string `json:"acceleratorType"`
+	NetworkEndpoints []struct {
+		IPAddress string `json:"ipAddress"`
+	} `json:"networkEndpoints"`
+}
+
+// LoadTPUs loads TPU VMs from every TPU location of the project, grouped by zone
+func (gcp *GCPService) LoadTPUs(project string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "tpus", "locations", "list",
+			"--project", project,
+			"--format", "value(locationId)")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list TPU locations: %w", err)}
+		}
+
+		resources, err := fanOut(strings.Fields(string(output)), func(zone string) ([]Resource, error) {
+			return gcp.listTPUs(project, zone)
+		})
+		if err != nil && len(resources) == 0 {
+			return ErrorMsg{Err: err}
+		}
+		return ResourcesLoadedMsg{Kind: KindTPUs, Resources: resources}
+	}
+}
+
+// listTPUs lists the TPU VMs of a single zone
+func (gcp *GCPService) listTPUs(project, zone string) ([]Resource, error) {
+	output, err := gcp.runGcloud("compute", "tpus", "tpu-vm", "list",
+		"--project", project,
+		"--zone", zone,
+		"--format", "json(name,state,acceleratorType,networkEndpoints)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list TPU VMs in %s: %w", zone, err)
+	}
+
+	var nodes []TPUNode
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse TPU data: %w", err)
+	}
+
+	resources := make([]Resource, len(nodes))
+	for i, node := range nodes {
+		// READY is the TPU equivalent of a running VM
+		status := node.State
+		if status == "READY" {
+			status = string(StatusRunning)
+		}
+
+		resources[i] = Resource{
+			Kind:     KindTPUs,
+			Name:     path.Base(node.Name),
+			Location: zone,
+			Status:   status,
+			Group:    zone,
+			Fields: []ResourceField{
+				{Label: "Accelerator", Value: node.AcceleratorType},
+				{Label: "Workers", Value: strconv.Itoa(len(node.NetworkEndpoints))},
+			},
+		}
+	}
+	return resources, nil
+}
+
+// tpuSSHArgs returns the gcloud command line that opens an SSH session to a TPU worker
+func (gcp *GCPService) tpuSSHArgs(project string, tpu Resource, worker string) []string {
+	return []string{
+		"gcloud", "compute", "tpus", "tpu-vm", "ssh", tpu.Name,
+		"--project", project,
+		"--zone", tpu.Location,
+		"--worker", worker,
+	}
+}
+
+// connectToTPU connects to a TPU VM, asking which worker to use for pods
+func (m model) connectToTPU(node *TreeNode) (tea.Model, tea.Cmd) {
+	tpu := *node.Resource
+	workers, _ := strconv.Atoi(tpu.Field("Workers"))
+
+	if workers <= 1 {
+		return m.launchAndQuit(Launch{
+			Title: tpu.Name,
+			Args:  m.gcpService.tpuSSHArgs(m.selectedProject, tpu, "0"),
+		})
+	}
+
+	prompt := fmt.Sprintf("Worker for %s (0-%d or 'all')", tpu.Name, workers-1)
+	return m.askInput(prompt, "0", func(m model, value string) (tea.Model, tea.Cmd) {
+		worker := strings.TrimSpace(value)
+		if index, err := strconv.Atoi(worker); worker != "all" && (err != nil || index < 0 || index >= workers) {
+			m.statusMsg = fmt.Sprintf("Invalid worker %q", value)
+			return m, nil
+		}
+
+		return m.launchAndQuit(Launch{
+			Title: fmt.Sprintf("%s worker %s", tpu.Name, worker),
+			Args:  m.gcpService.tpuSSHArgs(m.selectedProject, tpu, worker),
+		})
+	})
+}
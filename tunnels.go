@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// TUNNELS
+// =============================================================================
+
+// Tunnel is a long-running background process started from werkroom
+type Tunnel struct {
+	ID        int
+	Kind      string
+	Target    string
+	LocalAddr string
+	StartedAt time.Time
+	Running   bool
+	Err       error
+
+	cmd    *exec.Cmd
+	output *syncBuffer
+	// Ends the tunnel cleanly instead of killing it, e.g. an unmount
+	stopArgs []string
+	// Stop with an interrupt so the process can clean up after itself
+	interrupt bool
+}
+
+// syncBuffer collects process output safely across goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// LastLine returns the last non-empty line written
+func (b *syncBuffer) LastLine() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+	return lines[len(lines)-1]
+}
+
+// TunnelExitedMsg indicates a tunnel process exited
+type TunnelExitedMsg struct {
+	ID  int
+	Err error
+}
+
+// TunnelManager tracks tunnels for the lifetime of the program
+type TunnelManager struct {
+	mu      sync.Mutex
+	tunnels []*Tunnel
+	nextID  int
+}
+
+// NewTunnelManager creates a new tunnel manager
+func NewTunnelManager() *TunnelManager {
+	return &TunnelManager{nextID: 1}
+}
+
+// Start launches a tunnel process and returns a command reporting its exit
+func (tm *TunnelManager) Start(kind, target, localAddr string, args []string) (*Tunnel, tea.Cmd, error) {
+	output := &syncBuffer{}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Start()
+	auditCommand("tunnel", target, args, time.Now(), err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start %s: %w", args[0], err)
+	}
+
+	tm.mu.Lock()
+	tunnel := &Tunnel{
+		ID:        tm.nextID,
+		Kind:      kind,
+		Target:    target,
+		LocalAddr: localAddr,
+		StartedAt: time.Now(),
+		Running:   true,
+		cmd:       cmd,
+		output:    output,
+	}
+	tm.nextID++
+	tm.tunnels = append(tm.tunnels, tunnel)
+	tm.mu.Unlock()
+
+	wait := func() tea.Msg {
+		err := cmd.Wait()
+		if err != nil {
+			if line := output.LastLine(); line != "" {
+				err = fmt.Errorf("%w: %s", err, line)
+			}
+		}
+		return TunnelExitedMsg{ID: tunnel.ID, Err: err}
+	}
+	return tunnel, wait, nil
+}
+
+// Tunnels returns all tracked tunnels, running or not
+func (tm *TunnelManager) Tunnels() []*Tunnel {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return append([]*Tunnel{}, tm.tunnels...)
+}
+
+// Running returns the number of running tunnels
+func (tm *TunnelManager) Running() int {
+	count := 0
+	for _, tunnel := range tm.Tunnels() {
+		if tunnel.Running {
+			count++
+		}
+	}
+	return count
+}
+
+// MarkExited records that a tunnel process has exited
+func (tm *TunnelManager) MarkExited(id int, err error) *Tunnel {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for _, tunnel := range tm.tunnels {
+		if tunnel.ID == id {
+			tunnel.Running = false
+			tunnel.Err = err
+			return tunnel
+		}
+	}
+	return nil
+}
+
+// Stop terminates a running tunnel
+func (tm *TunnelManager) Stop(tunnel *Tunnel) {
+	if !tunnel.Running || tunnel.cmd.Process == nil {
+		return
+	}
+	if len(tunnel.stopArgs) > 0 {
+		err := exec.Command(tunnel.stopArgs[0], tunnel.stopArgs[1:]...).Run()
+		auditCommand("tunnel", tunnel.Target, tunnel.stopArgs, time.Now(), err)
+		if err == nil {
+			return
+		}
+	}
+	if tunnel.interrupt && runtime.GOOS != "windows" && tunnel.cmd.Process.Signal(os.Interrupt) == nil {
+		return
+	}
+	tunnel.cmd.Process.Kill()
+}
+
+// StopAll terminates every running tunnel
+func (tm *TunnelManager) StopAll() {
+	for _, tunnel := range tm.Tunnels() {
+		tm.Stop(tunnel)
+	}
+}
+
+// Remove forgets an exited tunnel
+func (tm *TunnelManager) Remove(id int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for i, tunnel := range tm.tunnels {
+		if tunnel.ID == id && !tunnel.Running {
+			tm.tunnels = append(tm.tunnels[:i], tm.tunnels[i+1:]...)
+			return
+		}
+	}
+}
+
+// freeLocalPort returns the preferred port if it is free, otherwise any free port
+func freeLocalPort(preferred int) (int, error) {
+	for _, port := range []int{preferred, 0} {
+		if port, err := listenLocalPort(port); err == nil {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free local port available")
+}
+
+// listenLocalPort checks that a local port is free and returns it; port 0
+// picks any free one
+func listenLocalPort(port int) (int, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return 0, fmt.Errorf("local port %d is not available: %w", port, err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// =============================================================================
+// TUNNELS PANEL
+// =============================================================================
+
+// startTunnel starts a tunnel and reports it in the status line
+func (m model) startTunnel(kind, target, localAddr string, args []string) (tea.Model, tea.Cmd) {
+	tunnel, wait, err := m.tunnelManager.Start(kind, target, localAddr, args)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+
+	m.statusMsg = fmt.Sprintf("%s to %s listening on %s (T for tunnels)", tunnel.Kind, tunnel.Target, tunnel.LocalAddr)
+	return m, wait
+}
+
+// handleTunnelExited updates tunnel state when its process ends
+func (m model) handleTunnelExited(msg TunnelExitedMsg) (tea.Model, tea.Cmd) {
+	tunnel := m.tunnelManager.MarkExited(msg.ID, msg.Err)
+	if tunnel != nil && msg.Err != nil && !m.quitting {
+		m.statusMsg = fmt.Sprintf("%s to %s exited: %v", tunnel.Kind, tunnel.Target, msg.Err)
+	}
+	return m, nil
+}
+
+// handleTunnelsPanelKeys handles input while the tunnels panel is open
+func (m model) handleTunnelsPanelKeys(keypress string) (tea.Model, tea.Cmd) {
+	tunnels := m.tunnelManager.Tunnels()
+
+	switch keypress {
+	case "up", "k":
+		if m.tunnelCursor > 0 {
+			m.tunnelCursor--
+		}
+	case "down", "j":
+		if m.tunnelCursor < len(tunnels)-1 {
+			m.tunnelCursor++
+		}
+	case "x":
+		if m.tunnelCursor < len(tunnels) {
+			tunnel := tunnels[m.tunnelCursor]
+			if tunnel.Running {
+				m.tunnelManager.Stop(tunnel)
+				m.statusMsg = fmt.Sprintf("Stopping %s to %s", tunnel.Kind, tunnel.Target)
+			} else {
+				m.tunnelManager.Remove(tunnel.ID)
+				if m.tunnelCursor > 0 {
+					m.tunnelCursor--
+				}
+			}
+		}
+	case "T", "esc":
+		m.showTunnels = false
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderTunnels renders the tunnels panel
+func (m model) renderTunnels() string {
+	tunnels := m.tunnelManager.Tunnels()
+
+	lines := []string{m.styles.Prompt.Render("Tunnels")}
+	if len(tunnels) == 0 {
+		lines = append(lines, m.styles.Label.Render("No tunnels started"))
+	}
+	for i, tunnel := range tunnels {
+		state := m.styles.Running.Render("running")
+		if !tunnel.Running {
+			state = m.styles.Terminated.Render("exited")
+			if tunnel.Err != nil {
+				state = m.styles.Stopping.Render("failed: " + tunnel.Err.Error())
+			}
+		}
+
+		cursor := "  "
+		if i == m.tunnelCursor {
+			cursor = "> "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s → %s  %s %s  %s", cursor,
+			tunnel.LocalAddr, tunnel.Target, m.styles.Label.Render(tunnel.Kind),
+			time.Since(tunnel.StartedAt).Round(time.Second), state))
+	}
+	lines = append(lines, m.styles.Label.Render("x to stop/remove, T or Esc to close"))
+
+	return m.styles.Details.Render(strings.Join(lines, "\n"))
+}
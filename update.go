@@ -0,0 +1,300 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// SELF-UPDATE
+// =============================================================================
+
+// Where releases are published
+const releasesURL = "https://api.github.com/repos/artemvang/werkroom/releases/latest"
+
+// Limits for the release lookup and the download
+const (
+	releaseTimeout  = 30 * time.Second
+	downloadTimeout = 5 * time.Minute
+	maxAssetSize    = 200 << 20
+)
+
+// Release is the part of a GitHub release the update needs
+type Release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// asset returns the download URL of the named asset
+func (r Release) asset(name string) (string, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL, true
+		}
+	}
+	return "", false
+}
+
+// runUpdateCommand runs `werkroom update` and returns the exit code
+func runUpdateCommand(args []string) int {
+	flags := flag.NewFlagSet("werkroom update", flag.ContinueOnError)
+	checkFlag := flags.Bool("check", false, "Only report whether a newer release is available")
+	forceFlag := flags.Bool("force", false, "Install the latest release even if this build is newer or a development build")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if err := selfUpdate(*checkFlag, *forceFlag, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "werkroom: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// selfUpdate replaces the running binary with the latest release after
+// verifying its checksum
+func selfUpdate(checkOnly, force bool, out io.Writer) error {
+	current, _, _ := buildVersion()
+	release, err := latestRelease()
+	if err != nil {
+		return err
+	}
+	latest := strings.TrimPrefix(release.TagName, "v")
+
+	if _, ok := parseVersion(current); !ok {
+		if !force {
+			return fmt.Errorf("this is a development build (%s); the latest release is %s, pass -force to replace this build with it", current, latest)
+		}
+	} else if newer, err := newerVersion(latest, current); err != nil {
+		return err
+	} else if !newer && !force {
+		fmt.Fprintf(out, "werkroom %s is up to date\n", current)
+		return nil
+	}
+	if checkOnly {
+		fmt.Fprintf(out, "werkroom %s is available (you have %s): %s\n", latest, current, release.HTMLURL)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the werkroom binary: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to locate the werkroom binary: %w", err)
+	}
+
+	names := releaseAssetNames(latest)
+	archiveURL, ok := release.asset(names.Archive)
+	if !ok {
+		return fmt.Errorf("release %s has no build for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksumsURL, ok := release.asset(names.Checksums)
+	if !ok {
+		return fmt.Errorf("release %s has no checksums, not installing it", release.TagName)
+	}
+
+	fmt.Fprintf(out, "Downloading werkroom %s...\n", latest)
+	checksums, err := download(checksumsURL)
+	if err != nil {
+		return fmt.Errorf("failed to download checksums: %w", err)
+	}
+	want, err := findChecksum(checksums, names.Archive)
+	if err != nil {
+		return err
+	}
+	archive, err := download(archiveURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", names.Archive, err)
+	}
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s; not installing it", names.Archive, want, got)
+	}
+	binary, err := extractBinary(names.Archive, archive, names.Binary)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceExecutable(exe, binary); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Updated %s from %s to %s\n", exe, current, latest)
+	return nil
+}
+
+// releaseAssets are the file names a release is published under
+type releaseAssets struct {
+	Archive   string
+	Checksums string
+	// The executable inside the archive
+	Binary string
+}
+
+// releaseAssetNames returns the names .goreleaser.yml gives a version's files
+// for this OS and architecture; keep the two in sync
+func releaseAssetNames(version string) releaseAssets {
+	binary := "werkroom_v" + version
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	return releaseAssets{
+		Archive:   fmt.Sprintf("werkroom_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH),
+		Checksums: fmt.Sprintf("werkroom_%s_SHA256SUMS", version),
+		Binary:    binary,
+	}
+}
+
+// latestRelease looks up the latest published release
+func latestRelease() (Release, error) {
+	var release Release
+	data, err := httpGet(releasesURL, releaseTimeout, "application/vnd.github+json")
+	if err != nil {
+		return release, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		return release, fmt.Errorf("failed to parse release data: %w", err)
+	}
+	if release.TagName == "" {
+		return release, errors.New("failed to check for updates: no release found")
+	}
+	return release, nil
+}
+
+// download fetches a release asset
+func download(url string) ([]byte, error) {
+	return httpGet(url, downloadTimeout, "application/octet-stream")
+}
+
+// httpGet fetches a URL, authenticating with GITHUB_TOKEN when set to avoid rate limits
+func httpGet(url string, timeout time.Duration, accept string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	ver, _, _ := buildVersion()
+	req.Header.Set("User-Agent", "werkroom/"+ver)
+	req.Header.Set("Accept", accept)
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && strings.HasPrefix(url, "https://api.github.com/") {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("GET %s: larger than %d MB", url, maxAssetSize>>20)
+	}
+	return data, nil
+}
+
+// findChecksum returns the SHA-256 listed for name in a sha256sum-style file
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum listed for %s, not installing it", name)
+}
+
+// extractBinary returns the file named binaryName from a zip archive
+func extractBinary(name string, archive []byte, binaryName string) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	for _, file := range reader.File {
+		if path.Base(file.Name) != binaryName {
+			continue
+		}
+		f, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", file.Name, err)
+		}
+		defer f.Close()
+		return io.ReadAll(io.LimitReader(f, maxAssetSize))
+	}
+	return nil, fmt.Errorf("%s does not contain %s", name, binaryName)
+}
+
+// replaceExecutable swaps the binary at exe for the new one, checking first
+// that the new one runs
+func replaceExecutable(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", exe, err)
+	}
+	// Next to the binary, so the final rename doesn't cross filesystems
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".werkroom-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to %s (is it writable?): %w", filepath.Dir(exe), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to make the new binary executable: %w", err)
+	}
+	if output, err := exec.Command(tmp.Name(), "-version").CombinedOutput(); err != nil {
+		if output := strings.TrimSpace(string(output)); output != "" {
+			err = fmt.Errorf("%w: %s", err, output)
+		}
+		return fmt.Errorf("the downloaded binary does not run, not installing it: %w", err)
+	}
+
+	// Windows can't overwrite a running executable, but can rename it
+	old := ""
+	if runtime.GOOS == "windows" {
+		old = exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", exe, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		// Put the old binary back rather than leave nothing at exe
+		if old != "" {
+			if restoreErr := os.Rename(old, exe); restoreErr != nil {
+				return fmt.Errorf("failed to replace %s: %w; the previous binary is at %s (%v)", exe, err, old, restoreErr)
+			}
+		}
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// VERSION
+// =============================================================================
+
+// Module pseudo-versions, e.g. v0.0.0-20240102030405-abcdef123456
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}`)
+
+// Set at build time by GoReleaser, or with
+// -ldflags "-X main.version=1.2.3 -X main.commit=abc1234 -X main.date=2024-01-02T03:04:05Z"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+// buildVersion returns the version, commit and build date, filling in what
+// the linker didn't set from the module and VCS info Go embeds
+func buildVersion() (ver, rev, built string) {
+	ver, rev, built = version, commit, date
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ver, rev, built
+	}
+	// go install module@version records the version; local builds get a pseudo-version instead
+	if ver == "dev" && info.Main.Version != "(devel)" && !pseudoVersion.MatchString(info.Main.Version) {
+		if _, ok := parseVersion(info.Main.Version); ok {
+			ver = strings.TrimPrefix(info.Main.Version, "v")
+		}
+	}
+	if rev != "" {
+		return ver, rev, built
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			rev = setting.Value + rev
+		case "vcs.time":
+			if built == "" {
+				built = setting.Value
+			}
+		case "vcs.modified":
+			if setting.Value == "true" {
+				rev += "-dirty"
+			}
+		}
+	}
+	return ver, rev, built
+}
+
+// versionString describes this build for -version
+func versionString() string {
+	ver, rev, built := buildVersion()
+	s := "werkroom " + ver
+	var details []string
+	if rev != "" {
+		details = append(details, "commit "+shortRevision(rev))
+	}
+	if built != "" {
+		details = append(details, "built "+built)
+	}
+	details = append(details, runtime.Version(), runtime.GOOS+"/"+runtime.GOARCH)
+	return s + " (" + strings.Join(details, ", ") + ")"
+}
+
+// shortRevision abbreviates a commit hash like git does
+func shortRevision(rev string) string {
+	hash, dirty, _ := strings.Cut(rev, "-")
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	if dirty != "" {
+		return hash + "-" + dirty
+	}
+	return hash
+}
+
+// parseVersion splits a version like 1.2.3 or v1.2.3-rc1 into its numbers,
+// ignoring any pre-release suffix
+func parseVersion(s string) ([3]int, bool) {
+	var parts [3]int
+	core, _, _ := strings.Cut(strings.TrimPrefix(s, "v"), "-")
+	fields := strings.Split(core, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// newerVersion reports whether version a is newer than b; both must parse
+func newerVersion(a, b string) (bool, error) {
+	pa, ok := parseVersion(a)
+	if !ok {
+		return false, fmt.Errorf("invalid version %q", a)
+	}
+	pb, ok := parseVersion(b)
+	if !ok {
+		return false, fmt.Errorf("invalid version %q", b)
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i], nil
+		}
+	}
+	return false, nil
+}
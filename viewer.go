@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// TEXT VIEWER
+// =============================================================================
+
+// Lines reserved around the viewer for its title and help
+const viewerChrome = 5
+
+// textViewer is a full-screen scrollable text pane
+type textViewer struct {
+	Title    string
+	Viewport viewport.Model
+}
+
+// openViewer shows content in a scrollable viewer
+func (m model) openViewer(title, content string) (tea.Model, tea.Cmd) {
+	height := m.height - viewerChrome
+	if height < MinHeight {
+		height = MinHeight
+	}
+	width := m.width
+	if width <= 0 {
+		width = DefaultWidth
+	}
+
+	vp := viewport.New(width, height)
+	vp.SetContent(content)
+	m.viewer = &textViewer{Title: title, Viewport: vp}
+	return m, nil
+}
+
+// handleViewerKey scrolls or closes the viewer
+func (m model) handleViewerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.viewer = nil
+		return m, nil
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	pending := *m.viewer
+	var cmd tea.Cmd
+	pending.Viewport, cmd = pending.Viewport.Update(msg)
+	m.viewer = &pending
+	return m, cmd
+}
+
+// renderViewer renders the viewer with its title and scroll position
+func (m model) renderViewer() string {
+	return "\n  " + m.styles.Prompt.Render(m.viewer.Title) + "\n\n" +
+		m.viewer.Viewport.View() + "\n" +
+		m.styles.Label.Render("  ↑/↓ PgUp/PgDn to scroll, Esc to close")
+}
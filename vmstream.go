@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// STREAMING VM LOADING
+// =============================================================================
+
+// Instances requested per page
+const vmPageSize = 500
+
+// Bound on concurrent per-zone listings
+const zoneWorkers = 16
+
+// Partial response selector matching the VM struct, to keep pages small
+const vmFields = "id,name,zone,status,machineType,creationTimestamp,lastStartTimestamp," +
+	"deletionProtection,labels,tags/items,metadata/items,disks(deviceName,source,boot,diskSizeGb,type,mode,licenses),scheduling(provisioningModel,preemptible)," +
+	"guestAccelerators(acceleratorType,acceleratorCount),confidentialInstanceConfig," +
+	"networkInterfaces(network,networkIP,accessConfigs/natIP),serviceAccounts(email,scopes)"
+
+// Comparison at the start of a gcloud-style filter term, e.g. labels.env=prod or status = RUNNING
+var filterTerm = regexp.MustCompile(`^([A-Za-z][\w.\-/]*)\s*(!=|<=|>=|=|<|>|:)\s*("(?:[^"\\]|\\.)*"|[^\s()]+)`)
+
+// computeFilter rewrites a gcloud-style filter like "labels.env=prod AND
+// status=RUNNING" into the parenthesised terms the Compute Engine API accepts.
+// Filters starting with "(" are taken to be in the API's syntax already.
+func computeFilter(filter string) (string, error) {
+	rest := strings.TrimSpace(filter)
+	if rest == "" || strings.HasPrefix(rest, "(") {
+		return rest, nil
+	}
+	var b strings.Builder
+	afterTerm := false
+	for rest != "" {
+		word, after, _ := strings.Cut(rest, " ")
+		if word == "AND" || word == "OR" {
+			if !afterTerm {
+				return "", fmt.Errorf("filter %q: %s must join two comparisons", filter, word)
+			}
+			b.WriteString(" " + word + " ")
+			afterTerm = false
+			rest = strings.TrimSpace(after)
+			continue
+		}
+		match := filterTerm.FindStringSubmatch(rest)
+		if match == nil {
+			return "", fmt.Errorf("filter %q: can't read %q as a comparison; the Compute Engine API supports field =, !=, <, >, <=, >= or : value, joined by AND or OR", filter, rest)
+		}
+		if afterTerm {
+			// Like gcloud, adjacent comparisons must all hold
+			b.WriteString(" AND ")
+		}
+		fmt.Fprintf(&b, "(%s %s %s)", match[1], match[2], match[3])
+		afterTerm = true
+		rest = strings.TrimSpace(rest[len(match[0]):])
+	}
+	if !afterTerm {
+		return "", fmt.Errorf("filter %q ends without a comparison", filter)
+	}
+	return b.String(), nil
+}
+
+// VMsPageMsg carries the VMs loaded so far while more pages or zones are pending
+type VMsPageMsg struct {
+	Project string
+	VMs     []VM
+	// Set by aggregated listings
+	Pages int
+	// Set by per-zone listings
+	ZonesDone int
+	Zones     int
+	Next      tea.Cmd
+}
+
+// aggregatedInstances is one page of instances.aggregatedList
+type aggregatedInstances struct {
+	Items map[string]struct {
+		Instances []VM `json:"instances"`
+	} `json:"items"`
+	NextPageToken string   `json:"nextPageToken"`
+	Unreachables  []string `json:"unreachables"`
+}
+
+// zoneResult is the outcome of listing one zone
+type zoneResult struct {
+	Zone string
+	VMs  []VM
+	Err  error
+}
+
+// LoadVMs lists the project's VMs. With gcloud, they are streamed page by
+// page from aggregatedList. The native API and -zones list zones, then
+// instances in every zone concurrently. Either way the last part arrives as
+// VMsLoadedMsg.
+func (gcp *GCPService) LoadVMs(project string) tea.Cmd {
+	if !gcp.native && len(gcp.zoneScope) == 0 {
+		return gcp.loadVMPage(project, "", nil, 0)
+	}
+	return gcp.loadZoneVMs(project)
+}
+
+// loadVMPage fetches one aggregatedList page and appends it to the VMs loaded so far
+func (gcp *GCPService) loadVMPage(project, pageToken string, sofar []VM, pages int) tea.Cmd {
+	return func() tea.Msg {
+		query := url.Values{}
+		query.Set("maxResults", fmt.Sprint(vmPageSize))
+		query.Set("returnPartialSuccess", "true")
+		query.Set("fields", "nextPageToken,unreachables,items/*/instances("+vmFields+")")
+		if gcp.vmFilter != "" {
+			query.Set("filter", gcp.vmFilter)
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		data, err := gcp.callAPI("GET", fmt.Sprintf("https://compute.googleapis.com/compute/v1/projects/%s/aggregated/instances?%s",
+			url.PathEscape(project), query.Encode()), nil)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list VMs: %w", err)}
+		}
+
+		var page aggregatedInstances
+		if err := json.Unmarshal(data, &page); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to parse VM data: %w", err)}
+		}
+
+		// Copy so earlier messages keep their own slice
+		vms := make([]VM, len(sofar), len(sofar)+vmPageSize)
+		copy(vms, sofar)
+		for _, scoped := range page.Items {
+			vms = append(vms, scoped.Instances...)
+		}
+		sortByZone(vms)
+		pages++
+		debugf("VM page %d in %s: %d VMs so far", pages, project, len(vms))
+
+		if page.NextPageToken != "" {
+			return VMsPageMsg{
+				Project: project,
+				VMs:     vms,
+				Pages:   pages,
+				Next:    withRetry("VMs", gcp.loadVMPage(project, page.NextPageToken, vms, pages)),
+			}
+		}
+		loaded := VMsLoadedMsg{Project: project, VMs: vms}
+		if len(page.Unreachables) > 0 {
+			loaded.Warning = fmt.Sprintf("Could not list %d zone(s): %s", len(page.Unreachables), strings.Join(page.Unreachables, ", "))
+		}
+		return loaded
+	}
+}
+
+// sortByZone orders VMs by zone then name, the order gcloud lists them in
+func sortByZone(vms []VM) {
+	sort.SliceStable(vms, func(i, j int) bool {
+		if vms[i].ZoneName() != vms[j].ZoneName() {
+			return vms[i].ZoneName() < vms[j].ZoneName()
+		}
+		return vms[i].Name < vms[j].Name
+	})
+}
+
+// loadZoneVMs lists zones, then lists instances in every zone concurrently.
+// Zones are reported as they finish.
+func (gcp *GCPService) loadZoneVMs(project string) tea.Cmd {
+	return func() tea.Msg {
+		zones, err := gcp.listZones(project)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list zones: %w", err)}
+		}
+		if len(zones) == 0 {
+			if len(gcp.zoneScope) > 0 {
+				return ErrorMsg{Err: fmt.Errorf("no zones of %s match %s", project, strings.Join(gcp.zoneScope, ","))}
+			}
+			return VMsLoadedMsg{Project: project}
+		}
+
+		// Buffered so workers never block on a reader that went away
+		results := make(chan zoneResult, len(zones))
+		go forEachLocation(zones, zoneWorkers, func(zone string) {
+			vms, err := gcp.listZoneVMs(project, zone)
+			results <- zoneResult{Zone: zone, VMs: vms, Err: err}
+		})
+
+		return gcp.awaitZone(project, results, nil, nil, 0, len(zones))()
+	}
+}
+
+// awaitZone waits for the next zone and merges it into the VMs loaded so far
+func (gcp *GCPService) awaitZone(project string, results <-chan zoneResult, sofar []VM, failed []string, done, total int) tea.Cmd {
+	return func() tea.Msg {
+		result := <-results
+		done++
+
+		// Copy so earlier messages keep their own slice
+		vms := make([]VM, len(sofar), len(sofar)+len(result.VMs))
+		copy(vms, sofar)
+		if result.Err != nil {
+			debugf("listing %s failed: %v", result.Zone, result.Err)
+			failed = append(failed[:len(failed):len(failed)], result.Zone)
+		} else {
+			vms = append(vms, result.VMs...)
+		}
+		sortByZone(vms)
+
+		if done < total {
+			return VMsPageMsg{
+				Project:   project,
+				VMs:       vms,
+				ZonesDone: done,
+				Zones:     total,
+				Next:      gcp.awaitZone(project, results, vms, failed, done, total),
+			}
+		}
+
+		debugf("listed %d VMs across %d zones in %s", len(vms), total, project)
+		if len(failed) == total {
+			return ErrorMsg{Err: fmt.Errorf("failed to list VMs: %w", result.Err), Retry: withRetry("VMs", gcp.LoadVMs(project))}
+		}
+		loaded := VMsLoadedMsg{Project: project, VMs: vms}
+		if len(failed) > 0 {
+			loaded.Warning = fmt.Sprintf("Could not list %d zone(s): %s", len(failed), strings.Join(failed, ", "))
+		}
+		return loaded
+	}
+}
+
+// listZones returns the zones of the project that are up
+func (gcp *GCPService) listZones(project string) ([]string, error) {
+	var zones []string
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("fields", "nextPageToken,items(name,status)")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		data, err := gcp.callAPI("GET", fmt.Sprintf("https://compute.googleapis.com/compute/v1/projects/%s/zones?%s",
+			url.PathEscape(project), query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Items []struct {
+				Name   string `json:"name"`
+				Status string `json:"status"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, zone := range page.Items {
+			if zone.Status == "UP" && gcp.inZoneScope(zone.Name) {
+				zones = append(zones, zone.Name)
+			}
+		}
+		if page.NextPageToken == "" {
+			return zones, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// inZoneScope reports whether a zone was selected by -zones, directly or by its region
+func (gcp *GCPService) inZoneScope(zone string) bool {
+	if len(gcp.zoneScope) == 0 {
+		return true
+	}
+	region := zone
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+	for _, scope := range gcp.zoneScope {
+		if scope == zone || scope == region {
+			return true
+		}
+	}
+	return false
+}
+
+// listZoneVMs lists every page of instances in one zone
+func (gcp *GCPService) listZoneVMs(project, zone string) ([]VM, error) {
+	var vms []VM
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("maxResults", fmt.Sprint(vmPageSize))
+		query.Set("fields", "nextPageToken,items("+vmFields+")")
+		if gcp.vmFilter != "" {
+			query.Set("filter", gcp.vmFilter)
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		data, err := gcp.callAPI("GET", fmt.Sprintf("https://compute.googleapis.com/compute/v1/projects/%s/zones/%s/instances?%s",
+			url.PathEscape(project), url.PathEscape(zone), query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Items         []VM   `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse VM data: %w", err)
+		}
+		vms = append(vms, page.Items...)
+		if page.NextPageToken == "" {
+			return vms, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// handleVMsPage shows the VMs loaded so far and waits for the next zone
+func (m model) handleVMsPage(msg VMsPageMsg) (tea.Model, tea.Cmd) {
+	if m.resourceKind != KindInstances || msg.Project != m.selectedProject || !m.expectsListing() {
+		return m, nil
+	}
+
+	if m.state != StateSelectingVM {
+		m.state = StateSelectingVM
+		m.resetFilter()
+	}
+	m.loadingProgress = fmt.Sprintf("loading... %d instances so far (page %d)", len(msg.VMs), msg.Pages)
+	if msg.Zones > 0 {
+		m.loadingProgress = fmt.Sprintf("loading... %d instances, %d/%d zones", len(msg.VMs), msg.ZonesDone, msg.Zones)
+	}
+	m.treeManager.BuildFromVMs(msg.VMs)
+	m.updateVMList()
+	return m, msg.Next
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// RUNNING WATCH
+// =============================================================================
+
+const (
+	// How often instances are reloaded while waiting for RUNNING
+	runningWatchInterval = 5 * time.Second
+	// Give up on an instance that hasn't come up by then
+	runningWatchTimeout = 15 * time.Minute
+)
+
+// isWatched reports whether the node is an instance being watched until RUNNING
+func (m model) isWatched(node *TreeNode) bool {
+	if node.Type != InstanceNode {
+		return false
+	}
+	_, ok := m.runningWatch[markKey(*node.VM)]
+	return ok
+}
+
+// canWatch reports whether the node is an instance that isn't running yet
+func canWatch(m model, node *TreeNode) bool {
+	return isInstance(m, node) && VMStatus(node.VM.Status) != StatusRunning
+}
+
+// watchUntilRunning adds the instance to the watch; notifying happens after the next reload
+func (m model) watchUntilRunning(vm VM) model {
+	// Copy on write: the map is shared with earlier model values
+	watch := make(map[string]time.Time, len(m.runningWatch)+1)
+	for key, deadline := range m.runningWatch {
+		watch[key] = deadline
+	}
+	watch[markKey(vm)] = time.Now().Add(runningWatchTimeout)
+	m.runningWatch = watch
+	return m
+}
+
+// toggleRunningWatch starts or stops watching the selected instance
+func (m model) toggleRunningWatch(node *TreeNode) (tea.Model, tea.Cmd) {
+	vm := *node.VM
+	if m.isWatched(node) {
+		watch := make(map[string]time.Time, len(m.runningWatch))
+		for key, deadline := range m.runningWatch {
+			if key != markKey(vm) {
+				watch[key] = deadline
+			}
+		}
+		m.runningWatch = watch
+		m.statusMsg = fmt.Sprintf("Stopped watching %s", vm.Name)
+		return m, nil
+	}
+
+	m = m.watchUntilRunning(vm)
+	m.statusMsg = fmt.Sprintf("Will notify when %s is RUNNING", vm.Name)
+	return m.scheduleWatchReload()
+}
+
+// RunningWatchTickMsg asks for the next reload while instances are watched
+type RunningWatchTickMsg struct{}
+
+// scheduleWatchReload reloads the instances after the watch interval, unless
+// a reload is already scheduled
+func (m model) scheduleWatchReload() (model, tea.Cmd) {
+	if m.runningWatchPending {
+		return m, nil
+	}
+	m.runningWatchPending = true
+	return m, tea.Tick(runningWatchInterval, func(time.Time) tea.Msg {
+		return RunningWatchTickMsg{}
+	})
+}
+
+// handleRunningWatchTick reloads the instances for the watch
+func (m model) handleRunningWatchTick() (tea.Model, tea.Cmd) {
+	m.runningWatchPending = false
+	if len(m.runningWatch) == 0 || m.resourceKind != KindInstances || m.state != StateSelectingVM {
+		return m, nil
+	}
+	return m, withRetry("VMs", m.gcpService.LoadVMs(m.selectedProject))
+}
+
+// advanceRunningWatch notifies about instances that came up and keeps reloading for the rest
+func (m model) advanceRunningWatch() (model, tea.Cmd) {
+	if len(m.runningWatch) == 0 {
+		return m, nil
+	}
+
+	var cmds []tea.Cmd
+	watch := make(map[string]time.Time, len(m.runningWatch))
+	for _, vm := range m.treeManager.vms {
+		key := markKey(vm)
+		deadline, ok := m.runningWatch[key]
+		switch {
+		case !ok:
+		case VMStatus(vm.Status) == StatusRunning:
+			m.statusMsg = fmt.Sprintf("%s is RUNNING", vm.Name)
+			cmds = append(cmds, m.config.Notifications.notify("werkroom", fmt.Sprintf("%s is RUNNING in %s", vm.Name, m.selectedProject)))
+			if key == m.connectWhenRunning {
+				m.connectWhenRunning = ""
+				var probe tea.Cmd
+				m, probe = m.waitForSSH(vm, sshStartTimeout)
+				cmds = append(cmds, probe)
+			}
+		case time.Now().After(deadline):
+			m.statusMsg = fmt.Sprintf("Stopped watching %s, still %s", vm.Name, vm.Status)
+			if key == m.connectWhenRunning {
+				m.connectWhenRunning = ""
+			}
+		default:
+			watch[key] = deadline
+		}
+	}
+	// Instances that disappeared from the listing are dropped too
+	m.runningWatch = watch
+
+	if len(watch) > 0 {
+		var reload tea.Cmd
+		m, reload = m.scheduleWatchReload()
+		cmds = append(cmds, reload)
+	}
+	return m, tea.Batch(cmds...)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// =============================================================================
+// VSPHERE
+// =============================================================================
+
+// VSphereConfig locates the vCenter; unset values fall back to govc's GOVC_* variables
+type VSphereConfig struct {
+	// e.g. https://vcenter.example.com/sdk
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	// Skip TLS verification for self-signed vCenter certificates
+	Insecure bool `yaml:"insecure"`
+	// User for SSH into guests, default is the local user
+	SSHUser string `yaml:"ssh_user"`
+}
+
+// endpoint builds the vCenter URL with credentials; the password only comes from GOVC_PASSWORD
+func (c VSphereConfig) endpoint() (*url.URL, bool, error) {
+	rawURL := c.URL
+	if rawURL == "" {
+		rawURL = os.Getenv("GOVC_URL")
+	}
+	if rawURL == "" {
+		return nil, false, errors.New("no vCenter configured; set vsphere.url in the config or GOVC_URL")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid vCenter URL: %w", err)
+	}
+	if u.Path == "" {
+		u.Path = "/sdk"
+	}
+
+	username := c.Username
+	if username == "" {
+		username = os.Getenv("GOVC_USERNAME")
+	}
+	if username != "" {
+		u.User = url.UserPassword(username, os.Getenv("GOVC_PASSWORD"))
+	}
+
+	insecure := c.Insecure
+	if value, err := strconv.ParseBool(os.Getenv("GOVC_INSECURE")); err == nil && value {
+		insecure = true
+	}
+	return u, insecure, nil
+}
+
+// vSphere power states mapped onto the VM statuses the tree knows how to style
+var vspherePowerStates = map[types.VirtualMachinePowerState]VMStatus{
+	types.VirtualMachinePowerStatePoweredOn:  StatusRunning,
+	types.VirtualMachinePowerStatePoweredOff: StatusTerminated,
+	types.VirtualMachinePowerStateSuspended:  StatusSuspended,
+}
+
+// LoadVSphereVMs lists VMs from vCenter, grouped by cluster and resource pool
+func LoadVSphereVMs(config Config) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), sourceTimeout(config))
+		defer cancel()
+
+		u, insecure, err := config.VSphere.endpoint()
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		debugf("connecting to vCenter %s", u.Host)
+		client, err := govmomi.NewClient(ctx, u, insecure)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to connect to %s: %w", u.Host, err)}
+		}
+		defer client.Logout(context.Background())
+
+		manager := view.NewManager(client.Client)
+		containers, err := manager.CreateContainerView(ctx, client.ServiceContent.RootFolder,
+			[]string{"ComputeResource", "ResourcePool", "VirtualMachine"}, true)
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to create inventory view: %w", err)}
+		}
+		defer containers.Destroy(context.Background())
+
+		var pools []types.ObjectContent
+		if err := containers.Retrieve(ctx, []string{"ComputeResource", "ResourcePool"}, []string{"name", "parent"}, &pools); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list clusters and resource pools: %w", err)}
+		}
+		var vms []mo.VirtualMachine
+		if err := containers.Retrieve(ctx, []string{"VirtualMachine"},
+			[]string{"name", "runtime.powerState", "guest.ipAddress", "guest.guestFullName", "resourcePool", "config.template"}, &vms); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list VMs: %w", err)}
+		}
+
+		groups := newVSphereGroups(pools)
+		var resources []Resource
+		for _, vm := range vms {
+			if vm.Config != nil && vm.Config.Template {
+				continue
+			}
+			resource := Resource{
+				Kind:   KindVSphere,
+				Name:   vm.Name,
+				Status: string(vspherePowerStates[vm.Runtime.PowerState]),
+			}
+			if vm.ResourcePool != nil {
+				resource.Group = groups.path(*vm.ResourcePool)
+				resource.Location = groups.cluster(*vm.ResourcePool)
+			}
+			if vm.Guest != nil {
+				resource.Fields = []ResourceField{
+					{Label: "IP", Value: vm.Guest.IpAddress},
+					{Label: "Guest OS", Value: vm.Guest.GuestFullName},
+				}
+			}
+			resources = append(resources, resource)
+		}
+		debugf("listed %d vSphere VMs", len(resources))
+		return ResourcesLoadedMsg{Kind: KindVSphere, Resources: resources}
+	}
+}
+
+// vsphereGroups resolves resource pools to their cluster and pool path
+type vsphereGroups struct {
+	names   map[types.ManagedObjectReference]string
+	parents map[types.ManagedObjectReference]types.ManagedObjectReference
+}
+
+// newVSphereGroups indexes the name and parent of every cluster and pool
+func newVSphereGroups(objects []types.ObjectContent) vsphereGroups {
+	groups := vsphereGroups{
+		names:   make(map[types.ManagedObjectReference]string),
+		parents: make(map[types.ManagedObjectReference]types.ManagedObjectReference),
+	}
+	for _, object := range objects {
+		for _, prop := range object.PropSet {
+			switch value := prop.Val.(type) {
+			case string:
+				groups.names[object.Obj] = value
+			case types.ManagedObjectReference:
+				groups.parents[object.Obj] = value
+			}
+		}
+	}
+	return groups
+}
+
+// path returns "cluster/pool/subpool"; VMs in a cluster's root pool are grouped by cluster alone
+func (g vsphereGroups) path(pool types.ManagedObjectReference) string {
+	isPool := func(ref types.ManagedObjectReference) bool {
+		return ref.Type == "ResourcePool" || ref.Type == "VirtualApp"
+	}
+
+	var parts []string
+	ref := pool
+	for isPool(ref) {
+		parent, ok := g.parents[ref]
+		if !ok {
+			break
+		}
+		// The root pool is an implementation detail; its parent is the cluster
+		if isPool(parent) {
+			parts = append([]string{g.names[ref]}, parts...)
+		}
+		ref = parent
+	}
+	if !isPool(ref) {
+		parts = append([]string{g.names[ref]}, parts...)
+	}
+	return strings.Join(parts, "/")
+}
+
+// cluster returns the name of the cluster or standalone host owning the pool
+func (g vsphereGroups) cluster(pool types.ManagedObjectReference) string {
+	path := g.path(pool)
+	cluster, _, _ := strings.Cut(path, "/")
+	return cluster
+}
+
+// connectToVSphereVM hands the terminal to ssh on the guest IP reported by VMware Tools
+func (m model) connectToVSphereVM(node *TreeNode) (tea.Model, tea.Cmd) {
+	ip := node.Resource.Field("IP")
+	if ip == "" {
+		m.statusMsg = fmt.Sprintf("VMware Tools reports no IP for %s", node.Name)
+		return m, nil
+	}
+	// The guest reports its own IP, so don't trust it to be one
+	if net.ParseIP(ip) == nil {
+		m.statusMsg = fmt.Sprintf("VMware Tools reports an invalid IP %q for %s", ip, node.Name)
+		return m, nil
+	}
+	args, err := sshArgs(m.config.VSphere.SSHUser, ip)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	return m.launchAndQuit(Launch{Title: node.Name, Args: args})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// VERTEX AI WORKBENCH
+// =============================================================================
+
+// WorkbenchInstance represents a Workbench instance as returned by gcloud
+type WorkbenchInstance struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	ProxyURI string `json:"proxyUri"`
+	GceSetup struct {
+		MachineType string `json:"machineType"`
+	} `json:"gceSetup"`
+}
+
+// LoadWorkbenchInstances loads Workbench instances from every zone of the project
+func (gcp *GCPService) LoadWorkbenchInstances(project string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := gcp.runGcloud("compute", "zones", "list",
+			"--project", project,
+			"--format", "value(name)")
+		if err != nil {
+			return ErrorMsg{Err: fmt.Errorf("failed to list zones: %w", err)}
+		}
+
+		resources, err := fanOut(strings.Fields(string(output)), func(zone string) ([]Resource, error) {
+			return gcp.listWorkbenchInstances(project, zone)
+		})
+		if err != nil && len(resources) == 0 {
+			return ErrorMsg{Err: err}
+		}
+		return ResourcesLoadedMsg{Kind: KindWorkbench, Resources: resources}
+	}
+}
+
+// listWorkbenchInstances lists the Workbench instances of a single zone
+func (gcp *GCPService) listWorkbenchInstances(project, zone string) ([]Resource, error) {
+	output, err := gcp.runGcloud("workbench", "instances", "list",
+		"--project", project,
+		"--location", zone,
+		"--format", "json(name,state,proxyUri,gceSetup.machineType)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Workbench instances in %s: %w", zone, err)
+	}
+
+	var instances []WorkbenchInstance
+	if err := json.Unmarshal(output, &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse Workbench data: %w", err)
+	}
+
+	resources := make([]Resource, len(instances))
+	for i, instance := range instances {
+		// ACTIVE is the Workbench equivalent of a running VM
+		status := instance.State
+		if status == "ACTIVE" {
+			status = string(StatusRunning)
+		}
+
+		resources[i] = Resource{
+			Kind:     KindWorkbench,
+			Name:     path.Base(instance.Name),
+			Location: zone,
+			Status:   status,
+			Group:    zone,
+			Fields: []ResourceField{
+				{Label: "Machine type", Value: path.Base(instance.GceSetup.MachineType)},
+				{Label: "JupyterLab", Value: instance.ProxyURI},
+			},
+		}
+	}
+	return resources, nil
+}
+
+// openJupyterLab opens the proxied JupyterLab URL in the browser
+func (m model) openJupyterLab(node *TreeNode) (tea.Model, tea.Cmd) {
+	proxyURI := node.Resource.Field("JupyterLab")
+	if proxyURI == "" {
+		m.statusMsg = fmt.Sprintf("%s has no JupyterLab URL yet", node.Resource.Name)
+		return m, nil
+	}
+
+	url := "https://" + strings.TrimPrefix(proxyURI, "https://")
+	if err := openBrowser(url); err != nil {
+		m.statusMsg = fmt.Sprintf("Failed to open browser: %v", err)
+		return m, nil
+	}
+	m.statusMsg = fmt.Sprintf("Opened %s", url)
+	return m, nil
+}
+
+// connectToWorkbenchVM opens an SSH session to the VM backing a Workbench instance
+func (m model) connectToWorkbenchVM(node *TreeNode) (tea.Model, tea.Cmd) {
+	instance := node.Resource
+	return m.connectToVM(&VM{
+		Name:   instance.Name,
+		Zone:   instance.Location,
+		Status: instance.Status,
+	})
+}
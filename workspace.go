@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// =============================================================================
+// WORKSPACE
+// =============================================================================
+
+// Workspace is where the user left off, saved on exit and restored with -resume
+type Workspace struct {
+	Project string `json:"project,omitempty"`
+	// Resource type by its plural name, e.g. "GKE clusters"
+	Kind string   `json:"kind,omitempty"`
+	Tabs []string `json:"tabs,omitempty"`
+	// Resource type of each tab, like Kind
+	TabKinds  []string `json:"tabKinds,omitempty"`
+	ActiveTab int      `json:"activeTab,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+	// Names of the expanded groups of the tree on screen
+	Expanded       []string  `json:"expanded,omitempty"`
+	HideGKENodes   bool      `json:"hideGkeNodes,omitempty"`
+	HideTerminated bool      `json:"hideTerminated,omitempty"`
+	SortOrder      string    `json:"sortOrder,omitempty"`
+	Density        string    `json:"density,omitempty"`
+	ShowCost       bool      `json:"showCost,omitempty"`
+	SavedAt        time.Time `json:"savedAt"`
+}
+
+// workspacePath returns the file holding the saved workspace
+func workspacePath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspace.json"), nil
+}
+
+// loadWorkspace reads the saved workspace, returning nil if there is none
+func loadWorkspace() *Workspace {
+	path, err := workspacePath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var ws Workspace
+	if err := json.Unmarshal(data, &ws); err != nil {
+		return nil
+	}
+	return &ws
+}
+
+// saveWorkspace writes the workspace for the next -resume
+func saveWorkspace(ws Workspace) error {
+	path, err := workspacePath()
+	if err != nil {
+		return err
+	}
+	ws.SavedAt = time.Now()
+	data, err := json.MarshalIndent(ws, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// workspace captures where the user is; ok is false when there is nothing
+// worth restoring, e.g. outside GCP or in -pick mode
+func (m model) workspace() (Workspace, bool) {
+	if m.onSource() || m.config.Pick {
+		return Workspace{}, false
+	}
+	tm := m.treeManager
+	ws := Workspace{
+		Kind:           resourceType(m.resourceKind).Plural,
+		ActiveTab:      m.activeTab,
+		HideGKENodes:   tm.hideGKENodes,
+		HideTerminated: tm.hideTerminated,
+		SortOrder:      tm.sortOrder.String(),
+		Density:        m.density.String(),
+		ShowCost:       tm.showCost,
+	}
+	for i, tab := range m.tabs {
+		kind := tab.Kind
+		if i == m.activeTab && tab.Project == m.selectedProject {
+			// The active tab's kind is only saved when leaving it
+			kind = m.resourceKind
+		}
+		ws.Tabs = append(ws.Tabs, tab.Project)
+		ws.TabKinds = append(ws.TabKinds, resourceType(kind).Plural)
+	}
+	// Back on the project list, the tabs are all that's left to restore
+	if m.state == StateSelectingVM || m.state == StateReadyToConnect {
+		ws.Project = m.selectedProject
+		ws.Filter = m.filterText
+		for _, node := range tm.GetNodes() {
+			if node.Type == GroupNode && node.IsExpanded {
+				ws.Expanded = append(ws.Expanded, node.Name)
+			}
+		}
+	}
+	return ws, ws.Project != "" || len(ws.Tabs) > 0
+}
+
+// restoreWorkspace applies a saved workspace before anything is loaded. The
+// filter and expanded groups only apply when the same project is reopened.
+func (m *model) restoreWorkspace(ws Workspace) {
+	tm := m.treeManager
+	tm.hideGKENodes = tm.hideGKENodes || ws.HideGKENodes
+	tm.hideTerminated = tm.hideTerminated || ws.HideTerminated
+	tm.showCost = ws.ShowCost
+	for _, order := range sortOrders {
+		if order.String() == ws.SortOrder {
+			tm.sortOrder = order
+		}
+	}
+	for density := DensityNormal; density <= DensityComfortable; density++ {
+		if density.String() == ws.Density {
+			m.density = density
+			tm.density = density
+			m.list.SetDelegate(itemDelegate{styles: m.styles, density: density})
+		}
+	}
+	if kind, ok := projectKind(ws.Kind); ok {
+		m.resourceKind = kind
+	}
+
+	for i, project := range ws.Tabs {
+		if len(m.tabs) >= maxProjectTabs {
+			break
+		}
+		// Workspaces saved before tabs had their own kind share the active one
+		kind := m.resourceKind
+		if i < len(ws.TabKinds) {
+			if tabKind, ok := projectKind(ws.TabKinds[i]); ok {
+				kind = tabKind
+			}
+		}
+		m.tabs = append(m.tabs, projectTab{Project: project, Kind: kind})
+	}
+	if ws.ActiveTab < len(m.tabs) {
+		m.activeTab = ws.ActiveTab
+	}
+
+	if ws.Project == "" || ws.Project != m.selectedProject {
+		return
+	}
+	m.resumeFilter = ws.Filter
+	// Stand-ins for the groups; the first build keeps them expanded
+	for _, name := range ws.Expanded {
+		tm.nodes = append(tm.nodes, &TreeNode{Type: GroupNode, Name: name, IsExpanded: true})
+	}
+}
+
+// projectKind returns the project resource type with the given plural name
+func projectKind(plural string) (ResourceKind, bool) {
+	for _, rt := range resourceTypes() {
+		if rt.Source == "" && rt.Plural == plural {
+			return rt.Kind, true
+		}
+	}
+	return 0, false
+}
+
+// resetFilter clears the filter for a fresh listing, or applies the one restored by -resume
+func (m *model) resetFilter() {
+	m.filtering, m.filterText = m.resumeFilter != "", m.resumeFilter
+	m.resumeFilter = ""
+}